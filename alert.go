@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+)
+
+// longest a single reachability check of alert may take
+const alertDialTimeout = 5 * time.Second
+
+// MonitorHost checks every interval whether the ssh port of h answers and
+// calls notify each time it does again after it didn't. It never returns.
+func MonitorHost(h SSHHost, interval time.Duration, notify func(string)) {
+	monitorHost(context.Background(), h, interval, notify)
+}
+
+// monitorHost is MonitorHost stopping once ctx is done. The first check only
+// sets the state, so a host that is up from the start isn't reported.
+func monitorHost(ctx context.Context, h SSHHost, interval time.Duration, notify func(string)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	first, wasUp := true, false
+	for {
+		_, err := checkReachable(ctx, h, min(interval, alertDialTimeout))
+		if ctx.Err() != nil {
+			return
+		}
+		up := err == nil
+		switch {
+		case first && up:
+			fmt.Printf("%s %s is reachable, waiting for it to go away and come back\n", time.Now().Format(time.TimeOnly), h.Host)
+		case first:
+			fmt.Printf("%s %s is unreachable: %v\n", time.Now().Format(time.TimeOnly), h.Host, err)
+		case up && !wasUp:
+			fmt.Printf("%s %s is reachable again\n", time.Now().Format(time.TimeOnly), h.Host)
+			notify(h.Host + " is reachable again")
+		case !up && wasUp:
+			fmt.Printf("%s %s is unreachable: %v\n", time.Now().Format(time.TimeOnly), h.Host, err)
+		}
+		first, wasUp = false, up
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// desktopNotify shows a desktop notification with notify-send, osascript on
+// macOS or toast on Windows
+func desktopNotify(title, message string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("osascript", "-e", fmt.Sprintf("display notification %q with title %q", message, title))
+	case "windows":
+		cmd = exec.Command("toast", "--title", title, "--message", message)
+	default:
+		cmd = exec.Command("notify-send", title, message)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if out := strings.TrimSpace(string(out)); out != "" {
+			return fmt.Errorf("%s: %w: %s", cmd.Args[0], err, out)
+		}
+		return fmt.Errorf("%s: %w", cmd.Args[0], err)
+	}
+	return nil
+}
+
+func runAlert(args []string) int {
+	fs := flag.NewFlagSet("alert", flag.ExitOnError)
+	alias := fs.String("host", "", "alias of the host to watch")
+	onReconnect := fs.Bool("on-reconnect", true, "notify when the host is reachable again after it wasn't")
+	interval := fs.Duration("interval", 30*time.Second, "time between checks")
+	command := fs.String("command", "", "run this once the host is back, e.g. \"ssh myserver\", and stop watching")
+	fs.Parse(args)
+
+	if *alias == "" || !*onReconnect || *interval <= 0 {
+		fmt.Fprintln(os.Stderr, "usage: quickssh alert --host <alias> [--on-reconnect] [--interval 30s] [--command cmd]")
+		return 2
+	}
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load config:", err)
+		return 1
+	}
+	i := slices.IndexFunc(config.Hosts, func(h SSHHost) bool { return h.Host == *alias })
+	if i < 0 {
+		fmt.Fprintf(os.Stderr, "no host with alias %q\n", *alias)
+		return 1
+	}
+	h := config.Hosts[i]
+	if h.ProxyJump != "" {
+		fmt.Fprintln(os.Stderr, "alert can't check hosts with proxy_jump, their port isn't reachable directly")
+		return 1
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+	fmt.Printf("Checking %s every %s, ctrl+c to stop\n", h.Host, *interval)
+	// only returns once the command is due
+	monitorHost(ctx, h, *interval, func(message string) {
+		if err := desktopNotify("quickssh", message); err != nil {
+			fmt.Fprintln(os.Stderr, "failed to send the notification:", err)
+		}
+		if *command != "" {
+			stop()
+		}
+	})
+
+	cmd := shellCommand(context.Background(), *command)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		fmt.Fprintln(os.Stderr, "command failed:", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+)
+
+var errAmbiguousAlias = errors.New("several hosts match")
+
+// matchAlias finds the host query refers to: the host with exactly that
+// alias, or else the only one whose alias starts with query, ignoring case.
+// When several aliases start with query they are returned as candidates
+// along with errAmbiguousAlias.
+func matchAlias(query string, hosts []SSHHost) (SSHHost, []SSHHost, error) {
+	if query == "" {
+		return SSHHost{}, nil, fmt.Errorf("no alias given")
+	}
+	var candidates []SSHHost
+	for _, h := range hosts {
+		if h.Host == query {
+			return h, nil, nil
+		}
+		if strings.HasPrefix(strings.ToLower(h.Host), strings.ToLower(query)) {
+			candidates = append(candidates, h)
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		return SSHHost{}, nil, fmt.Errorf("no host matches %q", query)
+	case 1:
+		return candidates[0], nil, nil
+	}
+	return SSHHost{}, candidates, errAmbiguousAlias
+}
+
+// hostsOf returns the hosts behind the list items
+func hostsOf(items []list.Item) []SSHHost {
+	hosts := make([]SSHHost, len(items))
+	for i, item := range items {
+		hosts[i] = item.(hostItem).SSHHost
+	}
+	return hosts
+}
+
+// listedHosts returns all hosts in the list, including the ones from
+// ssh_config that aren't part of m.hosts
+func (m model) listedHosts() []SSHHost {
+	return hostsOf(m.list.Items())
+}
+
+// visibleHosts returns the hosts the list shows, the ones matching the
+// filter if there is one
+func (m model) visibleHosts() []SSHHost {
+	return hostsOf(m.list.VisibleItems())
+}
+
+// runConnectAlias connects to the host matching query in the terminal, for
+// the quickssh <alias> form
+func runConnectAlias(query string) int {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load config:", err)
+		return 1
+	}
+	hosts, err := listedHosts(config)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to read ~/.ssh/config:", err)
+	}
+
+	h, candidates, err := matchAlias(query, hosts)
+	if errors.Is(err, errAmbiguousAlias) {
+		fmt.Fprintf(os.Stderr, "%q matches several hosts:\n", query)
+		for _, c := range candidates {
+			fmt.Fprintln(os.Stderr, "  "+c.Host)
+		}
+		return 1
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	return runSSH(h, config.Settings)
+}
+
+// runSSH connects to h in this terminal and returns ssh's exit code
+func runSSH(h SSHHost, settings Settings) int {
+	if warning := settings.wslWarning(); warning != "" {
+		fmt.Fprintln(os.Stderr, warning)
+	}
+	cmd := newConnectExec(h, sshCommand(settings, sshArgs(h)...), settings)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	err := cmd.Run()
+	if err := appendHistory(newConnectionEvent(h, err)); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to record history:", err)
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to run ssh:", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestMatchAlias(t *testing.T) {
+	hosts := []SSHHost{
+		{Host: "web1"}, {Host: "web12"}, {Host: "web2"}, {Host: "db-primary"}, {Host: "Backup"},
+	}
+	tests := []struct {
+		name       string
+		query      string
+		want       string
+		candidates []string
+		err        error
+	}{
+		{"exact", "db-primary", "db-primary", nil, nil},
+		{"exact beats a longer alias", "web1", "web1", nil, nil},
+		{"unique prefix", "db", "db-primary", nil, nil},
+		{"prefix ignores case", "back", "Backup", nil, nil},
+		{"ambiguous", "web", "", []string{"web1", "web12", "web2"}, errAmbiguousAlias},
+		{"ambiguous ignoring case", "WEB1", "", []string{"web1", "web12"}, errAmbiguousAlias},
+		{"no match", "mail", "", nil, errors.New(`no host matches "mail"`)},
+		{"empty", "", "", nil, errors.New("no alias given")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, candidates, err := matchAlias(tt.query, hosts)
+			switch {
+			case tt.err == nil && err != nil:
+				t.Fatalf("unexpected error %v", err)
+			case tt.err != nil && (err == nil || err.Error() != tt.err.Error()):
+				t.Fatalf("error = %v, want %v", err, tt.err)
+			}
+			if h.Host != tt.want {
+				t.Errorf("host = %q, want %q", h.Host, tt.want)
+			}
+			if got := aliasesOf(candidates); !slices.Equal(got, tt.candidates) {
+				t.Errorf("candidates = %v, want %v", got, tt.candidates)
+			}
+		})
+	}
+}
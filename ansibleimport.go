@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ansibleInventory is what ParseAnsibleInventory understood of an INI
+// inventory
+type ansibleInventory struct {
+	Hosts []SSHHost
+	// host patterns like web[01:20].example.com, which aren't expanded
+	Skipped []string
+}
+
+// ParseAnsibleInventory reads an INI-style Ansible inventory. Each group a
+// host is in, directly or through :children, becomes one of its tags.
+// ansible_host, ansible_user, ansible_port and
+// ansible_ssh_private_key_file map to the host's fields, from the host line
+// or from a group's :vars.
+func ParseAnsibleInventory(r io.Reader) (ansibleInventory, error) {
+	var inv ansibleInventory
+	byName := make(map[string]int)
+	vars := make(map[string]map[string]string)
+	members := make(map[string][]string)
+	children := make(map[string][]string)
+
+	section, kind := "ungrouped", ""
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return inv, fmt.Errorf("line %d: unterminated section %s", n, line)
+			}
+			section, kind, _ = strings.Cut(line[1:len(line)-1], ":")
+			continue
+		}
+
+		fields := strings.Fields(line)
+		switch kind {
+		case "vars":
+			key, value, _ := strings.Cut(line, "=")
+			if vars[section] == nil {
+				vars[section] = make(map[string]string)
+			}
+			vars[section][strings.TrimSpace(key)] = unquoteAnsible(strings.TrimSpace(value))
+		case "children":
+			children[section] = append(children[section], fields[0])
+		case "":
+			name := fields[0]
+			if strings.ContainsAny(name, "[]*?") {
+				if !slices.Contains(inv.Skipped, name) {
+					inv.Skipped = append(inv.Skipped, name)
+				}
+				continue
+			}
+			i, ok := byName[name]
+			if !ok {
+				i = len(inv.Hosts)
+				byName[name] = i
+				inv.Hosts = append(inv.Hosts, SSHHost{Host: name})
+			}
+			members[section] = append(members[section], name)
+			for _, field := range fields[1:] {
+				key, value, _ := strings.Cut(field, "=")
+				if err := setAnsibleVar(&inv.Hosts[i], key, unquoteAnsible(value)); err != nil {
+					return inv, fmt.Errorf("line %d: %w", n, err)
+				}
+			}
+		default:
+			return inv, fmt.Errorf("line %d: unknown section kind %q", n, kind)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return inv, err
+	}
+
+	// a group applies its tag and vars to its own hosts and to those of
+	// its children, host line variables win over group ones
+	var apply func(group, tag string, seen []string)
+	apply = func(group, tag string, seen []string) {
+		if slices.Contains(seen, group) {
+			return
+		}
+		for _, name := range members[group] {
+			h := &inv.Hosts[byName[name]]
+			if tag != "all" && tag != "ungrouped" && !slices.Contains(h.Tags, tag) {
+				h.Tags = append(h.Tags, tag)
+			}
+			for key, value := range vars[tag] {
+				if !ansibleVarSet(*h, key) {
+					setAnsibleVar(h, key, value)
+				}
+			}
+		}
+		for _, child := range children[group] {
+			apply(child, tag, append(seen, group))
+		}
+	}
+	groups := slices.Sorted(func(yield func(string) bool) {
+		for g := range members {
+			if !yield(g) {
+				return
+			}
+		}
+		for g := range children {
+			if _, ok := members[g]; !ok && !yield(g) {
+				return
+			}
+		}
+	})
+	for _, group := range groups {
+		apply(group, group, nil)
+	}
+	// all:vars apply to every host
+	for i := range inv.Hosts {
+		for key, value := range vars["all"] {
+			if !ansibleVarSet(inv.Hosts[i], key) {
+				setAnsibleVar(&inv.Hosts[i], key, value)
+			}
+		}
+	}
+	return inv, nil
+}
+
+// setAnsibleVar maps an inventory variable to a host field, others are
+// ignored
+func setAnsibleVar(h *SSHHost, key, value string) error {
+	switch key {
+	case "ansible_host", "ansible_ssh_host":
+		h.HostName = value
+	case "ansible_user", "ansible_ssh_user":
+		h.User = value
+	case "ansible_port", "ansible_ssh_port":
+		port, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%s %q is not a number", key, value)
+		}
+		h.Port = port
+	case "ansible_ssh_private_key_file":
+		h.IdentityFile = value
+	}
+	return nil
+}
+
+// ansibleVarSet reports whether the field of key is already set on h
+func ansibleVarSet(h SSHHost, key string) bool {
+	switch key {
+	case "ansible_host", "ansible_ssh_host":
+		return h.HostName != ""
+	case "ansible_user", "ansible_ssh_user":
+		return h.User != ""
+	case "ansible_port", "ansible_ssh_port":
+		return h.Port != 0
+	case "ansible_ssh_private_key_file":
+		return h.IdentityFile != ""
+	}
+	return true
+}
+
+func unquoteAnsible(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// runImportAnsible adds the hosts of an inventory file for -import-ansible
+func runImportAnsible(path string) int {
+	f, err := os.Open(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer f.Close()
+	inv, err := ParseAnsibleInventory(f)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to parse %s: %v\n", path, err)
+		return 1
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load config:", err)
+		return 1
+	}
+	var skipped []string
+	config.Hosts, skipped = mergeHosts(config.Hosts, inv.Hosts)
+	if err := saveConfig(config); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to save config:", err)
+		return 1
+	}
+
+	fmt.Printf("Imported %d hosts\n", len(inv.Hosts)-len(skipped))
+	for _, alias := range skipped {
+		fmt.Printf("Skipped %s, the alias already exists\n", alias)
+	}
+	for _, pattern := range inv.Skipped {
+		fmt.Printf("Skipped %s, host patterns and ranges aren't expanded\n", pattern)
+	}
+	return 0
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseAnsibleInventoryFixture(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "inventory.ini"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	inv, err := ParseAnsibleInventory(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []SSHHost{
+		// the host line wins over all:vars
+		{Host: "mail.example.com", User: "postmaster"},
+		// prod:vars win over all:vars, groups are tags in name order
+		{Host: "web1", HostName: "10.0.0.1", User: "deploy", Port: 22, Tags: []string{"prod", "web"}},
+		{Host: "web2", HostName: "10.0.0.2", User: "admin", Port: 2222, Tags: []string{"prod", "web"}},
+		{Host: "db1", HostName: "10.0.1.1", User: "deploy", Port: 22, IdentityFile: "~/.ssh/db_key", Tags: []string{"db", "prod"}},
+	}
+	if !reflect.DeepEqual(inv.Hosts, want) {
+		t.Errorf("hosts\n%+v\nwant\n%+v", inv.Hosts, want)
+	}
+	if want := []string{"web[03:10].example.com"}; !reflect.DeepEqual(inv.Skipped, want) {
+		t.Errorf("skipped %v, want %v", inv.Skipped, want)
+	}
+}
+
+func TestParseAnsibleInventory(t *testing.T) {
+	tests := []struct {
+		name      string
+		inventory string
+		want      []SSHHost
+		err       string
+	}{
+		{"empty", "", nil, ""},
+		{"host in two groups", "[a]\nh1\n[b]\nh1\n", []SSHHost{{Host: "h1", Tags: []string{"a", "b"}}}, ""},
+		{"nested children", "[leaf]\nh1\n[mid:children]\nleaf\n[top:children]\nmid\n", []SSHHost{{Host: "h1", Tags: []string{"leaf", "mid", "top"}}}, ""},
+		{"cyclic children", "[a]\nh1\n[a:children]\nb\n[b:children]\na\n", []SSHHost{{Host: "h1", Tags: []string{"a", "b"}}}, ""},
+		{"all is no tag", "[all]\nh1\n", []SSHHost{{Host: "h1"}}, ""},
+		{"other variables are ignored", "h1 ansible_connection=local foo=bar\n", []SSHHost{{Host: "h1"}}, ""},
+		{"ssh prefixed variables", "h1 ansible_ssh_host=10.0.0.1 ansible_ssh_user=u ansible_ssh_port=23\n", []SSHHost{{Host: "h1", HostName: "10.0.0.1", User: "u", Port: 23}}, ""},
+		{"port not a number", "h1 ansible_port=ssh\n", nil, `line 1: ansible_port "ssh" is not a number`},
+		{"unterminated section", "[web\nh1\n", nil, "line 1: unterminated section [web"},
+		{"unknown section kind", "[web:hosts]\nh1\n", nil, `line 2: unknown section kind "hosts"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv, err := ParseAnsibleInventory(strings.NewReader(tt.inventory))
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(inv.Hosts, tt.want) {
+				t.Errorf("hosts %+v, want %+v", inv.Hosts, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunImportAnsible(t *testing.T) {
+	old := configFilePath
+	t.Cleanup(func() { configFilePath = old })
+	configFilePath = filepath.Join(t.TempDir(), ".config")
+	existing := "[[hosts]]\nhost = \"web1\"\nhostname = \"192.168.0.1\"\n"
+	if err := os.WriteFile(configFilePath, []byte(existing), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if code := runImportAnsible(filepath.Join("testdata", "inventory.ini")); code != 0 {
+		t.Fatalf("exit code %d", code)
+	}
+	config, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hostAliases(config.Hosts); !reflect.DeepEqual(got, []string{"web1", "mail.example.com", "web2", "db1"}) {
+		t.Errorf("hosts %v after the import", got)
+	}
+	// an existing alias is kept as it was
+	if config.Hosts[0].HostName != "192.168.0.1" {
+		t.Errorf("web1 was overwritten with %s", config.Hosts[0].HostName)
+	}
+}
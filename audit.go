@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// hosts without a connection for this long are reported as stale
+const auditStaleAfter = 90 * 24 * time.Hour
+
+type AuditFinding struct {
+	Host    string `json:"host"`
+	Check   string `json:"check"`
+	Message string `json:"message"`
+}
+
+func runAudit(args []string) int {
+	fs := flag.NewFlagSet("audit", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	fs.Parse(args)
+
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load config:", err)
+		return 1
+	}
+	history, err := loadHistory()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load history:", err)
+		return 1
+	}
+
+	findings := AuditHosts(config.Hosts, history, time.Now())
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if findings == nil {
+			findings = []AuditFinding{}
+		}
+		enc.Encode(findings)
+	} else if len(findings) == 0 {
+		fmt.Println(checkPassStyle.Render("✓") + " no issues found")
+	} else {
+		for _, f := range findings {
+			fmt.Printf("%s %s [%s]: %s\n", checkFailStyle.Render("✗"), f.Host, f.Check, f.Message)
+		}
+		fmt.Printf("\n%d issues found\n", len(findings))
+	}
+
+	if len(findings) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// AuditHosts runs all security checks against the hosts
+func AuditHosts(hosts []SSHHost, history []ConnectionEvent, now time.Time) []AuditFinding {
+	last := lastConnected(history)
+
+	var findings []AuditFinding
+	for _, h := range hosts {
+		report := func(check, format string, a ...any) {
+			findings = append(findings, AuditFinding{Host: h.Host, Check: check, Message: fmt.Sprintf(format, a...)})
+		}
+
+		if v, ok := h.option("StrictHostKeyChecking"); ok && strings.EqualFold(v, "no") {
+			report("host-key-checking", "StrictHostKeyChecking=no disables host key verification")
+		}
+
+		if h.ForwardAgent && isPublicHost(h.address()) {
+			report("agent-forwarding", "agent forwarding to %s, which is outside private networks", h.address())
+		}
+
+		if h.IdentityFile != "" && runtime.GOOS != "windows" {
+			path := expandPath(h.IdentityFile)
+			if info, err := os.Stat(path); err == nil && info.Mode().Perm()&0o077 != 0 {
+				report("identity-permissions", "%s has mode %04o, readable by group or others", path, info.Mode().Perm())
+			}
+		}
+
+		if v, ok := h.option("PreferredAuthentications"); (ok && strings.HasPrefix(strings.ToLower(v), "password")) || h.PasswordOnly {
+			report("password-auth", "prefers password authentication")
+		}
+
+		if t, ok := last[h.Host]; !ok {
+			report("stale", "never connected")
+		} else if now.Sub(t) > auditStaleAfter {
+			report("stale", "last connected %s", t.Format(time.DateOnly))
+		}
+	}
+	return findings
+}
+
+// isPublicHost reports whether the host resolves to an address outside of
+// private, loopback and link-local ranges
+func isPublicHost(host string) bool {
+	ips := []net.IP{net.ParseIP(host)}
+	if ips[0] == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return false
+		}
+		ips = ips[:0]
+		for _, a := range addrs {
+			ips = append(ips, a.IP)
+		}
+	}
+
+	for _, ip := range ips {
+		if !ip.IsPrivate() && !ip.IsLoopback() && !ip.IsLinkLocalUnicast() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// used when autosave_delay is not set
+const defaultAutosaveDelay = 2 * time.Second
+
+func (s Settings) autosaveDelay() time.Duration {
+	if s.AutosaveDelay <= 0 {
+		return defaultAutosaveDelay
+	}
+	return time.Duration(s.AutosaveDelay) * time.Second
+}
+
+// autosave tracks a save waiting for the delay to pass without further
+// changes
+type autosave struct {
+	seq     int
+	pending bool
+	// changes not written yet, also without autosave
+	unsaved bool
+}
+
+// autosaveMsg is sent once the delay after a change has passed
+type autosaveMsg struct{ seq int }
+
+// config is what the model would write to the config file
+func (m model) config() *Config {
+	return &Config{Settings: m.settings, Profiles: m.profiles, Templates: m.templates, Hosts: m.hosts, Deploy: m.deploy}
+}
+
+// scheduleAutosave saves the config once no other change was made within
+// the delay
+func (m *model) scheduleAutosave() tea.Cmd {
+	m.autosave.unsaved = true
+	if !m.settings.Autosave || m.configErr != nil {
+		return nil
+	}
+	m.autosave.seq++
+	m.autosave.pending = true
+	seq := m.autosave.seq
+	return tea.Tick(m.settings.autosaveDelay(), func(time.Time) tea.Msg {
+		return autosaveMsg{seq}
+	})
+}
+
+// flushAutosave writes a pending save right away
+func (m *model) flushAutosave() error {
+	if !m.autosave.pending {
+		return nil
+	}
+	m.autosave.pending = false
+	if err := saveConfig(m.config()); err != nil {
+		return err
+	}
+	m.autosave.unsaved = false
+	return nil
+}
+
+func (m *model) handleAutosave(msg autosaveMsg) tea.Cmd {
+	if msg.seq != m.autosave.seq {
+		// a later change restarted the delay
+		return nil
+	}
+	if err := m.flushAutosave(); err != nil {
+		return m.list.NewStatusMessage(errorMessageStyle("Autosave failed: " + err.Error()))
+	}
+	return nil
+}
+
+// quitting reports whether the list quits on msg
+func (m model) quitting(msg tea.KeyMsg) bool {
+	if key.Matches(msg, m.list.KeyMap.ForceQuit) {
+		return true
+	}
+	return m.list.FilterState() != list.Filtering && key.Matches(msg, m.list.KeyMap.Quit)
+}
+
+// flushOnQuit saves changes still waiting for the autosave delay before the
+// program exits. If that fails the quit is held back so the error can be
+// seen, quitting again discards the changes.
+func (m *model) flushOnQuit() tea.Cmd {
+	if err := m.flushAutosave(); err != nil {
+		return m.list.NewStatusMessage(errorMessageStyle("Autosave failed: " + err.Error() + ", quit again to discard the changes"))
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// quits reports whether cmd ends the program. Commands that don't return
+// right away, like ticks, are given up on.
+func quits(cmd tea.Cmd) bool {
+	if cmd == nil {
+		return false
+	}
+	result := make(chan tea.Msg, 1)
+	go func() { result <- cmd() }()
+	select {
+	case msg := <-result:
+		if batch, ok := msg.(tea.BatchMsg); ok {
+			return slices.ContainsFunc(batch, quits)
+		}
+		_, ok := msg.(tea.QuitMsg)
+		return ok
+	case <-time.After(100 * time.Millisecond):
+		return false
+	}
+}
+
+func TestQuitFlushesAutosave(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings string
+		quit     tea.KeyMsg
+		saved    bool
+	}{
+		{"q", "autosave = true\nautosave_delay = 60\n", keyPress("q"), true},
+		{"ctrl+c", "autosave = true\nautosave_delay = 60\n", tea.KeyMsg{Type: tea.KeyCtrlC}, true},
+		{"autosave off", "", keyPress("q"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tm tea.Model = newTestModel(t, "[settings]\n"+tt.settings+bulkTestConfig)
+			tm, _ = tm.Update(keyPress("d"))
+			tm, cmd := tm.Update(tt.quit)
+			if !quits(cmd) {
+				t.Error("didn't quit")
+			}
+
+			data, err := os.ReadFile(configFilePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if deleted := !strings.Contains(string(data), `"web1"`); deleted != tt.saved {
+				t.Errorf("the delete was saved: %t, want %t", deleted, tt.saved)
+			}
+			if m := tm.(model); m.autosave.pending {
+				t.Error("a save is still pending")
+			}
+		})
+	}
+}
+
+func TestQuitHeldBackWhenFlushFails(t *testing.T) {
+	var tm tea.Model = newTestModel(t, "[settings]\nautosave = true\nautosave_delay = 60\n"+bulkTestConfig)
+	tm, _ = tm.Update(keyPress("d"))
+	// a directory in place of the config makes the save fail
+	if err := os.Remove(configFilePath); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(configFilePath, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	tm, cmd := tm.Update(keyPress("q"))
+	if quits(cmd) {
+		t.Fatal("quit although the changes couldn't be saved")
+	}
+	if !tm.(model).autosave.unsaved {
+		t.Error("the changes aren't marked unsaved")
+	}
+	if _, cmd = tm.Update(keyPress("q")); !quits(cmd) {
+		t.Error("quitting again didn't discard the changes")
+	}
+}
+
+func TestSubtitleAutosaved(t *testing.T) {
+	var tm tea.Model = newTestModel(t, "[settings]\nautosave = true\nautosave_delay = 60\n"+bulkTestConfig)
+	tm, _ = tm.Update(keyPress("D"))
+	m := tm.(model)
+	if !m.autosave.pending {
+		t.Fatal("no save scheduled after changing the subtitle")
+	}
+
+	tm, _ = m.Update(autosaveMsg{m.autosave.seq})
+	data, err := os.ReadFile(configFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `subtitle = "` + tm.(model).settings.Subtitle + `"`; !strings.Contains(string(data), want) {
+		t.Errorf("saved config has no %s:\n%s", want, data)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// used when keep_backups is not set
+	defaultKeepBackups = 10
+
+	// sorts by name in time order, microseconds keep quick saves apart
+	backupTimeFormat = "20060102-150405.000000"
+)
+
+func backupDir() string {
+	return filepath.Join(filepath.Dir(configFilePath), "backups")
+}
+
+func (s Settings) keepBackups() int {
+	if s.KeepBackups == 0 {
+		return defaultKeepBackups
+	}
+	return s.KeepBackups
+}
+
+// backupConfig copies the config file as it is before a save into the
+// backups directory and prunes the old ones. A negative keep_backups turns
+// backups off.
+func backupConfig(settings Settings, now time.Time) error {
+	keep := settings.keepBackups()
+	if keep < 0 {
+		return nil
+	}
+	data, err := os.ReadFile(configFilePath)
+	if os.IsNotExist(err) || len(data) == 0 {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(backupDir(), 0o755); err != nil {
+		return err
+	}
+	name := now.Format(backupTimeFormat) + ".toml"
+	if err := os.WriteFile(filepath.Join(backupDir(), name), data, 0o600); err != nil {
+		return err
+	}
+	return pruneBackups(backupDir(), keep)
+}
+
+// pruneBackups removes all but the keep newest backups in dir, going by the
+// timestamp in their names. Files not named like a backup are left alone.
+func pruneBackups(dir string, keep int) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for _, e := range entries {
+		stamp, ok := strings.CutSuffix(e.Name(), ".toml")
+		if !ok || e.IsDir() {
+			continue
+		}
+		if _, err := time.Parse(backupTimeFormat, stamp); err != nil {
+			continue
+		}
+		names = append(names, e.Name())
+	}
+	slices.Sort(names)
+
+	for len(names) > keep {
+		if err := os.Remove(filepath.Join(dir, names[0])); err != nil {
+			return err
+		}
+		names = names[1:]
+	}
+	return nil
+}
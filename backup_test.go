@@ -0,0 +1,98 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// backupNames lists the files in dir
+func backupNames(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names
+}
+
+func TestBackupConfigKeepsNewest(t *testing.T) {
+	tests := []struct {
+		name string
+		keep int
+		want int
+	}{
+		{"default", 0, 10},
+		{"keep 3", 3, 3},
+		{"more than made", 20, 15},
+		{"off", -1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			old := configFilePath
+			t.Cleanup(func() { configFilePath = old })
+			configFilePath = filepath.Join(t.TempDir(), ".config")
+			settings := Settings{KeepBackups: tt.keep}
+
+			start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+			var made []string
+			for i := range 15 {
+				// every save backs up what the previous one wrote
+				if err := os.WriteFile(configFilePath, []byte{byte('a' + i)}, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				now := start.Add(time.Duration(i) * time.Minute)
+				if err := backupConfig(settings, now); err != nil {
+					t.Fatal(err)
+				}
+				made = append(made, now.Format(backupTimeFormat)+".toml")
+			}
+
+			if tt.want == 0 {
+				if _, err := os.Stat(backupDir()); !os.IsNotExist(err) {
+					t.Errorf("backups written although they are off: %v", err)
+				}
+				return
+			}
+			names := backupNames(t, backupDir())
+			if want := made[len(made)-tt.want:]; !slices.Equal(names, want) {
+				t.Errorf("kept %v, want the %d newest %v", names, tt.want, want)
+			}
+			data, err := os.ReadFile(filepath.Join(backupDir(), names[len(names)-1]))
+			if err != nil || string(data) != "o" {
+				t.Errorf("newest backup holds %q, %v, want the last config", data, err)
+			}
+		})
+	}
+}
+
+func TestPruneBackupsOrder(t *testing.T) {
+	dir := t.TempDir()
+	// created out of order, so only their names tell their age
+	files := []string{
+		"20260102-030405.000002.toml",
+		"20251231-235959.999999.toml",
+		"notes.txt",
+		"20260102-030405.000001.toml",
+		"broken.toml",
+		"20260101-000000.000000.toml",
+	}
+	for _, name := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := pruneBackups(dir, 2); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"20260102-030405.000001.toml", "20260102-030405.000002.toml", "broken.toml", "notes.txt"}
+	if got := backupNames(t, dir); !slices.Equal(got, want) {
+		t.Errorf("left %v, want %v", got, want)
+	}
+}
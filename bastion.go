@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// firstHop returns the first jump host of a proxy_jump chain, e.g.
+// "admin@gw:2222" of "admin@gw:2222,inner"
+func firstHop(proxyJump string) string {
+	hop, _, _ := strings.Cut(proxyJump, ",")
+	return strings.TrimPrefix(strings.TrimSpace(hop), "ssh://")
+}
+
+// jumpHost returns the first hop of the proxy_jump of h as a host: the host
+// in hosts with that alias, so it connects with its own settings, or one made
+// from [user@]host[:port]
+func jumpHost(h SSHHost, hosts []SSHHost) (SSHHost, error) {
+	if h.ProxyJump == "" || strings.EqualFold(h.ProxyJump, "none") {
+		return SSHHost{}, fmt.Errorf("%s has no proxy_jump", h.Host)
+	}
+	hop := firstHop(h.ProxyJump)
+	for _, other := range hosts {
+		if other.Host == hop {
+			return other, nil
+		}
+	}
+
+	jump := SSHHost{Host: hop}
+	address := hop
+	if i := strings.LastIndexByte(hop, '@'); i >= 0 {
+		jump.User, address = hop[:i], hop[i+1:]
+	}
+	jump.HostName = address
+	if host, port, err := net.SplitHostPort(address); err == nil {
+		n, err := strconv.Atoi(port)
+		if err != nil {
+			return SSHHost{}, fmt.Errorf("invalid port in proxy_jump %q", h.ProxyJump)
+		}
+		jump.HostName, jump.Port = host, n
+	}
+	return jump, nil
+}
+
+// connectToJumpHost opens a shell on the first hop of the selected host's
+// proxy_jump instead of the host, to debug the bastion
+func (m *model) connectToJumpHost() tea.Cmd {
+	h, ok := m.selectedHost()
+	if !ok {
+		return nil
+	}
+	var hosts []SSHHost
+	for _, item := range m.list.Items() {
+		hosts = append(hosts, item.(hostItem).SSHHost)
+	}
+	jump, err := jumpHost(h, hosts)
+	if err != nil {
+		return m.list.NewStatusMessage(errorMessageStyle(err.Error()))
+	}
+	return m.connectTo(jump, Profile{})
+}
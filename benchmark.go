@@ -0,0 +1,149 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+)
+
+// benchmarkResult is the timing of one step over all iterations
+type benchmarkResult struct {
+	step  string
+	times []time.Duration
+}
+
+func (r benchmarkResult) mean() time.Duration {
+	var total time.Duration
+	for _, t := range r.times {
+		total += t
+	}
+	return total / time.Duration(len(r.times))
+}
+
+func (r benchmarkResult) p99() time.Duration {
+	sorted := slices.Clone(r.times)
+	slices.Sort(sorted)
+	return sorted[(len(sorted)*99-1)/100]
+}
+
+// benchmark runs fn n times and records how long each run took
+func benchmark(step string, n int, fn func() error) (benchmarkResult, error) {
+	r := benchmarkResult{step: step, times: make([]time.Duration, n)}
+	for i := range n {
+		start := time.Now()
+		if err := fn(); err != nil {
+			return r, fmt.Errorf("%s: %w", step, err)
+		}
+		r.times[i] = time.Since(start)
+	}
+	return r, nil
+}
+
+// syntheticConfig builds a config with n hosts using most of the fields
+func syntheticConfig(n int) *Config {
+	config := &Config{Hosts: make([]SSHHost, n)}
+	for i := range config.Hosts {
+		id := strconv.Itoa(i)
+		config.Hosts[i] = SSHHost{
+			Host:         "host-" + id,
+			HostName:     fmt.Sprintf("10.%d.%d.%d", i/65536%256, i/256%256, i%256),
+			User:         "deploy",
+			Tags:         []string{"env-" + strconv.Itoa(i%4), "team-" + strconv.Itoa(i%7)},
+			Desc:         "synthetic host " + id,
+			Port:         22 + i%3,
+			IdentityFile: "~/.ssh/id_ed25519",
+			ProxyJump:    "bastion",
+			LocalForward: []string{"8080 localhost:80"},
+			Options:      map[string]string{"StrictHostKeyChecking": "accept-new"},
+		}
+	}
+	return config
+}
+
+func renderBenchmarkTable(results []benchmarkResult) string {
+	t := table.New().
+		Border(lipgloss.RoundedBorder()).
+		Headers("Step", "Runs", "Mean", "p99").
+		StyleFunc(func(row, col int) lipgloss.Style {
+			if row == table.HeaderRow {
+				return dashboardHeaderStyle
+			}
+			return lipgloss.NewStyle().Padding(0, 1)
+		})
+	for _, r := range results {
+		t.Row(r.step, strconv.Itoa(len(r.times)), r.mean().Round(time.Microsecond).String(), r.p99().Round(time.Microsecond).String())
+	}
+	return t.Render()
+}
+
+func runBenchmarkConfig(args []string) int {
+	fs := flag.NewFlagSet("benchmark-config", flag.ExitOnError)
+	hosts := fs.Int("hosts", 1000, "number of hosts in the generated config")
+	iterations := fs.Int("iterations", 100, "runs per step")
+	fs.Parse(args)
+	if *hosts < 1 || *iterations < 1 {
+		fmt.Fprintln(os.Stderr, "--hosts and --iterations must be positive")
+		return 2
+	}
+
+	dir, err := os.MkdirTemp("", "quickssh-benchmark")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to create temp dir:", err)
+		return 1
+	}
+	defer os.RemoveAll(dir)
+
+	config := syntheticConfig(*hosts)
+	path := filepath.Join(dir, ".config")
+	if err := writeConfigFile(path, config); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write config:", err)
+		return 1
+	}
+
+	// loadConfig reads the global path, point it at the synthetic file
+	realPath := configFilePath
+	configFilePath = path
+	defer func() { configFilePath = realPath }()
+
+	l := list.New(nil, newHostDelegate(Settings{}, nil), 0, 0)
+	steps := []struct {
+		name string
+		fn   func() error
+	}{
+		{"loadConfig", func() error {
+			_, err := loadConfig()
+			return err
+		}},
+		{"toItems", func() error {
+			toItems(config.Hosts)
+			return nil
+		}},
+		{"list.SetItems", func() error {
+			l.SetItems(toItems(config.Hosts))
+			return nil
+		}},
+	}
+
+	var results []benchmarkResult
+	for _, step := range steps {
+		r, err := benchmark(step.name, *iterations, step.fn)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "benchmark failed:", err)
+			return 1
+		}
+		results = append(results, r)
+	}
+
+	info, _ := os.Stat(path)
+	fmt.Printf("%d hosts, %d KiB config\n", *hosts, info.Size()/1024)
+	fmt.Println(renderBenchmarkTable(results))
+	return 0
+}
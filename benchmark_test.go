@@ -0,0 +1,103 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBenchmarkResult(t *testing.T) {
+	ms := func(values ...int) []time.Duration {
+		var times []time.Duration
+		for _, n := range values {
+			times = append(times, time.Duration(n)*time.Millisecond)
+		}
+		return times
+	}
+	hundred := make([]int, 100)
+	for i := range hundred {
+		hundred[i] = i + 1
+	}
+	tests := []struct {
+		name  string
+		times []time.Duration
+		mean  time.Duration
+		p99   time.Duration
+	}{
+		{"single run", ms(5), 5 * time.Millisecond, 5 * time.Millisecond},
+		{"unsorted", ms(3, 1, 2), 2 * time.Millisecond, 3 * time.Millisecond},
+		{"hundred runs", ms(hundred...), 50500 * time.Microsecond, 99 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := benchmarkResult{step: "step", times: tt.times}
+			if got := r.mean(); got != tt.mean {
+				t.Errorf("mean = %s, want %s", got, tt.mean)
+			}
+			if got := r.p99(); got != tt.p99 {
+				t.Errorf("p99 = %s, want %s", got, tt.p99)
+			}
+		})
+	}
+}
+
+func TestSyntheticConfigLoads(t *testing.T) {
+	config := syntheticConfig(300)
+	old := configFilePath
+	t.Cleanup(func() { configFilePath = old })
+	configFilePath = filepath.Join(t.TempDir(), ".config")
+	if err := writeConfigFile(configFilePath, config); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded.Hosts, config.Hosts) {
+		t.Error("the loaded hosts differ from the generated ones")
+	}
+	aliases := make(map[string]bool)
+	for _, h := range config.Hosts {
+		aliases[h.Host] = true
+	}
+	if len(aliases) != 300 {
+		t.Errorf("%d distinct aliases, want 300", len(aliases))
+	}
+}
+
+func TestRunBenchmarkConfig(t *testing.T) {
+	tests := []struct {
+		args []string
+		want int
+	}{
+		{[]string{"--hosts", "20", "--iterations", "3"}, 0},
+		{[]string{"--hosts", "0"}, 2},
+		{[]string{"--iterations", "-1"}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.args[1], func(t *testing.T) {
+			old := configFilePath
+			if got := runBenchmarkConfig(tt.args); got != tt.want {
+				t.Errorf("exit code %d, want %d", got, tt.want)
+			}
+			if configFilePath != old {
+				t.Errorf("config path left at %s", configFilePath)
+			}
+		})
+	}
+}
+
+func BenchmarkLoadConfig(b *testing.B) {
+	old := configFilePath
+	b.Cleanup(func() { configFilePath = old })
+	configFilePath = filepath.Join(b.TempDir(), ".config")
+	if err := writeConfigFile(configFilePath, syntheticConfig(1000)); err != nil {
+		b.Fatal(err)
+	}
+	for b.Loop() {
+		if _, err := loadConfig(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
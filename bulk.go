@@ -0,0 +1,254 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// most aliases a bulk confirmation lists before "and n more"
+const bulkSampleSize = 5
+
+// bulkChange is a change to many hosts waiting for confirmation
+type bulkChange struct {
+	// what happens to the hosts, e.g. "Delete" or "Remove the tag web from"
+	action string
+	// name of the change in the operations log
+	op      string
+	aliases []string
+	apply   func(m *model) tea.Cmd
+	// view shown again if the change is cancelled
+	back viewState
+}
+
+// bulkSample lists the first aliases, with a count of the others
+func bulkSample(aliases []string) string {
+	if len(aliases) <= bulkSampleSize {
+		return strings.Join(aliases, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(aliases[:bulkSampleSize], ", "), len(aliases)-bulkSampleSize)
+}
+
+// confirmBulk asks before applying c
+func (m *model) confirmBulk(c bulkChange) {
+	c.back = m.view
+	m.bulk = c
+	m.view = bulkConfirmView
+}
+
+// updateBulkConfirm applies the change on y and records it in the
+// operations log
+func (m *model) updateBulkConfirm(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "y", "Y":
+		c := m.bulk
+		m.bulk = bulkChange{}
+		m.view = c.back
+		cmd := c.apply(m)
+		if err := logOperation(m.settings, c.op, c.aliases); err != nil {
+			return tea.Batch(cmd, m.list.NewStatusMessage(errorMessageStyle("Failed to write the operations log: "+err.Error())))
+		}
+		return cmd
+	case "n", "N", "esc", "q":
+		m.view = m.bulk.back
+		m.bulk = bulkChange{}
+		return m.list.NewStatusMessage("Cancelled")
+	}
+	return nil
+}
+
+func (m model) bulkConfirmView() string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(fmt.Sprintf("%s %d hosts?", m.bulk.action, len(m.bulk.aliases))) + "\n\n")
+	b.WriteString(bulkSample(m.bulk.aliases) + "\n\n")
+	b.WriteString(checkFixStyle.Render("y: apply • n/esc: cancel"))
+	return b.String()
+}
+
+// bulkDelete asks to delete the visible hosts, all of them or the ones
+// matching the filter
+func (m *model) bulkDelete() tea.Cmd {
+	var aliases []string
+	for _, h := range m.visibleHosts() {
+		if !h.fromSSHConfig {
+			aliases = append(aliases, h.Host)
+		}
+	}
+	if len(aliases) == 0 {
+		return m.list.NewStatusMessage("No hosts to delete")
+	}
+	m.confirmBulk(bulkChange{
+		action:  "Delete",
+		op:      "delete",
+		aliases: aliases,
+		apply: func(m *model) tea.Cmd {
+			var edits []edit
+			// from the back so the other indexes stay valid
+			for i := len(m.hosts) - 1; i >= 0; i-- {
+				if slices.Contains(aliases, m.hosts[i].Host) {
+					deleted := m.hosts[i]
+					edits = append(edits, edit{index: i, before: &deleted})
+				}
+			}
+			status := fmt.Sprintf("Deleted %d hosts, ctrl+z brings them all back", len(aliases))
+			return tea.Batch(m.commit(edits...), m.list.NewStatusMessage(statusMessageStyle(status)))
+		},
+	})
+	return nil
+}
+
+// openRemoveTag asks which tag to remove from the visible hosts
+func (m *model) openRemoveTag() tea.Cmd {
+	var tags []string
+	for _, h := range m.visibleHosts() {
+		if !h.fromSSHConfig {
+			tags = append(tags, h.Tags...)
+		}
+	}
+	slices.Sort(tags)
+	tags = slices.Compact(tags)
+	if len(tags) == 0 {
+		return m.list.NewStatusMessage("The listed hosts have no tags")
+	}
+	m.selector = newSelector("Remove tag from the listed hosts", tags)
+	m.view = removeTagView
+	return nil
+}
+
+func (m *model) updateRemoveTag(msg tea.KeyMsg) tea.Cmd {
+	tag, done := m.selector.update(msg)
+	if !done {
+		return nil
+	}
+	m.view = listView
+	if tag == "" {
+		return nil
+	}
+
+	var aliases []string
+	for _, h := range m.visibleHosts() {
+		if !h.fromSSHConfig && slices.Contains(h.Tags, tag) {
+			aliases = append(aliases, h.Host)
+		}
+	}
+	m.confirmBulk(bulkChange{
+		action:  "Remove the tag " + tag + " from",
+		op:      "remove-tag " + tag,
+		aliases: aliases,
+		apply: func(m *model) tea.Cmd {
+			var edits []edit
+			for i, h := range m.hosts {
+				if !slices.Contains(aliases, h.Host) {
+					continue
+				}
+				before := h
+				after := h
+				after.Tags = slices.DeleteFunc(slices.Clone(h.Tags), func(t string) bool { return t == tag })
+				edits = append(edits, edit{index: i, before: &before, after: &after})
+			}
+			status := fmt.Sprintf("Removed %s from %d hosts, press s to save or ctrl+z to undo", tag, len(aliases))
+			return tea.Batch(m.commit(edits...), m.list.NewStatusMessage(statusMessageStyle(status)))
+		},
+	})
+	return nil
+}
+
+// bulkTagTargets returns the aliases of the hosts that get tag from a bulk
+// tag: the visible ones from the config that don't have it yet
+func bulkTagTargets(visible []SSHHost, tag string) []string {
+	var aliases []string
+	for _, h := range visible {
+		if !h.fromSSHConfig && !slices.Contains(h.Tags, tag) {
+			aliases = append(aliases, h.Host)
+		}
+	}
+	return aliases
+}
+
+// openBulkTag asks for a tag to add to the visible hosts, all of them or the
+// ones matching the filter
+func (m *model) openBulkTag() tea.Cmd {
+	if !slices.ContainsFunc(m.visibleHosts(), func(h SSHHost) bool { return !h.fromSSHConfig }) {
+		return m.list.NewStatusMessage("No hosts to tag")
+	}
+	m.bulkTag = textinput.New()
+	m.bulkTag.Prompt = "tag: "
+	m.bulkTag.Placeholder = "prod"
+	m.view = bulkTagView
+	return m.bulkTag.Focus()
+}
+
+func (m *model) updateBulkTag(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		m.view = listView
+		return nil
+	case "enter":
+		tag := strings.TrimSpace(m.bulkTag.Value())
+		if err := checkTagName(tag); err != nil {
+			return m.list.NewStatusMessage(errorMessageStyle(err.Error()))
+		}
+		m.view = listView
+		aliases := bulkTagTargets(m.visibleHosts(), tag)
+		if len(aliases) == 0 {
+			return m.list.NewStatusMessage("The visible hosts already have the tag " + tag)
+		}
+		m.confirmBulk(bulkChange{
+			action:  "Add the tag " + tag + " to",
+			op:      "add-tag " + tag,
+			aliases: aliases,
+			apply: func(m *model) tea.Cmd {
+				var edits []edit
+				for i, h := range m.hosts {
+					if !slices.Contains(aliases, h.Host) {
+						continue
+					}
+					before := h
+					after := h
+					after.Tags = append(slices.Clone(h.Tags), tag)
+					edits = append(edits, edit{index: i, before: &before, after: &after})
+				}
+				// one step to undo, and saved right away
+				cmds := []tea.Cmd{m.commit(edits...)}
+				if m.configErr != nil {
+					status := fmt.Sprintf("Added %s to %d hosts, not saved, fix the config file first", tag, len(aliases))
+					return tea.Batch(append(cmds, m.list.NewStatusMessage(errorMessageStyle(status)))...)
+				}
+				m.autosave.pending = false
+				if err := saveConfig(m.config()); err != nil {
+					return tea.Batch(append(cmds, m.list.NewStatusMessage(errorMessageStyle("Failed to save config: "+err.Error())))...)
+				}
+				m.autosave.unsaved = false
+				status := fmt.Sprintf("Added %s to %d hosts and saved", tag, len(aliases))
+				return tea.Batch(append(cmds, m.list.NewStatusMessage(statusMessageStyle(status)))...)
+			},
+		})
+		return nil
+	}
+	var cmd tea.Cmd
+	m.bulkTag, cmd = m.bulkTag.Update(msg)
+	return cmd
+}
+
+func (m model) bulkTagView() string {
+	var b strings.Builder
+	visible := m.visibleHosts()
+	b.WriteString(titleStyle.Render(fmt.Sprintf("Tag the %d visible hosts", len(visible))) + "\n\n")
+	b.WriteString(m.bulkTag.View() + "\n\n")
+	if tag := strings.TrimSpace(m.bulkTag.Value()); tag != "" {
+		if err := checkTagName(tag); err != nil {
+			b.WriteString(errorMessageStyle(err.Error()))
+		} else {
+			b.WriteString(fmt.Sprintf("%d hosts don't have it yet", len(bulkTagTargets(visible, tag))))
+		}
+		b.WriteString("\n\n")
+	}
+	if suggestions := suggestTags(allTags(m.hosts), m.bulkTag.Value()); len(suggestions) > 0 {
+		b.WriteString(detailLabelStyle.Render("existing: "+strings.Join(suggestions, ", ")) + "\n\n")
+	}
+	b.WriteString(checkFixStyle.Render("enter: add • esc: cancel"))
+	return b.String()
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newTestModel starts the TUI model on a config with the given TOML in a
+// temporary directory
+func newTestModel(t testing.TB, config string) model {
+	t.Helper()
+	old := configFilePath
+	t.Cleanup(func() { configFilePath = old })
+	configFilePath = filepath.Join(t.TempDir(), ".config")
+	if err := os.WriteFile(configFilePath, []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return startTestModel(t)
+}
+
+// startTestModel starts the TUI model on configFilePath, with a home that has
+// no ~/.ssh/config
+func startTestModel(t testing.TB) model {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	var tm tea.Model = newModel()
+	tm, _ = tm.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	return tm.(model)
+}
+
+func keyPress(s string) tea.KeyMsg {
+	switch s {
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+const bulkTestConfig = `
+[[hosts]]
+host = "web1"
+hostname = "10.0.0.1"
+tags = ["web", "prod"]
+
+[[hosts]]
+host = "web2"
+hostname = "10.0.0.2"
+tags = ["web"]
+
+[[hosts]]
+host = "db1"
+hostname = "10.0.0.3"
+tags = ["db", "prod"]
+`
+
+func aliasesOf(hosts []SSHHost) []string {
+	var aliases []string
+	for _, h := range hosts {
+		aliases = append(aliases, h.Host)
+	}
+	return aliases
+}
+
+func TestBulkDeleteFiltered(t *testing.T) {
+	tests := []struct {
+		filter string
+		want   []string
+		kept   []string
+	}{
+		{"", []string{"web1", "web2", "db1"}, nil},
+		{"db1", []string{"db1"}, []string{"web1", "web2"}},
+		{"web", []string{"web1", "web2"}, []string{"db1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			m := newTestModel(t, bulkTestConfig)
+			if tt.filter != "" {
+				m.list.SetFilterText(tt.filter)
+			}
+			m.bulkDelete()
+			if m.view != bulkConfirmView {
+				t.Fatalf("view = %d, want the confirmation", m.view)
+			}
+			if !slices.Equal(m.bulk.aliases, tt.want) {
+				t.Errorf("confirming %v, want %v", m.bulk.aliases, tt.want)
+			}
+			m.updateBulkConfirm(keyPress("y"))
+			if got := aliasesOf(m.hosts); !slices.Equal(got, tt.kept) {
+				t.Errorf("hosts left %v, want %v", got, tt.kept)
+			}
+			m.undo()
+			if got := aliasesOf(m.hosts); !slices.Equal(got, []string{"web1", "web2", "db1"}) {
+				t.Errorf("after one undo %v, want all hosts back in order", got)
+			}
+		})
+	}
+}
+
+// more hosts than undo steps are kept still come back with one undo
+func TestBulkDeleteUndoMany(t *testing.T) {
+	m := newLargeModel(t, 150)
+	want := aliasesOf(m.hosts)
+	m.bulkDelete()
+	m.updateBulkConfirm(keyPress("y"))
+	if len(m.hosts) > 0 {
+		t.Fatalf("%d hosts left", len(m.hosts))
+	}
+	m.undo()
+	if got := aliasesOf(m.hosts); !slices.Equal(got, want) {
+		t.Errorf("after one undo %d hosts, want all %d back in order", len(got), len(want))
+	}
+	if got := len(m.list.Items()); got != len(want) {
+		t.Errorf("%d items listed, want %d", got, len(want))
+	}
+}
+
+func TestRemoveTagFiltered(t *testing.T) {
+	m := newTestModel(t, bulkTestConfig)
+	m.list.SetFilterText("db1")
+	m.openRemoveTag()
+	if !slices.Equal(m.selector.options, []string{"db", "prod"}) {
+		t.Fatalf("offered tags %v, want those of db1", m.selector.options)
+	}
+	m.selector.cursor = slices.Index(m.selector.options, "prod")
+	m.updateRemoveTag(keyPress("enter"))
+	if !slices.Equal(m.bulk.aliases, []string{"db1"}) {
+		t.Fatalf("confirming %v, want [db1]", m.bulk.aliases)
+	}
+	m.updateBulkConfirm(keyPress("y"))
+	for _, h := range m.hosts {
+		if want := h.Host == "web1"; slices.Contains(h.Tags, "prod") != want {
+			t.Errorf("%s has tags %v", h.Host, h.Tags)
+		}
+	}
+	m.undo()
+	if h := m.hosts[2]; !slices.Equal(h.Tags, []string{"db", "prod"}) {
+		t.Errorf("after undo db1 has tags %v", h.Tags)
+	}
+}
+
+func TestBulkTagUndo(t *testing.T) {
+	m := newTestModel(t, bulkTestConfig)
+	m.list.SetFilterText("web")
+	m.openBulkTag()
+	m.bulkTag.SetValue("staging")
+	m.updateBulkTag(keyPress("enter"))
+	m.updateBulkConfirm(keyPress("y"))
+
+	tagged := func() []string {
+		var aliases []string
+		for _, h := range m.hosts {
+			if slices.Contains(h.Tags, "staging") {
+				aliases = append(aliases, h.Host)
+			}
+		}
+		return aliases
+	}
+	if got := tagged(); !slices.Equal(got, []string{"web1", "web2"}) {
+		t.Fatalf("tagged %v, want [web1 web2]", got)
+	}
+	m.undo()
+	if got := tagged(); len(got) > 0 {
+		t.Errorf("after one undo %v still have the tag", got)
+	}
+	if len(m.edits.undo) > 0 {
+		t.Errorf("%d steps left to undo, want none", len(m.edits.undo))
+	}
+	m.redo()
+	if got := tagged(); !slices.Equal(got, []string{"web1", "web2"}) {
+		t.Errorf("after redo tagged %v, want [web1 web2]", got)
+	}
+}
+
+func TestBulkTagFiltered(t *testing.T) {
+	tests := []struct {
+		filter string
+		tag    string
+		want   []string
+	}{
+		{"", "new", []string{"web1", "web2", "db1"}},
+		{"web", "new", []string{"web1", "web2"}},
+		{"db1", "new", []string{"db1"}},
+		// hosts that have the tag already are left out
+		{"", "prod", []string{"web2"}},
+		{"web", "web", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter+"+"+tt.tag, func(t *testing.T) {
+			m := newTestModel(t, bulkTestConfig)
+			if tt.filter != "" {
+				m.list.SetFilterText(tt.filter)
+			}
+			m.openBulkTag()
+			m.bulkTag.SetValue(tt.tag)
+			m.updateBulkTag(keyPress("enter"))
+			if tt.want == nil {
+				if m.view == bulkConfirmView {
+					t.Fatalf("asked to tag %v, want nothing to do", m.bulk.aliases)
+				}
+				return
+			}
+			if m.view != bulkConfirmView || !slices.Equal(m.bulk.aliases, tt.want) {
+				t.Fatalf("confirming %v, want %v", m.bulk.aliases, tt.want)
+			}
+			before := slices.Clone(m.hosts)
+			m.updateBulkConfirm(keyPress("y"))
+			for i, h := range m.hosts {
+				had := slices.Contains(before[i].Tags, tt.tag)
+				if want := had || slices.Contains(tt.want, h.Host); slices.Contains(h.Tags, tt.tag) != want {
+					t.Errorf("%s has tags %v", h.Host, h.Tags)
+				}
+			}
+			// saved right away, with the filter still applied
+			config, err := loadConfig()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.EqualFunc(config.Hosts, m.hosts, func(a, b SSHHost) bool { return slices.Equal(a.Tags, b.Tags) }) {
+				t.Error("the saved config differs from the list")
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+)
+
+// catRemote streams the remote file at path into w, with the host's ssh
+// settings. ssh's own errors go to stderr.
+func catRemote(h SSHHost, path string, w io.Writer, stderr io.Writer) error {
+	cmd := remoteCommand(h, "cat -- "+shellQuote(path))
+	cmd.Stdout = w
+	cmd.Stderr = stderr
+	return cmd.Run()
+}
+
+func runCat(args []string) int {
+	fs := flag.NewFlagSet("cat", flag.ExitOnError)
+	alias := fs.String("host", "", "alias of the host")
+	path := fs.String("remote", "", "path of the file on the host")
+	asBase64 := fs.Bool("base64", false, "print the file base64 encoded, for binary files")
+	asJSON := fs.Bool("json", false, "parse the file as JSON and pretty-print it")
+	fs.Parse(args)
+
+	if *path == "" {
+		fmt.Fprintln(os.Stderr, "no file given, use --remote <path>")
+		return 2
+	}
+	if *asBase64 && *asJSON {
+		fmt.Fprintln(os.Stderr, "--base64 and --json can't be combined")
+		return 2
+	}
+	h, err := loadHost(*alias)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	switch {
+	case *asBase64:
+		enc := base64.NewEncoder(base64.StdEncoding, os.Stdout)
+		err = catRemote(h, *path, enc, os.Stderr)
+		enc.Close()
+		fmt.Println()
+	case *asJSON:
+		var raw, pretty bytes.Buffer
+		if err = catRemote(h, *path, &raw, os.Stderr); err == nil {
+			if err := json.Indent(&pretty, raw.Bytes(), "", "  "); err != nil {
+				fmt.Fprintln(os.Stderr, "not valid JSON:", err)
+				return 1
+			}
+			fmt.Println(pretty.String())
+		}
+	default:
+		err = catRemote(h, *path, os.Stdout, os.Stderr)
+	}
+
+	// ssh and cat have already explained a failure on stderr
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to run ssh:", err)
+		return 1
+	}
+	return 0
+}
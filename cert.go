@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// loadCertificate parses an OpenSSH certificate file (the *-cert.pub file)
+func loadCertificate(path string) (*ssh.Certificate, error) {
+	data, err := os.ReadFile(expandPath(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate: %w", err)
+	}
+	key, _, _, _, err := ssh.ParseAuthorizedKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+	cert, ok := key.(*ssh.Certificate)
+	if !ok {
+		return nil, fmt.Errorf("%s is a public key, not a certificate", path)
+	}
+	return cert, nil
+}
+
+// certificateExpiry returns when the certificate stops being valid, the zero
+// time means it never expires
+func certificateExpiry(path string) (time.Time, error) {
+	cert, err := loadCertificate(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if cert.ValidBefore == ssh.CertTimeInfinity {
+		return time.Time{}, nil
+	}
+	return time.Unix(int64(cert.ValidBefore), 0), nil
+}
+
+func runCert(args []string) int {
+	if len(args) == 0 || args[0] != "show" {
+		fmt.Fprintln(os.Stderr, "usage: quickssh cert show --host <alias>")
+		return 2
+	}
+
+	fs := flag.NewFlagSet("cert show", flag.ExitOnError)
+	alias := fs.String("host", "", "alias of the host whose certificate to show")
+	fs.Parse(args[1:])
+
+	h, err := loadHost(*alias)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if h.CertificateFile == "" {
+		fmt.Fprintf(os.Stderr, "%s has no certificate_file configured\n", h.Host)
+		return 1
+	}
+
+	out, err := exec.Command("ssh-keygen", "-L", "-f", expandPath(h.CertificateFile)).CombinedOutput()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ssh-keygen failed: %v\n%s", err, out)
+		return 1
+	}
+
+	fmt.Println(h.CertificateFile)
+	for _, line := range certSections(out, "Valid", "Principals", "Extensions") {
+		fmt.Println(line)
+	}
+	return 0
+}
+
+// certSections picks the named fields and their indented values out of the
+// output of ssh-keygen -L
+func certSections(out []byte, names ...string) []string {
+	var lines []string
+	keep, fieldIndent := false, 0
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimLeft(line, " \t")
+		indent := len(line) - len(trimmed)
+		if trimmed == "" {
+			continue
+		}
+
+		if keep && indent > fieldIndent {
+			lines = append(lines, "    "+trimmed)
+			continue
+		}
+
+		keep = false
+		for _, name := range names {
+			if strings.HasPrefix(trimmed, name+":") {
+				keep, fieldIndent = true, indent
+				lines = append(lines, "  "+strings.TrimSpace(trimmed))
+			}
+		}
+	}
+	return lines
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/bubbles/progress"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// number of hosts checked at the same time by a batch check
+const reachWorkers = 16
+
+// reachBatch is a running check of all hosts, started with R
+type reachBatch struct {
+	seq     int
+	total   int
+	done    int
+	up      int
+	cancel  context.CancelFunc
+	results chan reachabilityMsg
+	bar     progress.Model
+}
+
+// sent for each host checked by the batch with the given seq
+type batchReachMsg struct {
+	seq    int
+	result reachabilityMsg
+}
+
+// sent once every host of the batch has been checked
+type batchDoneMsg struct{ seq int }
+
+// startReachBatch checks all hosts in the background with a bounded number
+// of workers. The results arrive one message at a time through next.
+func startReachBatch(hosts []SSHHost, seq int) reachBatch {
+	ctx, cancel := context.WithCancel(context.Background())
+	b := reachBatch{
+		seq:     seq,
+		total:   len(hosts),
+		cancel:  cancel,
+		results: make(chan reachabilityMsg),
+		bar:     progress.New(progress.WithDefaultGradient(), progress.WithWidth(40)),
+	}
+
+	queue := make(chan SSHHost)
+	go func() {
+		defer close(queue)
+		for _, h := range hosts {
+			select {
+			case queue <- h:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for range min(reachWorkers, len(hosts)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for h := range queue {
+				msg := reachabilityMsg{host: h.Host, state: reachable}
+				msg.latency, msg.err = checkReachable(ctx, h, 3*time.Second)
+				if msg.err != nil {
+					msg.state = unreachable
+				}
+				select {
+				case b.results <- msg:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(b.results)
+	}()
+	return b
+}
+
+func (b reachBatch) next() tea.Cmd {
+	return func() tea.Msg {
+		result, ok := <-b.results
+		if !ok {
+			return batchDoneMsg{seq: b.seq}
+		}
+		return batchReachMsg{seq: b.seq, result: result}
+	}
+}
+
+func (b reachBatch) summary() string {
+	return fmt.Sprintf("%d reachable, %d unreachable", b.up, b.done-b.up)
+}
+
+func (m model) checkAllView() string {
+	b := m.batch
+	percent := 0.0
+	if b.total > 0 {
+		percent = float64(b.done) / float64(b.total)
+	}
+	return titleStyle.Render("Checking all hosts") + "\n\n" +
+		b.bar.ViewAs(percent) + "\n\n" +
+		fmt.Sprintf("%d of %d checked: %s", b.done, b.total, b.summary()) + "\n\n" +
+		checkFixStyle.Render("esc: cancel")
+}
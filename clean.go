@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// hosts dialed at the same time by clean
+const cleanWorkers = 16
+
+// findUnreachable dials every host and returns the ones that didn't answer
+// within timeout, with the error of each
+func findUnreachable(hosts []SSHHost, timeout time.Duration) ([]SSHHost, []error) {
+	errs := make([]error, len(hosts))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(cleanWorkers, len(hosts)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				_, errs[i] = checkReachable(context.Background(), hosts[i], timeout)
+			}
+		}()
+	}
+	for i := range hosts {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var unreachable []SSHHost
+	var reasons []error
+	for i, err := range errs {
+		if err != nil {
+			unreachable = append(unreachable, hosts[i])
+			reasons = append(reasons, err)
+		}
+	}
+	return unreachable, reasons
+}
+
+type removeAnswer int
+
+const (
+	keepHost removeAnswer = iota
+	removeHost
+	keepRemaining
+)
+
+// askRemove asks whether to remove alias. Anything but y keeps it.
+func askRemove(in *bufio.Reader, out io.Writer, alias string) removeAnswer {
+	fmt.Fprintf(out, "Remove %s (unreachable)? [y/N/skip-all] ", alias)
+	answer, _ := in.ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return removeHost
+	case "s", "skip-all":
+		return keepRemaining
+	}
+	return keepHost
+}
+
+func runClean(args []string) int {
+	fs := flag.NewFlagSet("clean", flag.ExitOnError)
+	removeUnreachable := fs.Bool("remove-unreachable", false, "ask to remove every host that can't be reached")
+	timeout := fs.Duration("timeout", 5*time.Second, "how long to wait for each host")
+	dryRun := fs.Bool("dry-run", false, "only list the unreachable hosts")
+	fs.Parse(args)
+
+	if !*removeUnreachable {
+		fmt.Fprintln(os.Stderr, "usage: quickssh clean --remove-unreachable [--timeout 5s] [--dry-run]")
+		return 2
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load config:", err)
+		return 1
+	}
+
+	// hosts behind a jump host can't be dialed from here
+	var direct []SSHHost
+	for _, h := range config.Hosts {
+		if h.ProxyJump != "" {
+			fmt.Printf("Not checking %s, it is reached through %s\n", h.Host, h.ProxyJump)
+			continue
+		}
+		direct = append(direct, h)
+	}
+
+	fmt.Printf("Checking %d hosts...\n", len(direct))
+	unreachable, reasons := findUnreachable(direct, *timeout)
+	if len(unreachable) == 0 {
+		fmt.Println("All hosts are reachable")
+		return 0
+	}
+	if *dryRun {
+		for i, h := range unreachable {
+			fmt.Printf("%s is unreachable: %v\n", h.Host, reasons[i])
+		}
+		return 0
+	}
+
+	in := bufio.NewReader(os.Stdin)
+	var remove, keep []string
+	skipAll := false
+	for i, h := range unreachable {
+		if skipAll {
+			keep = append(keep, h.Host)
+			continue
+		}
+		fmt.Printf("%s: %v\n", h.Host, reasons[i])
+		switch askRemove(in, os.Stdout, h.Host) {
+		case removeHost:
+			remove = append(remove, h.Host)
+		case keepRemaining:
+			skipAll = true
+			keep = append(keep, h.Host)
+		default:
+			keep = append(keep, h.Host)
+		}
+	}
+
+	if len(remove) > 0 {
+		config.Hosts = slices.DeleteFunc(config.Hosts, func(h SSHHost) bool {
+			return slices.Contains(remove, h.Host)
+		})
+		if err := saveConfig(config); err != nil {
+			fmt.Fprintln(os.Stderr, "failed to save config:", err)
+			return 1
+		}
+	}
+	for _, alias := range remove {
+		fmt.Println("Removed", alias)
+	}
+	for _, alias := range keep {
+		fmt.Println("Kept", alias)
+	}
+	for _, h := range config.Hosts {
+		if slices.Contains(remove, h.ProxyJump) {
+			fmt.Printf("Warning: %s still jumps through the removed %s\n", h.Host, h.ProxyJump)
+		}
+	}
+	return 0
+}
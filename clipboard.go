@@ -0,0 +1,28 @@
+package main
+
+import (
+	"os"
+
+	"github.com/atotto/clipboard"
+	"github.com/aymanbagabas/go-osc52/v2"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// copyToClipboard writes text to the system clipboard. Without one (e.g. on a
+// remote machine) it falls back to an OSC 52 sequence, which asks the
+// terminal to set its clipboard instead.
+func copyToClipboard(text string) error {
+	if err := clipboard.WriteAll(text); err == nil {
+		return nil
+	}
+	_, err := osc52.New(text).WriteTo(os.Stderr)
+	return err
+}
+
+// copyWithStatus copies text and reports it in the list status bar
+func (m *model) copyWithStatus(what, text string) tea.Cmd {
+	if err := copyToClipboard(text); err != nil {
+		return m.list.NewStatusMessage(errorMessageStyle("Failed to copy " + what + ": " + err.Error()))
+	}
+	return m.list.NewStatusMessage(statusMessageStyle("Copied " + what + ": " + text))
+}
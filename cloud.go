@@ -0,0 +1,264 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+)
+
+// CloudInstance is a machine as reported by a cloud provider
+type CloudInstance struct {
+	ID        string
+	Name      string
+	PublicIP  string
+	PrivateIP string
+}
+
+// CloudProvider lists the instances of one cloud account. Adding a provider
+// only needs an implementation and an entry in cloudProviders.
+type CloudProvider interface {
+	// Name is stored in cloud_id and added as a tag to synced hosts
+	Name() string
+	Instances() ([]CloudInstance, error)
+}
+
+var cloudProviders = map[string]func(region, project string) CloudProvider{
+	"aws": func(region, _ string) CloudProvider { return awsProvider{region: region} },
+	"gcp": func(_, project string) CloudProvider { return gcpProvider{project: project} },
+}
+
+// awsProvider lists EC2 instances with the aws cli and its credentials
+type awsProvider struct {
+	region string
+}
+
+func (awsProvider) Name() string { return "aws" }
+
+func (p awsProvider) Instances() ([]CloudInstance, error) {
+	args := []string{"ec2", "describe-instances", "--output", "json"}
+	if p.region != "" {
+		args = append(args, "--region", p.region)
+	}
+	out, err := runCloudCLI("aws", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Reservations []struct {
+			Instances []struct {
+				InstanceId       string
+				PublicIpAddress  string
+				PrivateIpAddress string
+				State            struct{ Name string }
+				Tags             []struct{ Key, Value string }
+			}
+		}
+	}
+	if err := json.Unmarshal(out, &result); err != nil {
+		return nil, fmt.Errorf("unexpected aws output: %w", err)
+	}
+
+	var instances []CloudInstance
+	for _, r := range result.Reservations {
+		for _, i := range r.Instances {
+			// terminated instances stay listed for a while after they're gone
+			if i.State.Name == "terminated" || i.State.Name == "shutting-down" {
+				continue
+			}
+			instance := CloudInstance{ID: i.InstanceId, PublicIP: i.PublicIpAddress, PrivateIP: i.PrivateIpAddress}
+			for _, tag := range i.Tags {
+				if tag.Key == "Name" {
+					instance.Name = tag.Value
+				}
+			}
+			instances = append(instances, instance)
+		}
+	}
+	return instances, nil
+}
+
+// gcpProvider lists Compute Engine instances with the gcloud cli
+type gcpProvider struct {
+	project string
+}
+
+func (gcpProvider) Name() string { return "gcp" }
+
+func (p gcpProvider) Instances() ([]CloudInstance, error) {
+	args := []string{"compute", "instances", "list", "--format=json"}
+	if p.project != "" {
+		args = append(args, "--project", p.project)
+	}
+	out, err := runCloudCLI("gcloud", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []struct {
+		ID                string `json:"id"`
+		Name              string `json:"name"`
+		NetworkInterfaces []struct {
+			NetworkIP     string `json:"networkIP"`
+			AccessConfigs []struct {
+				NatIP string `json:"natIP"`
+			} `json:"accessConfigs"`
+		} `json:"networkInterfaces"`
+	}
+	if err := json.Unmarshal(out, &result); err != nil {
+		return nil, fmt.Errorf("unexpected gcloud output: %w", err)
+	}
+
+	// stopped instances are listed as TERMINATED but still exist
+	instances := make([]CloudInstance, 0, len(result))
+	for _, i := range result {
+		instance := CloudInstance{ID: i.ID, Name: i.Name}
+		if len(i.NetworkInterfaces) > 0 {
+			nic := i.NetworkInterfaces[0]
+			instance.PrivateIP = nic.NetworkIP
+			if len(nic.AccessConfigs) > 0 {
+				instance.PublicIP = nic.AccessConfigs[0].NatIP
+			}
+		}
+		instances = append(instances, instance)
+	}
+	return instances, nil
+}
+
+func runCloudCLI(name string, args ...string) ([]byte, error) {
+	out, err := exec.Command(name, args...).Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return nil, fmt.Errorf("%s failed: %s", name, lastLine(strings.TrimSpace(string(exitErr.Stderr))))
+	}
+	return out, err
+}
+
+// cloudSyncResult counts what a sync changed
+type cloudSyncResult struct {
+	added, updated, decommissioned, restored int
+}
+
+func (r cloudSyncResult) changed() bool {
+	return r != cloudSyncResult{}
+}
+
+// SyncCloudHosts updates hosts to match the instances of a provider. New
+// instances are added, addresses of known ones updated and hosts whose
+// instance is gone are marked decommissioned instead of being deleted.
+func SyncCloudHosts(hosts []SSHHost, provider string, instances []CloudInstance, user string, private bool) ([]SSHHost, cloudSyncResult) {
+	var result cloudSyncResult
+	address := func(i CloudInstance) string {
+		if private || i.PublicIP == "" {
+			return i.PrivateIP
+		}
+		return i.PublicIP
+	}
+
+	current := make(map[string]CloudInstance, len(instances))
+	for _, i := range instances {
+		current[provider+":"+i.ID] = i
+	}
+
+	for n := range hosts {
+		h := &hosts[n]
+		if !strings.HasPrefix(h.CloudID, provider+":") {
+			continue
+		}
+		i, ok := current[h.CloudID]
+		delete(current, h.CloudID)
+		switch {
+		case !ok && !h.Decommissioned:
+			h.Decommissioned = true
+			result.decommissioned++
+		case ok && h.Decommissioned:
+			h.Decommissioned = false
+			result.restored++
+		}
+		if ok && address(i) != "" && address(i) != h.HostName {
+			h.HostName = address(i)
+			result.updated++
+		}
+	}
+
+	// new instances, in the order the provider listed them
+	for _, i := range instances {
+		if _, ok := current[provider+":"+i.ID]; !ok {
+			continue
+		}
+		alias := i.Name
+		if alias == "" {
+			alias = i.ID
+		} else if slices.ContainsFunc(hosts, func(h SSHHost) bool { return h.Host == alias }) {
+			alias += "-" + i.ID
+		}
+		hosts = append(hosts, SSHHost{
+			Host:     alias,
+			HostName: address(i),
+			User:     user,
+			Tags:     []string{provider},
+			CloudID:  provider + ":" + i.ID,
+		})
+		result.added++
+	}
+	return hosts, result
+}
+
+func runCloudSync(args []string) int {
+	fs := flag.NewFlagSet("cloud-sync", flag.ExitOnError)
+	providerName := fs.String("provider", "", "cloud provider to sync from: aws, gcp")
+	region := fs.String("region", "", "aws: region, defaults to the aws cli configuration")
+	project := fs.String("project", "", "gcp: project, defaults to the gcloud configuration")
+	user := fs.String("user", "", "user for newly added hosts")
+	private := fs.Bool("private", false, "use private instead of public addresses")
+	interval := fs.Duration("interval", 0, "keep running and sync at this interval, e.g. 10m")
+	fs.Parse(args)
+
+	newProvider, ok := cloudProviders[*providerName]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown provider %q\n", *providerName)
+		return 2
+	}
+	provider := newProvider(*region, *project)
+
+	for {
+		if err := cloudSync(provider, *user, *private); err != nil {
+			fmt.Fprintln(os.Stderr, "cloud sync failed:", err)
+			if *interval == 0 {
+				return 1
+			}
+		}
+		if *interval == 0 {
+			return 0
+		}
+		time.Sleep(*interval)
+	}
+}
+
+// cloudSync runs a single sync and saves the config if anything changed
+func cloudSync(provider CloudProvider, user string, private bool) error {
+	instances, err := provider.Instances()
+	if err != nil {
+		return err
+	}
+	// loaded after the provider call so edits made meanwhile aren't lost
+	config, err := loadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	var result cloudSyncResult
+	config.Hosts, result = SyncCloudHosts(config.Hosts, provider.Name(), instances, user, private)
+	fmt.Printf("%s %s: %d added, %d updated, %d decommissioned, %d back\n",
+		time.Now().Format(time.DateTime), provider.Name(), result.added, result.updated, result.decommissioned, result.restored)
+	if !result.changed() {
+		return nil
+	}
+	return saveConfig(config)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// subcommands are the non-interactive commands by name, each returning the
+// process exit code
+func subcommands() map[string]func(args []string) int {
+	return map[string]func(args []string) int{
+		"doctor":            runDoctor,
+		"share":             runShare,
+		"connect":           runConnect,
+		"env":               runEnv,
+		"exec":              runExec,
+		"port-scan":         runPortScan,
+		"whoami":            runWhoami,
+		"cert":              runCert,
+		"audit":             runAudit,
+		"export":            runExport,
+		"benchmark-config":  runBenchmarkConfig,
+		"mkdir":             runMkdir,
+		"tail":              runTail,
+		"diff-remote":       runDiffRemote,
+		"uptime":            runUptime,
+		"df":                runDF,
+		"cat":               runCat,
+		"gc":                runGC,
+		"convert":           runConvert,
+		"import":            runImport,
+		"split":             runSplit,
+		"merge":             runMerge,
+		"cloud-sync":        runCloudSync,
+		"multiplex":         runMultiplex,
+		"rsync":             runRsync,
+		"snapshot":          runSnapshot,
+		"deploy":            runDeploy,
+		"ping":              runPing,
+		"watch":             runWatch,
+		"repl":              runRepl,
+		"template-host":     runTemplateHost,
+		"clean":             runClean,
+		"generate-keys":     runGenerateKeys,
+		"copy-id":           runCopyID,
+		"alert":             runAlert,
+		"tag":               runTag,
+		"port-forward-list": runPortForwardList,
+		"host":              runHost,
+		"config":            runConfig,
+	}
+}
+
+// runCommand dispatches the non-interactive subcommands and returns the
+// process exit code
+func runCommand(name string, args []string) int {
+	if run, ok := subcommands()[name]; ok {
+		return run(args)
+	}
+	// anything else names a host to connect to
+	return runConnectAlias(name)
+}
+
+// loadHost reads the config and returns the host with the given alias
+func loadHost(alias string) (SSHHost, error) {
+	if alias == "" {
+		return SSHHost{}, fmt.Errorf("no host given, use --host <alias>")
+	}
+	config, err := loadConfig()
+	if err != nil {
+		return SSHHost{}, fmt.Errorf("failed to load config: %w", err)
+	}
+	for _, h := range config.Hosts {
+		if h.Host == alias {
+			return h, nil
+		}
+	}
+	return SSHHost{}, fmt.Errorf("no host with alias %q", alias)
+}
+
+// confirm asks a yes/no question on the terminal, anything but y is a no
+func confirm(question string) bool {
+	fmt.Print(question + " [y/N] ")
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	return strings.EqualFold(strings.TrimSpace(answer), "y")
+}
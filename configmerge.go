@@ -0,0 +1,277 @@
+package main
+
+import (
+	"cmp"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// MergeStrategy decides which of two hosts, profiles or templates with the
+// same name MergeConfigs keeps
+type MergeStrategy string
+
+const (
+	MergeFirst MergeStrategy = "first"
+	MergeLast  MergeStrategy = "last"
+	// MergeError fails the merge instead of choosing
+	MergeError MergeStrategy = "error"
+)
+
+// MergeConflict is a field two configs give different values
+type MergeConflict struct {
+	// e.g. "hosts.web1.hostname" or "settings.ssh_path"
+	Field string
+	// indexes of the configs and their values, empty if unset
+	FileA, FileB int
+	A, B         string
+	// index of the config whose value was kept, -1 if the merge failed
+	Kept int
+}
+
+// fieldValue formats v for a conflict, empty if it's unset
+func fieldValue(v reflect.Value) string {
+	if v.IsZero() {
+		return ""
+	}
+	return formatField(v)
+}
+
+// tomlName is the config name of a struct field, empty for fields that
+// aren't written
+func tomlName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("toml"), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// fieldConflicts compares the fields of two structs of the same type
+func fieldConflicts(prefix string, a, b reflect.Value, fileA, fileB int) []MergeConflict {
+	var conflicts []MergeConflict
+	for i := range a.NumField() {
+		name := tomlName(a.Type().Field(i))
+		if name == "" || reflect.DeepEqual(a.Field(i).Interface(), b.Field(i).Interface()) {
+			continue
+		}
+		conflicts = append(conflicts, MergeConflict{
+			Field: prefix + name,
+			FileA: fileA, FileB: fileB,
+			A: fieldValue(a.Field(i)), B: fieldValue(b.Field(i)),
+		})
+	}
+	return conflicts
+}
+
+// mergeFields sets the fields of dst that are set in src, the config with
+// index file. from remembers which config each field came from.
+func mergeFields(prefix string, dst, src reflect.Value, from map[string]int, file int) []MergeConflict {
+	var conflicts []MergeConflict
+	for i := range src.NumField() {
+		name := tomlName(src.Type().Field(i))
+		if name == "" || src.Field(i).IsZero() {
+			continue
+		}
+		field := prefix + name
+		if d := dst.Field(i); !d.IsZero() && !reflect.DeepEqual(d.Interface(), src.Field(i).Interface()) {
+			conflicts = append(conflicts, MergeConflict{
+				Field: field,
+				FileA: from[field], FileB: file,
+				A: fieldValue(d), B: fieldValue(src.Field(i)),
+				Kept: file,
+			})
+		}
+		dst.Field(i).Set(src.Field(i))
+		from[field] = file
+	}
+	return conflicts
+}
+
+// resolve decides between an earlier and a later entry of the same name
+// that differ in conflicts, and reports whether the later one wins
+func resolve(conflicts []MergeConflict, strategy MergeStrategy, earlier, later int) bool {
+	kept := earlier
+	switch strategy {
+	case MergeLast:
+		kept = later
+	case MergeError:
+		kept = -1
+	}
+	for i := range conflicts {
+		conflicts[i].Kept = kept
+	}
+	return strategy == MergeLast
+}
+
+// mergeNamed adds the profiles or templates of src to dst
+func mergeNamed[T any](prefix string, dst, src map[string]T, from map[string]int, file int, strategy MergeStrategy) ([]MergeConflict, []string) {
+	var conflicts []MergeConflict
+	var clashes []string
+	for _, name := range slices.Sorted(maps.Keys(src)) {
+		existing, ok := dst[name]
+		if !ok {
+			dst[name] = src[name]
+			from[name] = file
+			continue
+		}
+		cs := fieldConflicts(prefix+name+".", reflect.ValueOf(existing), reflect.ValueOf(src[name]), from[name], file)
+		if len(cs) == 0 {
+			continue
+		}
+		if resolve(cs, strategy, from[name], file) {
+			dst[name] = src[name]
+			from[name] = file
+		}
+		conflicts = append(conflicts, cs...)
+		clashes = append(clashes, prefix+name)
+	}
+	return conflicts, clashes
+}
+
+// MergeConfigs combines configs into one. Hosts, profiles and templates are
+// matched by name, strategy decides between two that differ, identical ones
+// are kept once. The [settings] and [deploy] tables are merged per field,
+// a field set in a later config wins. Every field that differs is returned
+// as a conflict, with strategy MergeError along with an error and no config.
+func MergeConfigs(configs []*Config, strategy MergeStrategy) (*Config, []MergeConflict, error) {
+	if !slices.Contains([]MergeStrategy{MergeFirst, MergeLast, MergeError}, strategy) {
+		return nil, nil, fmt.Errorf("unknown merge strategy %q", strategy)
+	}
+	merged := &Config{Profiles: make(map[string]Profile), Templates: make(map[string]HostTemplate)}
+	fieldFrom := make(map[string]int)
+	profileFrom := make(map[string]int)
+	templateFrom := make(map[string]int)
+	hostFrom := make(map[string]int)
+
+	var conflicts []MergeConflict
+	var clashes []string
+	for file, config := range configs {
+		conflicts = append(conflicts, mergeFields("settings.", reflect.ValueOf(&merged.Settings).Elem(), reflect.ValueOf(config.Settings), fieldFrom, file)...)
+		conflicts = append(conflicts, mergeFields("deploy.", reflect.ValueOf(&merged.Deploy).Elem(), reflect.ValueOf(config.Deploy), fieldFrom, file)...)
+
+		cs, names := mergeNamed("profiles.", merged.Profiles, config.Profiles, profileFrom, file, strategy)
+		conflicts, clashes = append(conflicts, cs...), append(clashes, names...)
+		cs, names = mergeNamed("templates.", merged.Templates, config.Templates, templateFrom, file, strategy)
+		conflicts, clashes = append(conflicts, cs...), append(clashes, names...)
+
+		for _, h := range config.Hosts {
+			i := slices.IndexFunc(merged.Hosts, func(e SSHHost) bool { return e.Host == h.Host })
+			if i < 0 {
+				merged.Hosts = append(merged.Hosts, h)
+				hostFrom[h.Host] = file
+				continue
+			}
+			cs := fieldConflicts("hosts."+h.Host+".", reflect.ValueOf(merged.Hosts[i]), reflect.ValueOf(h), hostFrom[h.Host], file)
+			if len(cs) == 0 {
+				continue
+			}
+			// the host keeps its place in the list
+			if resolve(cs, strategy, hostFrom[h.Host], file) {
+				merged.Hosts[i] = h
+				hostFrom[h.Host] = file
+			}
+			conflicts = append(conflicts, cs...)
+			clashes = append(clashes, "hosts."+h.Host)
+		}
+	}
+
+	if strategy == MergeError && len(clashes) > 0 {
+		return nil, conflicts, fmt.Errorf("%d entries differ between the files: %s", len(clashes), bulkSample(clashes))
+	}
+	if len(merged.Profiles) == 0 {
+		merged.Profiles = nil
+	}
+	if len(merged.Templates) == 0 {
+		merged.Templates = nil
+	}
+	return merged, conflicts, nil
+}
+
+func runConfig(args []string) int {
+	if len(args) == 0 || args[0] != "merge" {
+		fmt.Fprintln(os.Stderr, "usage: quickssh config merge --files a.toml,b.toml [--strategy first|last|error] [--output file [--yes]]")
+		return 2
+	}
+	return runConfigMerge(args[1:])
+}
+
+func runConfigMerge(args []string) int {
+	fs := flag.NewFlagSet("config merge", flag.ExitOnError)
+	fileList := fs.String("files", "", "comma separated config files to merge, later ones win per field of the settings")
+	strategy := fs.String("strategy", string(MergeLast), "which of two differing hosts with the same alias to keep: first, last, or error to stop")
+	output := fs.String("output", "", "write the merged config to this file instead of stdout")
+	yes := fs.Bool("yes", false, "with --output: don't ask before writing the file")
+	fs.Parse(args)
+
+	var paths []string
+	for _, path := range strings.Split(*fileList, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	valid := slices.Contains([]MergeStrategy{MergeFirst, MergeLast, MergeError}, MergeStrategy(*strategy))
+	if len(paths) < 2 || fs.NArg() > 0 || !valid {
+		fmt.Fprintln(os.Stderr, "usage: quickssh config merge --files a.toml,b.toml [--strategy first|last|error] [--output file [--yes]]")
+		return 2
+	}
+
+	return mergeFiles(paths, MergeStrategy(*strategy), *output, *yes, "config merge into ")
+}
+
+// mergeFiles merges the configs at paths with MergeConfigs, prints the
+// conflicts and writes the result to output, or to stdout if it's empty.
+// operation prefixes the line in the operations log.
+func mergeFiles(paths []string, strategy MergeStrategy, output string, yes bool, operation string) int {
+	var configs []*Config
+	for _, path := range paths {
+		var config Config
+		if _, err := toml.DecodeFile(path, &config); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read %s: %v\n", path, err)
+			return 1
+		}
+		configs = append(configs, &config)
+	}
+
+	merged, conflicts, err := MergeConfigs(configs, strategy)
+	for _, c := range conflicts {
+		resolution := "stopping"
+		if c.Kept >= 0 {
+			resolution = "keeping " + paths[c.Kept]
+		}
+		fmt.Fprintf(os.Stderr, "%s: %s in %s, %s in %s, %s\n", c.Field, cmp.Or(c.A, "unset"), paths[c.FileA], cmp.Or(c.B, "unset"), paths[c.FileB], resolution)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if output == "" {
+		if err := toml.NewEncoder(os.Stdout).Encode(merged); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return 0
+	}
+	aliases := hostAliases(merged.Hosts)
+	if !yes && !confirm(fmt.Sprintf("Write %d merged hosts (%s) to %s?", len(aliases), bulkSample(aliases), output)) {
+		return 1
+	}
+	if err := writeConfigFile(output, merged); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write merged config:", err)
+		return 1
+	}
+	fmt.Printf("Wrote %d hosts to %s\n", len(merged.Hosts), output)
+	if config, err := loadConfig(); err == nil {
+		if err := logOperation(config.Settings, operation+output, aliases); err != nil {
+			fmt.Fprintln(os.Stderr, "failed to write the operations log:", err)
+		}
+	}
+	return 0
+}
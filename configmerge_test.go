@@ -0,0 +1,45 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMergeConfigsStrategy(t *testing.T) {
+	configs := func() []*Config {
+		return []*Config{
+			{Hosts: []SSHHost{{Host: "web1", HostName: "10.0.0.1"}, {Host: "db1", HostName: "10.0.0.2"}}},
+			{Hosts: []SSHHost{{Host: "web1", HostName: "10.0.0.9"}, {Host: "db1", HostName: "10.0.0.2"}}},
+		}
+	}
+	tests := []struct {
+		strategy MergeStrategy
+		want     string
+		kept     int
+	}{
+		{MergeFirst, "10.0.0.1", 0},
+		{MergeLast, "10.0.0.9", 1},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.strategy), func(t *testing.T) {
+			merged, conflicts, err := MergeConfigs(configs(), tt.strategy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := aliasesOf(merged.Hosts); !slices.Equal(got, []string{"web1", "db1"}) {
+				t.Fatalf("hosts %v, want [web1 db1]", got)
+			}
+			if merged.Hosts[0].HostName != tt.want {
+				t.Errorf("web1 has hostname %s, want %s", merged.Hosts[0].HostName, tt.want)
+			}
+			// the identical db1 isn't a conflict
+			if len(conflicts) != 1 || conflicts[0].Field != "hosts.web1.hostname" || conflicts[0].Kept != tt.kept {
+				t.Errorf("conflicts %+v, want hosts.web1.hostname keeping %d", conflicts, tt.kept)
+			}
+		})
+	}
+
+	if _, _, err := MergeConfigs(configs(), MergeError); err == nil {
+		t.Error("MergeError merged differing hosts, want an error")
+	}
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Importer reads the hosts of another ssh manager
+type Importer interface {
+	Import(r io.Reader) ([]SSHHost, error)
+}
+
+// a source of convert: its importer and where its config usually lives
+type convertSource struct {
+	importer Importer
+	path     func() string
+}
+
+var convertSources = map[string]convertSource{
+	"storm":     {jsonImporter{}, func() string { return expandPath("~/.storm/profiles.json") }},
+	"sshhub":    {jsonImporter{}, func() string { return expandPath("~/.sshhub") }},
+	"securecrt": {secureCRTImporter{}, secureCRTSessionDir},
+}
+
+// jsonHost accepts the field names storm and sshhub use for a host
+type jsonHost struct {
+	Name         string            `json:"name"`
+	Alias        string            `json:"alias"`
+	Host         string            `json:"host"`
+	HostName     string            `json:"hostname"`
+	User         string            `json:"user"`
+	Port         json.RawMessage   `json:"port"`
+	IDFile       string            `json:"id_file"`
+	IdentityFile string            `json:"identity_file"`
+	Key          string            `json:"key"`
+	Tags         []string          `json:"tags"`
+	Options      map[string]string `json:"options"`
+}
+
+func (j jsonHost) toSSHHost() (SSHHost, error) {
+	h := SSHHost{
+		Host:         firstNonEmpty(j.Name, j.Alias, j.Host),
+		HostName:     firstNonEmpty(j.HostName, j.Host),
+		User:         j.User,
+		IdentityFile: firstNonEmpty(j.IDFile, j.IdentityFile, j.Key),
+		Tags:         j.Tags,
+		Options:      j.Options,
+	}
+	if len(j.Port) > 0 {
+		// written as a number or a string depending on the version
+		port := strings.Trim(string(j.Port), `"`)
+		n, err := strconv.Atoi(port)
+		if err != nil && port != "" && port != "null" {
+			return SSHHost{}, fmt.Errorf("invalid port %s of %s", j.Port, h.Host)
+		}
+		if n != 22 {
+			h.Port = n
+		}
+	}
+	if h.Host == "" {
+		return SSHHost{}, errors.New("host without name")
+	}
+	return h, nil
+}
+
+// jsonImporter reads the JSON configs of storm and sshhub, a list of hosts
+// either at the top level or under "hosts" or "profiles"
+type jsonImporter struct{}
+
+func (jsonImporter) Import(r io.Reader) ([]SSHHost, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []jsonHost
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		err = json.Unmarshal(data, &list)
+	} else {
+		var wrapped struct {
+			Hosts    []jsonHost `json:"hosts"`
+			Profiles []jsonHost `json:"profiles"`
+		}
+		err = json.Unmarshal(data, &wrapped)
+		list = append(wrapped.Hosts, wrapped.Profiles...)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	hosts := make([]SSHHost, 0, len(list))
+	for _, j := range list {
+		h, err := j.toSSHHost()
+		if err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, h)
+	}
+	return hosts, nil
+}
+
+// secureCRTImporter reads one SecureCRT session file. Sessions are named
+// after their file, so the returned host has no alias yet.
+type secureCRTImporter struct{}
+
+func (secureCRTImporter) Import(r io.Reader) ([]SSHHost, error) {
+	var h SSHHost
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		// lines look like S:"Hostname"=web1 or D:"[SSH2] Port"=00000016
+		line := strings.TrimPrefix(strings.TrimSpace(scanner.Text()), "\ufeff")
+		kind, rest, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		name, value, ok := strings.Cut(rest, "=")
+		if !ok {
+			continue
+		}
+		switch name = strings.Trim(name, `"`); {
+		case kind == "S" && name == "Hostname":
+			h.HostName = value
+		case kind == "S" && name == "Username":
+			h.User = value
+		case kind == "S" && name == "Firewall Name" && value != "None":
+			h.ProxyJump = value
+		case kind == "S" && name == "Identity Filename V2":
+			// the path may be followed by "::" and key flags
+			path, _, _ := strings.Cut(value, "::")
+			h.IdentityFile = path
+		case kind == "D" && name == "[SSH2] Port":
+			// dwords are hex
+			if port, err := strconv.ParseInt(value, 16, 32); err == nil && port != 22 {
+				h.Port = int(port)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if h.HostName == "" {
+		return nil, nil
+	}
+	return []SSHHost{h}, nil
+}
+
+func secureCRTSessionDir() string {
+	if appData := os.Getenv("APPDATA"); appData != "" {
+		return filepath.Join(appData, "VanDyke", "Config", "Sessions")
+	}
+	return expandPath("~/.vandyke/SecureCRT/Config/Sessions")
+}
+
+// importSecureCRT reads a session file or every session below a directory,
+// naming each host after its session path
+func importSecureCRT(path string) ([]SSHHost, error) {
+	var hosts []SSHHost
+	err := filepath.WalkDir(path, func(file string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(file) != ".ini" {
+			return err
+		}
+		base := filepath.Base(file)
+		if base == "__FolderData__.ini" || base == "Default.ini" {
+			return nil
+		}
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		imported, err := secureCRTImporter{}.Import(f)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+
+		rel, _ := filepath.Rel(path, file)
+		if rel == "." {
+			rel = base
+		}
+		for _, h := range imported {
+			h.Host = slugify(strings.TrimSuffix(filepath.ToSlash(rel), ".ini"))
+			hosts = append(hosts, h)
+		}
+		return nil
+	})
+	return hosts, err
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func runConvert(args []string) int {
+	fs := flag.NewFlagSet("convert", flag.ExitOnError)
+	from := fs.String("from", "", "ssh manager to convert from: storm, sshhub, securecrt")
+	path := fs.String("path", "", "config file or session directory, defaults to the manager's usual location")
+	dryRun := fs.Bool("dry-run", false, "only show the hosts that would be added")
+	fs.Parse(args)
+
+	source, ok := convertSources[*from]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown source %q\n", *from)
+		return 2
+	}
+	if *path == "" {
+		*path = source.path()
+	}
+
+	var imported []SSHHost
+	var err error
+	if *from == "securecrt" {
+		imported, err = importSecureCRT(*path)
+	} else {
+		var f *os.File
+		if f, err = os.Open(*path); err == nil {
+			imported, err = source.importer.Import(f)
+			f.Close()
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "convert failed:", err)
+		return 1
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load config:", err)
+		return 1
+	}
+	var skipped []string
+	config.Hosts, skipped = mergeHosts(config.Hosts, imported)
+
+	for _, h := range imported {
+		if !slices.Contains(skipped, h.Host) {
+			fmt.Printf("Add %s (%s)\n", h.Host, h.destination())
+		}
+	}
+	for _, alias := range skipped {
+		fmt.Printf("Skipped %s, the alias already exists\n", alias)
+	}
+	if *dryRun {
+		return 0
+	}
+	if err := saveConfig(config); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to save config:", err)
+		return 1
+	}
+	fmt.Printf("Imported %d hosts\n", len(imported)-len(skipped))
+	return 0
+}
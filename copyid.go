@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/x/term"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// public keys copy-id tries when the host has no identity file, like
+// ssh-copy-id does
+var defaultPublicKeys = []string{"~/.ssh/id_ed25519.pub", "~/.ssh/id_ecdsa.pub", "~/.ssh/id_rsa.pub"}
+
+// appends the key on stdin to authorized_keys unless it is there already,
+// creating ~/.ssh with the permissions sshd insists on
+const authorizeKeyScript = `umask 077 && mkdir -p ~/.ssh && touch ~/.ssh/authorized_keys && ` +
+	`chmod 700 ~/.ssh && chmod 600 ~/.ssh/authorized_keys && key=$(cat) && ` +
+	`if grep -qxF "$key" ~/.ssh/authorized_keys; then exit 0; fi && ` +
+	`if [ -s ~/.ssh/authorized_keys ] && [ -n "$(tail -c1 ~/.ssh/authorized_keys)" ]; then echo >> ~/.ssh/authorized_keys; fi && ` +
+	`printf '%s\n' "$key" >> ~/.ssh/authorized_keys`
+
+// CopyPublicKey logs into h with password and appends the public key at
+// pubKeyPath to the remote ~/.ssh/authorized_keys, like ssh-copy-id
+func CopyPublicKey(h SSHHost, pubKeyPath, password string) error {
+	data, err := os.ReadFile(expandPath(pubKeyPath))
+	if err != nil {
+		return err
+	}
+	if _, _, _, _, err := ssh.ParseAuthorizedKey(data); err != nil {
+		return fmt.Errorf("%s is not a public key: %w", pubKeyPath, err)
+	}
+
+	hostKeyCallback, err := knownhosts.New(knownHostsPath())
+	if err != nil {
+		return fmt.Errorf("failed to read known_hosts: %w", err)
+	}
+	config := &ssh.ClientConfig{
+		User: cmp.Or(h.User, os.Getenv("USER")),
+		Auth: []ssh.AuthMethod{
+			ssh.Password(password),
+			// servers that only allow keyboard-interactive ask for the
+			// password that way
+			ssh.KeyboardInteractive(func(_, _ string, questions []string, _ []bool) ([]string, error) {
+				answers := make([]string, len(questions))
+				for i := range answers {
+					answers[i] = password
+				}
+				return answers, nil
+			}),
+		},
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         10 * time.Second,
+	}
+
+	client, err := ssh.Dial("tcp", net.JoinHostPort(h.address(), strconv.Itoa(h.port())), config)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	session, err := client.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	var stderr bytes.Buffer
+	session.Stdin = bytes.NewReader(bytes.TrimSpace(data))
+	session.Stderr = &stderr
+	if err := session.Run(authorizeKeyScript); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%w: %s", err, lastLine(msg))
+		}
+		return err
+	}
+	return nil
+}
+
+// publicKeyFor picks the key copy-id sends: the host's identity file, or the
+// first of the usual keys that exists
+func publicKeyFor(h SSHHost) (string, error) {
+	if h.IdentityFile != "" {
+		return h.IdentityFile + ".pub", nil
+	}
+	for _, path := range defaultPublicKeys {
+		if _, err := os.Stat(expandPath(path)); err == nil {
+			return path, nil
+		}
+	}
+	return "", errors.New("no public key found in ~/.ssh, pass one with --key")
+}
+
+func runCopyID(args []string) int {
+	fs := flag.NewFlagSet("copy-id", flag.ExitOnError)
+	alias := fs.String("host", "", "alias of the host to install the key on")
+	keyPath := fs.String("key", "", "public key to install (default: the host's identity_file + .pub, or ~/.ssh/id_*.pub)")
+	fs.Parse(args)
+
+	if *alias == "" {
+		fmt.Fprintln(os.Stderr, "usage: quickssh copy-id --host <alias> [--key file.pub]")
+		return 2
+	}
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load config:", err)
+		return 1
+	}
+	i := slices.IndexFunc(config.Hosts, func(h SSHHost) bool { return h.Host == *alias })
+	if i < 0 {
+		fmt.Fprintf(os.Stderr, "no host with alias %q\n", *alias)
+		return 1
+	}
+	h := config.Hosts[i]
+	if h.ProxyJump != "" {
+		fmt.Fprintln(os.Stderr, "copy-id doesn't support hosts with proxy_jump")
+		return 1
+	}
+
+	pubKey := *keyPath
+	if pubKey == "" {
+		if pubKey, err = publicKeyFor(h); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Password for %s@%s: ", cmp.Or(h.User, os.Getenv("USER")), h.address())
+	password, err := term.ReadPassword(os.Stdin.Fd())
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to read the password:", err)
+		return 1
+	}
+
+	if err := CopyPublicKey(h, pubKey, string(password)); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to copy %s to %s: %v\n", pubKey, h.Host, err)
+		return 1
+	}
+	fmt.Printf("Installed %s on %s\n", pubKey, h.Host)
+
+	private := strings.TrimSuffix(pubKey, ".pub")
+	if _, err := os.Stat(expandPath(private)); err != nil || private == h.IdentityFile {
+		return 0
+	}
+	config.Hosts[i].IdentityFile = private
+	if err := saveConfig(config); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to save config:", err)
+		return 1
+	}
+	fmt.Printf("Set identity_file of %s to %s\n", h.Host, private)
+	return 0
+}
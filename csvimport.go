@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+)
+
+// csvFields are the config fields a CSV column can fill, with the other
+// names a column of them often has
+var csvFields = []struct {
+	name     string
+	synonyms []string
+}{
+	{"host", []string{"alias", "name", "server", "servername", "hostalias"}},
+	{"hostname", []string{"ip", "ip_address", "ipaddress", "address", "addr", "fqdn", "dns"}},
+	{"user", []string{"login", "username", "user_name", "account"}},
+	{"port", []string{"ssh_port", "sshport"}},
+	{"identity_file", []string{"key", "keyfile", "key_file", "identity", "private_key"}},
+	{"proxy_jump", []string{"jump", "jumphost", "jump_host", "bastion"}},
+	{"tags", []string{"tag", "labels", "label"}},
+	{"group", []string{"folder", "category"}},
+	{"description", []string{"desc", "comment", "comments"}},
+	{"notes", []string{"note"}},
+}
+
+func csvFieldNames() []string {
+	names := make([]string, len(csvFields))
+	for i, f := range csvFields {
+		names[i] = f.name
+	}
+	return names
+}
+
+// normalizeHeader makes "IP Address" and "ip-address" read as ip_address
+func normalizeHeader(header string) string {
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(strings.TrimSpace(header))
+}
+
+// GuessFieldMapping maps CSV headers to the config fields they most likely
+// hold, by name or a common synonym like "ip" for hostname. Headers that
+// don't match, or name a field an earlier column already took, are left out.
+func GuessFieldMapping(headers []string) map[string]string {
+	return fieldMapping(headers, true)
+}
+
+// fieldMapping maps headers named like a config field, and with guess also
+// the ones named like one of its synonyms
+func fieldMapping(headers []string, guess bool) map[string]string {
+	mapping := make(map[string]string)
+	taken := make(map[string]bool)
+	for _, header := range headers {
+		name := normalizeHeader(header)
+		for _, f := range csvFields {
+			if taken[f.name] {
+				continue
+			}
+			match := strings.EqualFold(name, f.name)
+			if guess && !match {
+				match = slices.ContainsFunc(f.synonyms, func(s string) bool { return strings.EqualFold(name, s) })
+			}
+			if match {
+				mapping[header] = f.name
+				taken[f.name] = true
+				break
+			}
+		}
+	}
+	return mapping
+}
+
+// askFieldMapping asks on the terminal which field each unmapped column
+// holds, an empty answer skips the column
+func askFieldMapping(unmapped []string, mapping map[string]string) {
+	in := bufio.NewReader(os.Stdin)
+	fmt.Println("Fields:", strings.Join(csvFieldNames(), ", "))
+	for _, header := range unmapped {
+		for {
+			fmt.Printf("Field for column %q (empty to skip): ", header)
+			answer, err := in.ReadString('\n')
+			answer = strings.TrimSpace(answer)
+			if answer == "" {
+				if err != nil {
+					return
+				}
+				break
+			}
+			if !slices.Contains(csvFieldNames(), answer) {
+				fmt.Printf("%q is not one of the fields\n", answer)
+				continue
+			}
+			if slices.Contains(mappedFields(mapping), answer) {
+				fmt.Printf("%s is already filled from another column\n", answer)
+				continue
+			}
+			mapping[header] = answer
+			break
+		}
+	}
+}
+
+func mappedFields(mapping map[string]string) []string {
+	fields := make([]string, 0, len(mapping))
+	for _, f := range mapping {
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// setCSVField sets the field of h named like in the config file
+func setCSVField(h *SSHHost, field, value string) error {
+	switch field {
+	case "host":
+		h.Host = value
+	case "hostname":
+		h.HostName = value
+	case "user":
+		h.User = value
+	case "port":
+		port, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("port %q is not a number", value)
+		}
+		h.Port = port
+	case "identity_file":
+		h.IdentityFile = value
+	case "proxy_jump":
+		h.ProxyJump = value
+	case "tags":
+		h.Tags = strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ';' || r == ' ' })
+	case "group":
+		h.Group = value
+	case "description":
+		h.Desc = value
+	case "notes":
+		h.Notes = value
+	}
+	return nil
+}
+
+// parseCSVHosts reads one host per row after the header row, filling the
+// fields given by mapping from header to field. A row without an alias uses
+// its hostname, rows with neither are skipped.
+func parseCSVHosts(records [][]string, mapping map[string]string) ([]SSHHost, error) {
+	if len(records) == 0 {
+		return nil, nil
+	}
+	headers := records[0]
+	var hosts []SSHHost
+	for i, record := range records[1:] {
+		var h SSHHost
+		// cells past the last header have no field
+		for col, value := range record[:min(len(record), len(headers))] {
+			field, ok := mapping[headers[col]]
+			value = strings.TrimSpace(value)
+			if !ok || value == "" {
+				continue
+			}
+			if err := setCSVField(&h, field, value); err != nil {
+				// the header is row 1
+				return nil, fmt.Errorf("row %d: %w", i+2, err)
+			}
+		}
+		if h.Host == "" {
+			h.Host = h.HostName
+		}
+		if h.Host == "" {
+			continue
+		}
+		hosts = append(hosts, h)
+	}
+	return hosts, nil
+}
+
+// importCSV reads the hosts of a CSV file with a header row. Columns are
+// matched to fields by name, with guess by synonyms as well. Columns left
+// over are reported and, on a terminal, asked about.
+func importCSV(path string, guess bool) ([]SSHHost, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%s is empty", path)
+	}
+	headers := records[0]
+	mapping := fieldMapping(headers, guess)
+	var unmapped []string
+	for _, header := range headers {
+		if _, ok := mapping[header]; !ok {
+			unmapped = append(unmapped, header)
+			fmt.Fprintf(os.Stderr, "warning: column %q doesn't match a field\n", header)
+		}
+	}
+	if len(unmapped) > 0 && term.IsTerminal(os.Stdin.Fd()) {
+		askFieldMapping(unmapped, mapping)
+	}
+	if !slices.Contains(mappedFields(mapping), "host") && !slices.Contains(mappedFields(mapping), "hostname") {
+		return nil, fmt.Errorf("no column for host or hostname")
+	}
+	return parseCSVHosts(records, mapping)
+}
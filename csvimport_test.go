@@ -0,0 +1,47 @@
+package main
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestGuessFieldMapping(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers []string
+		want    map[string]string
+	}{
+		{
+			name:    "field names",
+			headers: []string{"host", "hostname", "user", "port", "tags"},
+			want:    map[string]string{"host": "host", "hostname": "hostname", "user": "user", "port": "port", "tags": "tags"},
+		},
+		{
+			name:    "synonyms in any case",
+			headers: []string{"Server", "IP Address", "Login", "SSH-Port", "Labels", "Comment"},
+			want: map[string]string{
+				"Server": "host", "IP Address": "hostname", "Login": "user",
+				"SSH-Port": "port", "Labels": "tags", "Comment": "description",
+			},
+		},
+		{
+			name:    "unknown columns left out",
+			headers: []string{"Name", "Owner", "Cost Center", "FQDN"},
+			want:    map[string]string{"Name": "host", "FQDN": "hostname"},
+		},
+		{
+			// the second column naming hostname is left to be asked about
+			name:    "field taken by an earlier column",
+			headers: []string{"ip", "hostname", "alias", "host"},
+			want:    map[string]string{"ip": "hostname", "alias": "host"},
+		},
+		{name: "no headers", headers: nil, want: map[string]string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GuessFieldMapping(tt.headers); !maps.Equal(got, tt.want) {
+				t.Errorf("GuessFieldMapping(%q) = %v, want %v", tt.headers, got, tt.want)
+			}
+		})
+	}
+}
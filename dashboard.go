@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+)
+
+// used when stale_days is not set
+const defaultStaleDays = 30
+
+var dashboardHeaderStyle = lipgloss.NewStyle().Bold(true).Padding(0, 1)
+
+// dashboardView summarizes reachability and lists hosts that haven't been
+// connected to within the staleness threshold
+func (m model) dashboardView() string {
+	staleDays := m.settings.StaleDays
+	if staleDays <= 0 {
+		staleDays = defaultStaleDays
+	}
+
+	counts := map[reachState]int{}
+	stale := table.New().
+		Border(lipgloss.RoundedBorder()).
+		Headers("Host", "HostName", "Last connected").
+		StyleFunc(func(row, col int) lipgloss.Style {
+			if row == table.HeaderRow {
+				return dashboardHeaderStyle
+			}
+			return lipgloss.NewStyle().Padding(0, 1)
+		})
+
+	staleCount := 0
+	for _, h := range m.hosts {
+		counts[m.reach[h.Host]]++
+
+		last, ok := m.lastSeen[h.Host]
+		switch {
+		case !ok:
+			stale.Row(h.Host, h.address(), "never")
+		case time.Since(last) > time.Duration(staleDays)*24*time.Hour:
+			stale.Row(h.Host, h.address(), fmt.Sprintf("%s (%d days ago)", last.Format(time.DateOnly), int(time.Since(last).Hours()/24)))
+		default:
+			continue
+		}
+		staleCount++
+	}
+
+	summary := table.New().
+		Border(lipgloss.RoundedBorder()).
+		Headers("Reachable", "Unreachable", "Unknown", "Total").
+		Row(
+			reachableStyle.Render(fmt.Sprint(counts[reachable])),
+			unreachableStyle.Render(fmt.Sprint(counts[unreachable])),
+			fmt.Sprint(counts[reachUnknown]),
+			fmt.Sprint(len(m.hosts)),
+		).
+		StyleFunc(func(row, col int) lipgloss.Style {
+			if row == table.HeaderRow {
+				return dashboardHeaderStyle
+			}
+			return lipgloss.NewStyle().Padding(0, 1).Align(lipgloss.Center)
+		})
+
+	sections := []string{
+		titleStyle.Render("Health"),
+		summary.Render(),
+		fmt.Sprintf("Stale hosts, not connected to in %d days: %d", staleDays, staleCount),
+	}
+	if staleCount > 0 {
+		sections = append(sections, stale.Render())
+	}
+	sections = append(sections, checkFixStyle.Render("esc: back"))
+	return lipgloss.JoinVertical(lipgloss.Left, sections...)
+}
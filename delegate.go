@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// what the second line of a list item shows, cycled with D
+const (
+	subtitleDescription   = "description"
+	subtitleAddress       = "address"
+	subtitleTags          = "tags"
+	subtitleLastConnected = "last_connected"
+)
+
+var subtitleModes = []string{subtitleDescription, subtitleAddress, subtitleTags, subtitleLastConnected}
+
+var numberStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+
+// hostDelegate renders the list items. It puts the quick connect number in
+// front of each item and picks what the second line shows.
+type hostDelegate struct {
+	list.DefaultDelegate
+	showNumbers bool
+	subtitle    string
+	lastSeen    map[string]time.Time
+}
+
+func newHostDelegate(settings Settings, lastSeen map[string]time.Time) hostDelegate {
+	return hostDelegate{
+		DefaultDelegate: list.NewDefaultDelegate(),
+		showNumbers:     settings.ShowNumbers,
+		subtitle:        settings.Subtitle,
+		lastSeen:        lastSeen,
+	}
+}
+
+// subtitledItem replaces the description of a host item, filtering still
+// uses the full filter value of the item
+type subtitledItem struct {
+	hostItem
+	subtitle string
+}
+
+func (i subtitledItem) Description() string { return i.subtitle }
+
+// subtitleOf returns the second line of h for the current mode
+func (d hostDelegate) subtitleOf(h SSHHost) string {
+	switch d.subtitle {
+	case subtitleAddress:
+		return strings.TrimPrefix(h.User+"@"+h.address(), "@")
+	case subtitleTags:
+		return strings.Join(h.Tags, ", ")
+	case subtitleLastConnected:
+		if last, ok := d.lastSeen[h.Host]; ok {
+			return "connected " + relativeTime(last, time.Now())
+		}
+		return neverStyle.Render("never connected")
+	}
+	return h.Description()
+}
+
+func (d hostDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	if i, ok := item.(hostItem); ok {
+		item = subtitledItem{hostItem: i, subtitle: d.subtitleOf(i.SSHHost)}
+	}
+	if !d.showNumbers {
+		d.DefaultDelegate.Render(w, m, index, item)
+		return
+	}
+
+	// m is a copy, narrowing it makes room for the numbers
+	width := len(strconv.Itoa(len(m.VisibleItems())))
+	m.SetWidth(m.Width() - width - 1)
+	var b strings.Builder
+	d.DefaultDelegate.Render(&b, m, index, item)
+
+	lines := strings.Split(b.String(), "\n")
+	for i, line := range lines {
+		label := ""
+		if i == 0 {
+			label = strconv.Itoa(index + 1)
+		}
+		lines[i] = numberStyle.Render(fmt.Sprintf("%*s ", width, label)) + line
+	}
+	fmt.Fprint(w, strings.Join(lines, "\n"))
+}
+
+// refreshDelegate applies changed display settings to the list
+func (m *model) refreshDelegate() {
+	m.list.SetDelegate(newHostDelegate(m.settings, m.lastSeen))
+}
+
+// cycleSubtitle switches the second line of the list items to the next mode
+func (m *model) cycleSubtitle() string {
+	next := (slices.Index(subtitleModes, m.settings.Subtitle) + 1) % len(subtitleModes)
+	if m.settings.Subtitle == "" {
+		// empty means description, the first mode
+		next = 1
+	}
+	m.settings.Subtitle = subtitleModes[next]
+	m.refreshDelegate()
+	return strings.ReplaceAll(m.settings.Subtitle, "_", " ")
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// DeployConfig is a [deploy] table, globally or in a host. The hooks run
+// locally, activate and rollback on the host.
+type DeployConfig struct {
+	LocalDir   string `toml:"local_dir,omitempty" json:"local_dir,omitempty"`
+	RemoteDir  string `toml:"remote_dir,omitempty" json:"remote_dir,omitempty"`
+	PreDeploy  string `toml:"pre_deploy,omitempty" json:"pre_deploy,omitempty"`
+	Activate   string `toml:"activate,omitempty" json:"activate,omitempty"`
+	PostDeploy string `toml:"post_deploy,omitempty" json:"post_deploy,omitempty"`
+	Rollback   string `toml:"rollback,omitempty" json:"rollback,omitempty"`
+}
+
+// merge returns d with the fields set in override replaced
+func (d DeployConfig) merge(override *DeployConfig) DeployConfig {
+	if override == nil {
+		return d
+	}
+	return DeployConfig{
+		LocalDir:   cmp.Or(override.LocalDir, d.LocalDir),
+		RemoteDir:  cmp.Or(override.RemoteDir, d.RemoteDir),
+		PreDeploy:  cmp.Or(override.PreDeploy, d.PreDeploy),
+		Activate:   cmp.Or(override.Activate, d.Activate),
+		PostDeploy: cmp.Or(override.PostDeploy, d.PostDeploy),
+		Rollback:   cmp.Or(override.Rollback, d.Rollback),
+	}
+}
+
+// DeployOptions is a deploy of LocalDir to RemoteDir with the steps of
+// DeployConfig, progress goes to Stdout and Stderr
+type DeployOptions struct {
+	DeployConfig
+	Exclude []string
+	Stdout  io.Writer
+	Stderr  io.Writer
+}
+
+// DeployToHost copies opts.LocalDir to opts.RemoteDir on h and activates it.
+// The files are synced into a copy of the current directory next to it,
+// which then replaces it, so a failed sync leaves the live directory
+// untouched. If activating or a later step fails, the previous directory is
+// moved back and the rollback command runs.
+func DeployToHost(h SSHHost, opts DeployOptions) error {
+	remote := strings.TrimSuffix(opts.RemoteDir, "/")
+	if opts.LocalDir == "" || remote == "" {
+		return errors.New("local and remote directory are required")
+	}
+	next, previous := remote+".quickssh-next", remote+".quickssh-previous"
+	vars := hookVars(h, -1)
+	delete(vars, "exit_code")
+	vars["local_dir"], vars["remote_dir"] = opts.LocalDir, remote
+
+	step := func(name string) { fmt.Fprintf(opts.Stderr, "==> %s\n", name) }
+	local := func(command string) error {
+		cmd := hookCommand(context.Background(), command, vars)
+		cmd.Stdout, cmd.Stderr = opts.Stdout, opts.Stderr
+		return cmd.Run()
+	}
+	onHost := func(command string) error {
+		cmd := remoteCommand(h, command)
+		cmd.Stdout, cmd.Stderr = opts.Stdout, opts.Stderr
+		return cmd.Run()
+	}
+
+	if opts.PreDeploy != "" {
+		step("pre_deploy")
+		if err := local(opts.PreDeploy); err != nil {
+			return fmt.Errorf("pre_deploy failed: %w", err)
+		}
+	}
+
+	// start from the live files so rsync only sends what changed
+	step("syncing " + opts.LocalDir + " to " + next)
+	prepare := fmt.Sprintf("rm -rf -- %[1]s && mkdir -p -- %[1]s && if [ -d %[2]s ]; then cp -a -- %[2]s/. %[1]s/; fi",
+		shellQuote(next), shellQuote(remote))
+	err := onHost(prepare)
+	if err == nil {
+		cmd := BuildRsyncCommand(h, strings.TrimSuffix(opts.LocalDir, "/")+"/", next+"/",
+			RsyncOptions{Delete: true, Exclude: opts.Exclude})
+		cmd.Stdout, cmd.Stderr = opts.Stdout, opts.Stderr
+		err = cmd.Run()
+	}
+	if err != nil {
+		err = fmt.Errorf("sync failed: %w", err)
+		if cleanupErr := onHost("rm -rf -- " + shellQuote(next)); cleanupErr != nil {
+			err = errors.Join(err, fmt.Errorf("removing %s failed: %w", next, cleanupErr))
+		}
+		return err
+	}
+
+	step("activating " + remote)
+	swap := fmt.Sprintf("rm -rf -- %[1]s && if [ -e %[2]s ]; then mv -- %[2]s %[1]s; fi && mv -- %[3]s %[2]s",
+		shellQuote(previous), shellQuote(remote), shellQuote(next))
+	err = onHost(swap)
+	if err == nil && opts.Activate != "" {
+		if err = onHost("cd -- " + shellQuote(remote) + " && " + opts.Activate); err != nil {
+			err = fmt.Errorf("activate failed: %w", err)
+		}
+	} else if err != nil {
+		err = fmt.Errorf("replacing %s failed: %w", remote, err)
+	}
+	if err == nil && opts.PostDeploy != "" {
+		step("post_deploy")
+		if err = local(opts.PostDeploy); err != nil {
+			err = fmt.Errorf("post_deploy failed: %w", err)
+		}
+	}
+	if err != nil {
+		return errors.Join(err, rollbackDeploy(opts, step, onHost, remote, previous))
+	}
+	return nil
+}
+
+// rollbackDeploy moves the previous directory back in place and runs the
+// rollback command in it
+func rollbackDeploy(opts DeployOptions, step func(string), onHost func(string) error, remote, previous string) error {
+	step("rolling back")
+	restore := fmt.Sprintf("if [ -e %[1]s ]; then rm -rf -- %[2]s && mv -- %[1]s %[2]s; fi",
+		shellQuote(previous), shellQuote(remote))
+	if err := onHost(restore); err != nil {
+		return fmt.Errorf("restoring %s failed: %w", previous, err)
+	}
+	if opts.Rollback != "" {
+		if err := onHost("cd -- " + shellQuote(remote) + " && " + opts.Rollback); err != nil {
+			return fmt.Errorf("rollback failed: %w", err)
+		}
+	}
+	return nil
+}
+
+func runDeploy(args []string) int {
+	fs := flag.NewFlagSet("deploy", flag.ExitOnError)
+	alias := fs.String("host", "", "alias of the host to deploy to")
+	localDir := fs.String("local-dir", "", "directory to deploy, local_dir of [deploy] if not set")
+	remoteDir := fs.String("remote-dir", "", "directory on the host, remote_dir of [deploy] if not set")
+	var exclude stringsFlag
+	fs.Var(&exclude, "exclude", "pattern of files to skip, can be repeated")
+	fs.Parse(args)
+
+	if *alias == "" {
+		fmt.Fprintln(os.Stderr, "usage: quickssh deploy --host <alias> [--local-dir <dir>] [--remote-dir <dir>] [--exclude pattern]")
+		return 2
+	}
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load config:", err)
+		return 1
+	}
+	h, err := loadHost(*alias)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	opts := DeployOptions{
+		DeployConfig: config.Deploy.merge(h.Deploy),
+		Exclude:      exclude,
+		Stdout:       os.Stdout,
+		Stderr:       os.Stderr,
+	}
+	if *localDir != "" {
+		opts.LocalDir = *localDir
+	}
+	if *remoteDir != "" {
+		opts.RemoteDir = *remoteDir
+	}
+	if opts.LocalDir == "" || opts.RemoteDir == "" {
+		fmt.Fprintln(os.Stderr, "set --local-dir and --remote-dir or local_dir and remote_dir in [deploy]")
+		return 2
+	}
+	if info, err := os.Stat(opts.LocalDir); err != nil || !info.IsDir() {
+		fmt.Fprintln(os.Stderr, opts.LocalDir, "is not a directory")
+		return 1
+	}
+
+	if err := DeployToHost(h, opts); err != nil {
+		fmt.Fprintln(os.Stderr, "deploy failed:", err)
+		return 1
+	}
+	fmt.Fprintln(os.Stderr, "Deployed", opts.LocalDir, "to", h.Host+":"+opts.RemoteDir)
+	return 0
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// width of the host list, the detail panel takes the rest of the terminal
+const listWidth = 40
+
+var (
+	detailLabelStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+	notesTitleStyle  = lipgloss.NewStyle().Bold(true)
+)
+
+// renderDetailPanel lists the populated fields of h, cutting lines that don't
+// fit into width
+func renderDetailPanel(h SSHHost, width int) string {
+	var lines []string
+	field := func(label, value string) {
+		if value != "" {
+			lines = append(lines, detailLabelStyle.Render(label+": ")+value)
+		}
+	}
+
+	field("Host", h.Host)
+	field("HostName", h.HostName)
+	field("User", h.User)
+	if h.Port != 0 {
+		field("Port", strconv.Itoa(h.Port))
+	}
+	field("Group", h.Group)
+	field("Description", h.Desc)
+	field("Tags", strings.Join(h.Tags, ", "))
+	field("IdentityFile", h.IdentityFile)
+	field("ProxyJump", h.ProxyJump)
+	if h.ForwardAgent {
+		field("ForwardAgent", "yes")
+	}
+	if h.CertificateFile != "" {
+		field("Certificate", h.CertificateFile)
+		if expiry, err := certificateExpiry(h.CertificateFile); err != nil {
+			lines = append(lines, errorMessageStyle(err.Error()))
+		} else if !expiry.IsZero() && expiry.Before(time.Now()) {
+			lines = append(lines, errorMessageStyle("Certificate expired "+expiry.Format(time.DateTime)))
+		}
+	}
+	field("Auth", h.authLabel())
+	field("VaultSSHRole", h.VaultSSHRole)
+	if h.PasswordCommand != "" {
+		field("PasswordCommand", h.PasswordCommand)
+	}
+	if h.TunnelTarget != "" {
+		field("Tunnel", fmt.Sprintf("localhost:%d → %s", h.tunnelPort(), h.TunnelTarget))
+	}
+	if h.Verbose > 0 {
+		field("Verbose", verbosityLabel(h.Verbose))
+	}
+	if h.Multiplexing {
+		field("Multiplexing", "yes")
+	}
+	if h.Pinned {
+		field("Pinned", "yes")
+	}
+	for _, forward := range h.LocalForward {
+		field("LocalForward", forward)
+	}
+	for _, forward := range h.RemoteForward {
+		field("RemoteForward", forward)
+	}
+
+	for i, line := range lines {
+		lines[i] = ansi.Truncate(line, width, "…")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m model) detailWidth() int {
+	h, _ := appStyle.GetFrameSize()
+	return max(m.width-listWidth-2*h, 10)
+}
+
+// syncDetail points the notes viewport at the selected host and fits it into
+// the space left below the fields
+func (m *model) syncDetail() {
+	h, _ := m.selectedHost()
+	width := m.detailWidth()
+	if h.Host != m.detailHost || m.notes.Width != width {
+		m.detailHost = h.Host
+		m.notes.SetContent(ansi.Wrap(h.Notes, width, ""))
+		m.notes.GotoTop()
+	}
+
+	_, v := appStyle.GetFrameSize()
+	fieldsHeight := lipgloss.Height(m.detailFields(h, width))
+	m.notes.Width = width
+	// fields, a blank line and the notes title sit above the viewport
+	m.notes.Height = max(m.height-v-fieldsHeight-2, 1)
+}
+
+// detailFields renders the panel for h as it would be connected to, with a
+// note when the next connection overrides its verbosity
+func (m model) detailFields(h SSHHost, width int) string {
+	panel := renderDetailPanel(m.forConnect(h), width)
+	panel += "\n" + ansi.Truncate(detailLabelStyle.Render("Last connected: ")+lastConnectedLabel(m.lastSeen, h.Host, time.Now()), width, "…")
+	if h.sharesConnection() {
+		state := "none"
+		if m.shared[h.Host] {
+			state = sharedStyle.Render("active") + ", O closes it"
+		}
+		panel += "\n" + ansi.Truncate(detailLabelStyle.Render("Shared connection: ")+state, width, "…")
+	}
+	if m.verboseSet {
+		panel += "\n" + ansi.Truncate(statusMessageStyle("Next connection: "+verbosityLabel(m.verboseOnce)), width, "…")
+	}
+	if e, ok := m.lastErrors[h.Host]; ok {
+		panel += "\n\n" + e.view(width)
+	}
+	return panel
+}
+
+func (m model) detailView() string {
+	h, ok := m.selectedHost()
+	if !ok {
+		return "No item selected"
+	}
+
+	panel := m.detailFields(h, m.detailWidth())
+	if h.Notes == "" {
+		return panel
+	}
+
+	title := "Notes"
+	if !m.notes.AtTop() || !m.notes.AtBottom() {
+		title += fmt.Sprintf(" (%d%%, J/K to scroll)", int(m.notes.ScrollPercent()*100))
+	}
+	return panel + "\n\n" + notesTitleStyle.Render(title) + "\n" + m.notes.View()
+}
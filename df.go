@@ -0,0 +1,169 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+)
+
+var (
+	diskFullStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#ED567A")).Padding(0, 1)
+	diskWarnStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#E8A33D")).Padding(0, 1)
+	diskOKStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#04B575")).Padding(0, 1)
+)
+
+// DiskUsage is one line of df -h
+type DiskUsage struct {
+	Filesystem string
+	Size       string
+	Used       string
+	Avail      string
+	UsePercent int
+	MountPoint string
+}
+
+// ParseDF reads the output of df -h. Linux prints six columns and wraps long
+// filesystem names onto a line of their own, macOS adds three inode columns
+// before the mount point. Lines are split around the use percentage, so
+// filesystems and mount points may contain spaces.
+func ParseDF(out string) ([]DiskUsage, error) {
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) == 0 || !strings.HasPrefix(lines[0], "Filesystem") {
+		return nil, fmt.Errorf("unexpected df output %q", lastLine(out))
+	}
+	// columns between the use percentage and the mount point
+	inodes := 0
+	if strings.Contains(lines[0], "iused") {
+		inodes = 3
+	}
+
+	var usages []DiskUsage
+	var wrapped string
+	for _, line := range lines[1:] {
+		fields := strings.Fields(line)
+		if len(fields) == 1 {
+			wrapped = fields[0]
+			continue
+		}
+		if wrapped != "" {
+			fields = append([]string{wrapped}, fields...)
+			wrapped = ""
+		}
+
+		use := -1
+		for i := 4; i < len(fields); i++ {
+			if strings.HasSuffix(fields[i], "%") || fields[i] == "-" {
+				use = i
+				break
+			}
+		}
+		if use < 0 || len(fields) <= use+inodes+1 {
+			return nil, fmt.Errorf("unexpected df line %q", line)
+		}
+		// "-" for pseudo filesystems without a size
+		percent, _ := strconv.Atoi(strings.TrimSuffix(fields[use], "%"))
+		usages = append(usages, DiskUsage{
+			Filesystem: strings.Join(fields[:use-3], " "),
+			Size:       fields[use-3],
+			Used:       fields[use-2],
+			Avail:      fields[use-1],
+			UsePercent: percent,
+			MountPoint: strings.Join(fields[use+inodes+1:], " "),
+		})
+	}
+	return usages, nil
+}
+
+func diskUsageStyle(percent int) lipgloss.Style {
+	switch {
+	case percent > 90:
+		return diskFullStyle
+	case percent > 75:
+		return diskWarnStyle
+	}
+	return diskOKStyle
+}
+
+// hostDiskUsage is a DiskUsage with the host it was read from
+type hostDiskUsage struct {
+	host string
+	DiskUsage
+}
+
+func renderDFTable(rows []hostDiskUsage, showHost bool) string {
+	headers := []string{"Filesystem", "Size", "Used", "Avail", "Use%", "Mounted on"}
+	if showHost {
+		headers = append([]string{"Host"}, headers...)
+	}
+	t := table.New().
+		Border(lipgloss.RoundedBorder()).
+		Headers(headers...).
+		StyleFunc(func(row, col int) lipgloss.Style {
+			if row == table.HeaderRow {
+				return dashboardHeaderStyle
+			}
+			return diskUsageStyle(rows[row].UsePercent)
+		})
+	for _, r := range rows {
+		cells := []string{r.Filesystem, r.Size, r.Used, r.Avail, strconv.Itoa(r.UsePercent) + "%", r.MountPoint}
+		if showHost {
+			cells = append([]string{r.host}, cells...)
+		}
+		t.Row(cells...)
+	}
+	return t.Render()
+}
+
+func runDF(args []string) int {
+	fs := flag.NewFlagSet("df", flag.ExitOnError)
+	var aliases []string
+	fs.Var((*stringsFlag)(&aliases), "host", "alias of a host, can be repeated")
+	tag := fs.String("tag", "", "all hosts with this tag")
+	fs.Parse(args)
+
+	if len(aliases) == 0 && *tag == "" {
+		fmt.Fprintln(os.Stderr, "no hosts given, use --host <alias> or --tag <tag>")
+		return 2
+	}
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load config:", err)
+		return 1
+	}
+	hosts, err := selectHosts(config.Hosts, strings.Join(aliases, ","), *tag)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	var rows []hostDiskUsage
+	failed := false
+	for _, r := range RunOnHosts(hosts, "df -h", len(hosts), nil) {
+		if r.Err != nil || r.ExitCode != 0 {
+			fmt.Fprintf(os.Stderr, "%s: df failed: %s\n", r.Host, r.failure())
+			failed = true
+			continue
+		}
+		usages, err := ParseDF(r.Stdout)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", r.Host, err)
+			failed = true
+			continue
+		}
+		for _, u := range usages {
+			rows = append(rows, hostDiskUsage{host: r.Host, DiskUsage: u})
+		}
+	}
+	if len(rows) > 0 {
+		fmt.Println(renderDFTable(rows, len(hosts) > 1))
+	}
+	if failed {
+		return 1
+	}
+	return 0
+}
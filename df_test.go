@@ -0,0 +1,75 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseDF(t *testing.T) {
+	tests := []struct {
+		name    string
+		out     string
+		want    []DiskUsage
+		wantErr bool
+	}{
+		{
+			name: "linux",
+			out: `Filesystem                         Size  Used Avail Use% Mounted on
+udev                               3.9G     0  3.9G   0% /dev
+tmpfs                              796M  1.7M  794M   1% /run
+/dev/mapper/ubuntu--vg-ubuntu--lv   98G   42G   52G  45% /
+/dev/sda2                          2.0G  251M  1.6G  14% /boot
+`,
+			want: []DiskUsage{
+				{"udev", "3.9G", "0", "3.9G", 0, "/dev"},
+				{"tmpfs", "796M", "1.7M", "794M", 1, "/run"},
+				{"/dev/mapper/ubuntu--vg-ubuntu--lv", "98G", "42G", "52G", 45, "/"},
+				{"/dev/sda2", "2.0G", "251M", "1.6G", 14, "/boot"},
+			},
+		},
+		{
+			name: "linux wrapped device name",
+			out: `Filesystem            Size  Used Avail Use% Mounted on
+/dev/mapper/VolGroup00-LogVol00
+                       38G  4.5G   32G  13% /
+/dev/sda1              99M   12M   82M  13% /boot
+tmpfs                 1.9G     0  1.9G   0% /dev/shm
+`,
+			want: []DiskUsage{
+				{"/dev/mapper/VolGroup00-LogVol00", "38G", "4.5G", "32G", 13, "/"},
+				{"/dev/sda1", "99M", "12M", "82M", 13, "/boot"},
+				{"tmpfs", "1.9G", "0", "1.9G", 0, "/dev/shm"},
+			},
+		},
+		{
+			name: "macos",
+			out: `Filesystem        Size    Used   Avail Capacity iused      ifree %iused  Mounted on
+/dev/disk3s1s1   460Gi    10Gi   392Gi     3%  404167 4109711673    0%   /
+devfs            200Ki   200Ki     0Bi   100%     692          0  100%   /dev
+/dev/disk3s6     460Gi   2.0Gi   392Gi     1%       2 4109711673    0%   /System/Volumes/VM
+map auto_home      0Bi     0Bi     0Bi   100%       0          0     -   /System/Volumes/Data/home
+/dev/disk5s1     100Mi    50Mi    50Mi    50%      10 4294967269    0%   /Volumes/My Disk
+`,
+			want: []DiskUsage{
+				{"/dev/disk3s1s1", "460Gi", "10Gi", "392Gi", 3, "/"},
+				{"devfs", "200Ki", "200Ki", "0Bi", 100, "/dev"},
+				{"/dev/disk3s6", "460Gi", "2.0Gi", "392Gi", 1, "/System/Volumes/VM"},
+				{"map auto_home", "0Bi", "0Bi", "0Bi", 100, "/System/Volumes/Data/home"},
+				{"/dev/disk5s1", "100Mi", "50Mi", "50Mi", 50, "/Volumes/My Disk"},
+			},
+		},
+		{name: "not df", out: "bash: df: command not found\n", wantErr: true},
+		{name: "cut off", out: "Filesystem Size Used Avail Use% Mounted on\n/dev/sda1 99M 12M\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDF(tt.out)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDF: err = %v, want error %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ParseDF =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
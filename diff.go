@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// DiffConfigs describes how b differs from a, one line per added, removed or
+// changed host and per changed setting. Hosts are matched by alias.
+func DiffConfigs(a, b *Config) []string {
+	var lines []string
+	for _, field := range diffFields(a.Settings, b.Settings) {
+		lines = append(lines, "~ settings: "+field)
+	}
+
+	old := make(map[string]SSHHost, len(a.Hosts))
+	for _, h := range a.Hosts {
+		old[h.Host] = h
+	}
+	seen := make(map[string]bool, len(b.Hosts))
+	for _, h := range b.Hosts {
+		seen[h.Host] = true
+		before, ok := old[h.Host]
+		if !ok {
+			lines = append(lines, "+ "+h.Host)
+			continue
+		}
+		for _, field := range diffFields(before, h) {
+			lines = append(lines, "~ "+h.Host+": "+field)
+		}
+	}
+	for _, h := range a.Hosts {
+		if !seen[h.Host] {
+			lines = append(lines, "- "+h.Host)
+		}
+	}
+	return lines
+}
+
+// diffFields compares two structs of the same type field by field and names
+// the changed ones by their toml key
+func diffFields(a, b any) []string {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	var changed []string
+	for i := range va.NumField() {
+		field := va.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		x, y := va.Field(i).Interface(), vb.Field(i).Interface()
+		if reflect.DeepEqual(x, y) || (isEmpty(va.Field(i)) && isEmpty(vb.Field(i))) {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("toml"), ",")
+		changed = append(changed, fmt.Sprintf("%s %v -> %v", name, x, y))
+	}
+	return changed
+}
+
+// isEmpty treats nil and empty slices and maps as equal, they encode the same
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return false
+}
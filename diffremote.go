@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	diffAddStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("#04B575"))
+	diffRemoveStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#ED567A"))
+	diffHunkStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#5A9BD5"))
+)
+
+// unifiedDiff runs diff -u between the remote copy and the local file. An
+// empty result means the files are the same.
+func unifiedDiff(remoteCopy, remoteLabel, local string) (string, error) {
+	out, err := exec.Command("diff", "-u", "--label", remoteLabel, "--label", local, remoteCopy, local).Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		// 1 only means the files differ
+		return string(out), nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("diff failed: %w", err)
+	}
+	return string(out), nil
+}
+
+// colorDiff colours added lines green, removed ones red and hunk headers blue
+func colorDiff(diff string) string {
+	lines := strings.Split(strings.TrimSuffix(diff, "\n"), "\n")
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			lines[i] = lipgloss.NewStyle().Bold(true).Render(line)
+		case strings.HasPrefix(line, "+"):
+			lines[i] = diffAddStyle.Render(line)
+		case strings.HasPrefix(line, "-"):
+			lines[i] = diffRemoveStyle.Render(line)
+		case strings.HasPrefix(line, "@@"):
+			lines[i] = diffHunkStyle.Render(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// uploadFile replaces path on h with the contents of the local file
+func uploadFile(h SSHHost, local, path string) error {
+	f, err := os.Open(local)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	cmd := remoteCommand(h, "cat > "+shellQuote(path))
+	cmd.Stdin = f
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%w: %s", err, lastLine(msg))
+		}
+		return err
+	}
+	return nil
+}
+
+func runDiffRemote(args []string) int {
+	fs := flag.NewFlagSet("diff-remote", flag.ExitOnError)
+	alias := fs.String("host", "", "alias of the host")
+	local := fs.String("local", "", "local file")
+	path := fs.String("remote", "", "file on the host")
+	sync := fs.Bool("sync", false, "offer to upload the local file after showing the diff")
+	fs.Parse(args)
+
+	if *local == "" || *path == "" {
+		fmt.Fprintln(os.Stderr, "both --local <file> and --remote <path> are needed")
+		return 2
+	}
+	h, err := loadHost(*alias)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	tmp, err := os.CreateTemp("", "quickssh-remote-*")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to create temp file:", err)
+		return 1
+	}
+	defer os.Remove(tmp.Name())
+	err = catRemote(h, *path, tmp, os.Stderr)
+	tmp.Close()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to fetch the remote file:", err)
+		return 1
+	}
+
+	diff, err := unifiedDiff(tmp.Name(), h.Host+":"+*path, *local)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if diff == "" {
+		fmt.Println("No differences")
+		return 0
+	}
+	fmt.Println(colorDiff(diff))
+
+	if !*sync {
+		return 0
+	}
+	if !confirm(fmt.Sprintf("Upload %s to %s:%s?", *local, h.Host, *path)) {
+		fmt.Println("Aborted")
+		return 1
+	}
+	if err := uploadFile(h, *local, *path); err != nil {
+		fmt.Fprintln(os.Stderr, "upload failed:", err)
+		return 1
+	}
+	fmt.Println("Uploaded", *local)
+	return 0
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+
+	"github.com/BurntSushi/toml"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	checkPassStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#04B575"))
+	checkFailStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5F87"))
+	checkFixStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
+
+	sshVersionRegexp = regexp.MustCompile(`OpenSSH_(?:for_Windows_)?(\d+)\.(\d+)`)
+)
+
+// result of a single doctor check, fix is only shown for failed checks
+type checkResult struct {
+	name   string
+	ok     bool
+	detail string
+	fix    string
+}
+
+func runDoctor(args []string) int {
+	fs := flag.NewFlagSet("doctor", flag.ExitOnError)
+	fs.Parse(args)
+
+	results := []checkResult{
+		checkSSHBinary(),
+		checkConfigFile(),
+		checkSSHDir(),
+		checkKnownHosts(),
+	}
+	results = append(results, checkIdentityFiles()...)
+	results = append(results, checkSSHAgent(), checkTerminal())
+
+	failed := 0
+	for _, r := range results {
+		if r.ok {
+			fmt.Printf("%s %s", checkPassStyle.Render("✓"), r.name)
+		} else {
+			failed++
+			fmt.Printf("%s %s", checkFailStyle.Render("✗"), r.name)
+		}
+		if r.detail != "" {
+			fmt.Printf(": %s", r.detail)
+		}
+		fmt.Println()
+		if !r.ok && r.fix != "" {
+			fmt.Println("  " + checkFixStyle.Render("→ "+r.fix))
+		}
+	}
+
+	if failed > 0 {
+		fmt.Printf("\n%d of %d checks failed\n", failed, len(results))
+		return 1
+	}
+	return 0
+}
+
+func checkSSHBinary() checkResult {
+	r := checkResult{name: "ssh binary"}
+	path, err := exec.LookPath(sshBinary)
+	if err != nil && sshBinary != "ssh" {
+		r.detail = err.Error()
+		r.fix = "check ssh_path in the config"
+		return r
+	} else if err != nil {
+		r.detail = "not found in PATH"
+		r.fix = "install an OpenSSH client and make sure ssh is on your PATH"
+		return r
+	}
+
+	// ssh -V prints its version to stderr
+	out, err := exec.Command(path, "-V").CombinedOutput()
+	if err != nil {
+		r.detail = fmt.Sprintf("could not run %s -V: %v", path, err)
+		r.fix = "check that the ssh binary is executable"
+		return r
+	}
+
+	match := sshVersionRegexp.FindStringSubmatch(string(out))
+	if match == nil {
+		r.detail = "unrecognized version string " + strconv.Quote(string(out))
+		r.fix = "quickssh is tested against OpenSSH, other clients may not work"
+		return r
+	}
+
+	major, _ := strconv.Atoi(match[1])
+	r.detail = fmt.Sprintf("OpenSSH %s.%s", match[1], match[2])
+	if major < 7 {
+		r.fix = "upgrade to OpenSSH 7.0 or newer"
+		return r
+	}
+	r.ok = true
+	return r
+}
+
+func checkConfigFile() checkResult {
+	r := checkResult{name: "config file", detail: configFilePath}
+	var config Config
+	if _, err := toml.DecodeFile(configFilePath, &config); err != nil {
+		r.detail = err.Error()
+		r.fix = "fix the TOML syntax in " + configFilePath
+		return r
+	}
+	if problems := validateConfig(&config); len(problems) > 0 {
+		r.detail = problems[0].Error()
+		if len(problems) > 1 {
+			r.detail += fmt.Sprintf(" (and %d more problems)", len(problems)-1)
+		}
+		r.fix = "fix the hosts in " + configFilePath
+		return r
+	}
+	r.ok = true
+	return r
+}
+
+func checkSSHDir() checkResult {
+	r := checkResult{name: "~/.ssh permissions"}
+	info, err := os.Stat(expandPath("~/.ssh"))
+	if err != nil {
+		r.detail = "directory missing"
+		r.fix = "run: mkdir -m 700 ~/.ssh"
+		return r
+	}
+
+	r.detail = fmt.Sprintf("%04o", info.Mode().Perm())
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0o077 != 0 {
+		r.fix = "run: chmod 700 ~/.ssh"
+		return r
+	}
+	r.ok = true
+	return r
+}
+
+func checkKnownHosts() checkResult {
+	r := checkResult{name: "~/.ssh/known_hosts"}
+	if _, err := os.Stat(expandPath("~/.ssh/known_hosts")); err != nil {
+		r.detail = "file missing"
+		r.fix = "connect to a host once with ssh to create it"
+		return r
+	}
+	r.ok = true
+	return r
+}
+
+// checkIdentityFiles returns one result per host that has an identity file
+// configured
+func checkIdentityFiles() []checkResult {
+	config, err := loadConfig()
+	if err != nil {
+		// already reported by checkConfigFile
+		return nil
+	}
+
+	var results []checkResult
+	for _, h := range config.Hosts {
+		if h.IdentityFile == "" {
+			continue
+		}
+		path := expandPath(h.IdentityFile)
+		r := checkResult{name: "identity file for " + h.Host, detail: path}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			r.detail = path + " missing"
+			r.fix = "generate a key with ssh-keygen or update identity_file for " + h.Host
+			results = append(results, r)
+			continue
+		}
+		if runtime.GOOS != "windows" && info.Mode().Perm()&0o077 != 0 {
+			r.detail = fmt.Sprintf("%s has mode %04o", path, info.Mode().Perm())
+			r.fix = "run: chmod 600 " + filepath.ToSlash(path)
+			results = append(results, r)
+			continue
+		}
+		r.ok = true
+		results = append(results, r)
+	}
+	return results
+}
+
+func checkSSHAgent() checkResult {
+	r := checkResult{name: "ssh-agent"}
+	if os.Getenv("SSH_AUTH_SOCK") == "" {
+		r.detail = "SSH_AUTH_SOCK is not set"
+		r.fix = `start an agent with: eval "$(ssh-agent -s)"`
+		return r
+	}
+	r.ok = true
+	return r
+}
+
+func checkTerminal() checkResult {
+	r := checkResult{name: "terminal colours"}
+	term := os.Getenv("TERM")
+	if runtime.GOOS == "windows" && term == "" {
+		// Windows Terminal and conhost handle ANSI without setting TERM
+		r.ok = true
+		return r
+	}
+
+	r.detail = "TERM=" + term
+	if term == "" || term == "dumb" {
+		r.fix = "set TERM to a colour capable terminal, e.g. xterm-256color"
+		return r
+	}
+	r.ok = true
+	return r
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var duplicateStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#777777"))
+
+// duplicateGroup is a set of hosts pointing at the same HostName and port
+type duplicateGroup struct {
+	address string
+	// indexes into the host slice, in config order
+	indexes []int
+}
+
+// findDuplicates groups the hosts sharing an address, ignoring the case of
+// the host name. Groups are in the order their first host appears.
+func findDuplicates(hosts []SSHHost) []duplicateGroup {
+	byAddress := make(map[string][]int)
+	var order []string
+	for i, h := range hosts {
+		address := net.JoinHostPort(strings.ToLower(h.address()), strconv.Itoa(h.port()))
+		if _, ok := byAddress[address]; !ok {
+			order = append(order, address)
+		}
+		byAddress[address] = append(byAddress[address], i)
+	}
+	var groups []duplicateGroup
+	for _, address := range order {
+		if len(byAddress[address]) > 1 {
+			groups = append(groups, duplicateGroup{address: address, indexes: byAddress[address]})
+		}
+	}
+	return groups
+}
+
+// mergeDuplicate adds the tags and options of other that keep doesn't have
+// yet, and its description and notes if keep has none
+func mergeDuplicate(keep, other SSHHost) SSHHost {
+	keep.Tags = slices.Clone(keep.Tags)
+	for _, tag := range other.Tags {
+		if !slices.Contains(keep.Tags, tag) {
+			keep.Tags = append(keep.Tags, tag)
+		}
+	}
+	if len(other.Options) > 0 {
+		options := maps.Clone(other.Options)
+		maps.Copy(options, keep.Options)
+		keep.Options = options
+	}
+	if keep.Desc == "" {
+		keep.Desc = other.Desc
+	}
+	if keep.Notes == "" {
+		keep.Notes = other.Notes
+	}
+	return keep
+}
+
+// refreshDuplicates marks the hosts that share their address with another
+// one, only the items whose mark changed are replaced
+func (m *model) refreshDuplicates() tea.Cmd {
+	m.duplicates = make(map[string]bool)
+	for _, g := range findDuplicates(m.hosts) {
+		for _, i := range g.indexes {
+			m.duplicates[m.hosts[i].Host] = true
+		}
+	}
+	var cmds []tea.Cmd
+	for i, item := range m.list.Items() {
+		h := item.(hostItem)
+		if h.duplicate != m.duplicates[h.Host] {
+			cmds = append(cmds, m.list.SetItem(i, m.itemFor(h.SSHHost)))
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// duplicatesPanel lists the duplicate groups, the cursor is on a host
+type duplicatesPanel struct {
+	groups []duplicateGroup
+	cursor int
+}
+
+// selected returns the group and the host index under the cursor
+func (p duplicatesPanel) selected() (duplicateGroup, int) {
+	n := p.cursor
+	for _, g := range p.groups {
+		if n < len(g.indexes) {
+			return g, g.indexes[n]
+		}
+		n -= len(g.indexes)
+	}
+	return duplicateGroup{}, -1
+}
+
+func (p duplicatesPanel) size() int {
+	n := 0
+	for _, g := range p.groups {
+		n += len(g.indexes)
+	}
+	return n
+}
+
+func (m *model) openDuplicates() tea.Cmd {
+	groups := findDuplicates(m.hosts)
+	if len(groups) == 0 {
+		return m.list.NewStatusMessage(statusMessageStyle("No two hosts share an address"))
+	}
+	m.dups = duplicatesPanel{groups: groups}
+	m.view = duplicatesView
+	return nil
+}
+
+func (m *model) updateDuplicates(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc", "q":
+		m.view = listView
+	case "up", "k":
+		m.dups.cursor = max(m.dups.cursor-1, 0)
+	case "down", "j":
+		m.dups.cursor = min(m.dups.cursor+1, m.dups.size()-1)
+	case "enter":
+		group, keepIndex := m.dups.selected()
+		if keepIndex < 0 {
+			return nil
+		}
+		var removed []string
+		for _, i := range group.indexes {
+			if i != keepIndex {
+				removed = append(removed, m.hosts[i].Host)
+			}
+		}
+		m.confirmBulk(bulkChange{
+			action:  "Merge into " + m.hosts[keepIndex].Host + " and delete",
+			op:      "merge-duplicates into " + m.hosts[keepIndex].Host,
+			aliases: removed,
+			apply:   func(m *model) tea.Cmd { return m.mergeInto(group, keepIndex) },
+		})
+	}
+	return nil
+}
+
+// mergeInto merges the other hosts of group into the one at keepIndex and
+// deletes them, as one undoable edit per host
+func (m *model) mergeInto(group duplicateGroup, keepIndex int) tea.Cmd {
+	before := m.hosts[keepIndex]
+	after := before
+	var removed []string
+	for _, i := range group.indexes {
+		if i != keepIndex {
+			after = mergeDuplicate(after, m.hosts[i])
+			removed = append(removed, m.hosts[i].Host)
+		}
+	}
+	cmds := []tea.Cmd{m.commit(edit{index: keepIndex, before: &before, after: &after})}
+	// from the back so the other indexes stay valid
+	for _, i := range slices.Backward(group.indexes) {
+		if i != keepIndex {
+			deleted := m.hosts[i]
+			cmds = append(cmds, m.commit(edit{index: i, before: &deleted}))
+		}
+	}
+
+	status := fmt.Sprintf("Merged %s into %s, press s to save", strings.Join(removed, ", "), before.Host)
+	cmds = append(cmds, m.list.NewStatusMessage(statusMessageStyle(status)))
+	m.dups = duplicatesPanel{groups: findDuplicates(m.hosts)}
+	if len(m.dups.groups) == 0 {
+		m.view = listView
+	}
+	return tea.Batch(cmds...)
+}
+
+func (m model) duplicatesView() string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Hosts sharing an address") + "\n\n")
+	n := 0
+	for _, g := range m.dups.groups {
+		b.WriteString(detailLabelStyle.Render(g.address) + "\n")
+		for _, i := range g.indexes {
+			h := m.hosts[i]
+			line := h.Host
+			if len(h.Tags) > 0 {
+				line += duplicateStyle.Render("  " + strings.Join(h.Tags, ", "))
+			}
+			if n == m.dups.cursor {
+				b.WriteString(selectedOptionStyle.Render("> "+h.Host) + strings.TrimPrefix(line, h.Host) + "\n")
+			} else {
+				b.WriteString("  " + line + "\n")
+			}
+			n++
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(checkFixStyle.Render("enter: keep this host, merge the others into it and delete them\nesc: close"))
+	return b.String()
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// effectiveConfig shows what ssh -G prints for the selected host, opened
+// with S. seq tells results for an older panel apart.
+type effectiveConfig struct {
+	seq     int
+	alias   string
+	loading bool
+	err     error
+	output  viewport.Model
+}
+
+type effectiveConfigMsg struct {
+	seq int
+	// option names and values in the order ssh prints them
+	options [][2]string
+	// options quickssh passes on the command line, in lower case like ssh
+	// prints them
+	set map[string]bool
+	err error
+}
+
+// ssh options set by command line flags, named like ssh -G prints them
+var sshFlagOptions = map[string]string{
+	"-p": "port",
+	"-i": "identityfile",
+	"-A": "forwardagent",
+	"-J": "proxyjump",
+}
+
+// quicksshOptions returns the options args set, lower-cased
+func quicksshOptions(h SSHHost, args []string) map[string]bool {
+	set := make(map[string]bool)
+	if !h.fromSSHConfig {
+		// part of the destination
+		if h.User != "" {
+			set["user"] = true
+		}
+		if h.HostName != "" {
+			set["hostname"] = true
+		}
+	}
+	for i, arg := range args {
+		if name, ok := sshFlagOptions[arg]; ok {
+			set[name] = true
+		}
+		if arg == "-o" && i+1 < len(args) {
+			name, _, _ := strings.Cut(args[i+1], "=")
+			set[strings.ToLower(name)] = true
+		}
+	}
+	return set
+}
+
+// parseSSHG splits the output of ssh -G into option names and values
+func parseSSHG(out string) [][2]string {
+	var options [][2]string
+	for _, line := range strings.Split(out, "\n") {
+		name, value, _ := strings.Cut(strings.TrimSpace(line), " ")
+		if name != "" {
+			options = append(options, [2]string{name, value})
+		}
+	}
+	return options
+}
+
+// dumpSSHConfig runs ssh -G with the options quickssh would connect with, so
+// the result is what ssh_config and quickssh make of the host together
+func dumpSSHConfig(h SSHHost, settings Settings, seq int) tea.Cmd {
+	args := sshOptions(h)
+	return func() tea.Msg {
+		cmd := sshCommand(settings, append(append([]string{"-G"}, args...), h.destination())...)
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout, cmd.Stderr = &stdout, &stderr
+		if err := cmd.Run(); err != nil {
+			out := strings.TrimSpace(stderr.String())
+			// OpenSSH before 6.8 prints its usage
+			if strings.Contains(out, "unknown option") || strings.Contains(out, "illegal option") {
+				return effectiveConfigMsg{seq: seq, err: errors.New("this ssh doesn't support -G, OpenSSH 6.8 or newer is needed")}
+			}
+			if out != "" {
+				err = fmt.Errorf("%w: %s", err, lastLine(out))
+			}
+			return effectiveConfigMsg{seq: seq, err: err}
+		}
+		return effectiveConfigMsg{seq: seq, options: parseSSHG(stdout.String()), set: quicksshOptions(h, args)}
+	}
+}
+
+// openEffectiveConfig asks ssh for the effective config of the selected host
+func (m *model) openEffectiveConfig() tea.Cmd {
+	h, ok := m.selectedHost()
+	if !ok {
+		return nil
+	}
+	m.effective = effectiveConfig{seq: m.effective.seq + 1, alias: h.Host, loading: true, output: viewport.New(0, 0)}
+	m.view = effectiveConfigView
+	m.sizeEffectiveConfig()
+	return dumpSSHConfig(h, m.settings, m.effective.seq)
+}
+
+// sizeEffectiveConfig fits the viewport into the window
+func (m *model) sizeEffectiveConfig() {
+	h, v := appStyle.GetFrameSize()
+	// title, blank, legend and help lines
+	m.effective.output.Width = max(m.width-h, 1)
+	m.effective.output.Height = max(m.height-v-4, 1)
+}
+
+// showEffectiveConfig puts the options in the viewport as aligned columns,
+// the ones quickssh sets highlighted
+func (m *model) showEffectiveConfig(msg effectiveConfigMsg) {
+	m.effective.loading = false
+	m.effective.err = msg.err
+	var b strings.Builder
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	for _, option := range msg.options {
+		fmt.Fprintf(tw, "%s\t%s\n", option[0], option[1])
+	}
+	tw.Flush()
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	for i, option := range msg.options {
+		if msg.set[option[0]] {
+			lines[i] = watchChangedStyle.Render(lines[i])
+		}
+	}
+	m.effective.output.SetContent(strings.Join(lines, "\n"))
+}
+
+func (m *model) updateEffectiveConfig(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc", "q":
+		// a dump still running is dropped by its seq
+		m.effective.seq++
+		m.view = listView
+		return nil
+	}
+	var cmd tea.Cmd
+	m.effective.output, cmd = m.effective.output.Update(msg)
+	return cmd
+}
+
+func (m model) effectiveConfigView() string {
+	e := m.effective
+	title := titleStyle.Render("ssh -G " + e.alias)
+	var body, legend string
+	switch {
+	case e.loading:
+		body = "Running ssh -G..."
+	case e.err != nil:
+		body = errorMessageStyle("ssh -G failed: " + e.err.Error())
+	default:
+		body = e.output.View()
+		legend = "what ssh would use with ssh_config and quickssh's options, " + watchChangedStyle.Render("highlighted") + " ones come from quickssh"
+	}
+	return title + "\n\n" + body + "\n" + legend + "\n" + checkFixStyle.Render("j/k: scroll • esc: close")
+}
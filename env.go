@@ -0,0 +1,180 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	envNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	// values made of these need no quoting in a shell
+	shellSafeRegexp = regexp.MustCompile(`^[A-Za-z0-9_./:@%+=,-]+$`)
+)
+
+// FetchRemoteEnv returns the environment of a login on h. env -0 keeps
+// values with newlines intact, plain env is the fallback where it's missing.
+func FetchRemoteEnv(h SSHHost) (map[string]string, error) {
+	out, err := runRemote(h, "env -0 2>/dev/null || env")
+	if err != nil {
+		return nil, err
+	}
+	sep := "\n"
+	if strings.Contains(out, "\x00") {
+		sep = "\x00"
+	}
+
+	env := make(map[string]string)
+	for _, entry := range strings.Split(out, sep) {
+		name, value, ok := strings.Cut(entry, "=")
+		if ok && envNameRegexp.MatchString(name) {
+			env[name] = value
+		}
+	}
+	return env, nil
+}
+
+// FormatEnvExports renders env as sorted export lines for a shell
+func FormatEnvExports(env map[string]string) string {
+	var b strings.Builder
+	for _, name := range slices.Sorted(maps.Keys(env)) {
+		fmt.Fprintf(&b, "export %s=%s\n", name, shellQuote(env[name]))
+	}
+	return b.String()
+}
+
+// shellQuote single-quotes s unless it's safe as is, quotes inside end the
+// quoted part and are escaped with a backslash
+func shellQuote(s string) string {
+	if shellSafeRegexp.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// ParseEnvExports reads a file written by FormatEnvExports
+func ParseEnvExports(data string) (map[string]string, error) {
+	env := make(map[string]string)
+	rest := data
+	for {
+		rest = strings.TrimLeft(rest, " \t\n")
+		if rest == "" {
+			return env, nil
+		}
+		line, ok := strings.CutPrefix(rest, "export ")
+		if !ok {
+			return nil, fmt.Errorf("expected an export line, got %q", firstLine(rest))
+		}
+		name, value, ok := strings.Cut(line, "=")
+		if !ok || !envNameRegexp.MatchString(name) {
+			return nil, fmt.Errorf("invalid export line %q", firstLine(rest))
+		}
+
+		var b strings.Builder
+		i := 0
+	scan:
+		for i < len(value) {
+			switch c := value[i]; {
+			case c == '\'':
+				end := strings.IndexByte(value[i+1:], '\'')
+				if end < 0 {
+					return nil, fmt.Errorf("unterminated quote in the value of %s", name)
+				}
+				b.WriteString(value[i+1 : i+1+end])
+				i += end + 2
+			case c == '\\' && i+1 < len(value):
+				b.WriteByte(value[i+1])
+				i += 2
+			case c == '\n':
+				break scan
+			default:
+				b.WriteByte(c)
+				i++
+			}
+		}
+		env[name] = b.String()
+		rest = value[i:]
+	}
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
+
+// DiffEnv lists the variables added, removed or changed from old to new
+func DiffEnv(old, new map[string]string) []string {
+	var lines []string
+	for _, name := range slices.Sorted(maps.Keys(new)) {
+		before, ok := old[name]
+		switch {
+		case !ok:
+			lines = append(lines, "+ "+name+"="+new[name])
+		case before != new[name]:
+			lines = append(lines, "~ "+name+": "+before+" -> "+new[name])
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(old)) {
+		if _, ok := new[name]; !ok {
+			lines = append(lines, "- "+name)
+		}
+	}
+	return lines
+}
+
+func runEnv(args []string) int {
+	fs := flag.NewFlagSet("env", flag.ExitOnError)
+	alias := fs.String("host", "", "alias of the host to read the environment of")
+	output := fs.String("output", "", "write the export lines to this file instead of stdout")
+	diff := fs.String("diff", "", "compare against a file captured earlier and print the changes")
+	fs.Parse(args)
+
+	h, err := loadHost(*alias)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	env, err := FetchRemoteEnv(h)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to read remote environment:", err)
+		return 1
+	}
+
+	if *diff != "" {
+		data, err := os.ReadFile(*diff)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		old, err := ParseEnvExports(string(data))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to parse %s: %v\n", *diff, err)
+			return 1
+		}
+		changes := DiffEnv(old, env)
+		if len(changes) == 0 {
+			fmt.Println("No changes")
+		}
+		for _, line := range changes {
+			fmt.Println(line)
+		}
+		if *output == "" {
+			return 0
+		}
+	}
+
+	exports := FormatEnvExports(env)
+	if *output == "" {
+		fmt.Print(exports)
+		return 0
+	}
+	if err := os.WriteFile(*output, []byte(exports), 0o600); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write environment:", err)
+		return 1
+	}
+	return 0
+}
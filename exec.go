@@ -0,0 +1,387 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// longest stdout shown in a table cell
+const execTableWidth = 80
+
+// most bytes of stdout and of stderr kept per host, the rest is dropped
+const execOutputLimit = 1 << 20
+
+// ExecResult is the outcome of running a command on one host
+type ExecResult struct {
+	Host     string
+	ExitCode int
+	Stdout   string
+	Stderr   string
+	Elapsed  time.Duration
+	// set when ssh itself couldn't be run
+	Err error
+	// Truncated is set when output beyond execOutputLimit was dropped
+	Truncated bool
+}
+
+// headBuffer keeps the first max bytes written to it and counts the rest
+type headBuffer struct {
+	buf     bytes.Buffer
+	max     int
+	dropped int
+}
+
+func (b *headBuffer) Write(p []byte) (int, error) {
+	keep := min(len(p), max(b.max-b.buf.Len(), 0))
+	b.buf.Write(p[:keep])
+	b.dropped += len(p) - keep
+	return len(p), nil
+}
+
+// String is the kept output with a note about what was dropped
+func (b *headBuffer) String() string {
+	if b.dropped == 0 {
+		return b.buf.String()
+	}
+	return b.buf.String() + fmt.Sprintf("\n[output truncated, %s not kept]\n", formatBytes(int64(b.dropped)))
+}
+
+// execOnHost runs command on h and collects its output and exit code
+func execOnHost(h SSHHost, command string) ExecResult {
+	stdout, stderr := &headBuffer{max: execOutputLimit}, &headBuffer{max: execOutputLimit}
+	cmd := remoteCommand(h, command)
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+
+	start := time.Now()
+	err := cmd.Run()
+	result := ExecResult{
+		Host:      h.Host,
+		Stdout:    stdout.String(),
+		Stderr:    stderr.String(),
+		Elapsed:   time.Since(start),
+		Truncated: stdout.dropped > 0 || stderr.dropped > 0,
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		result.ExitCode = exitErr.ExitCode()
+	} else if err != nil {
+		result.ExitCode, result.Err = -1, err
+	}
+	return result
+}
+
+// failure describes why the command failed on the host: ssh's error, the
+// last line of stderr or the exit code
+func (r ExecResult) failure() string {
+	if r.Err != nil {
+		return r.Err.Error()
+	}
+	if msg := strings.TrimSpace(r.Stderr); msg != "" {
+		return lastLine(msg)
+	}
+	return fmt.Sprintf("exit code %d", r.ExitCode)
+}
+
+// RunOnHosts runs command on every host with at most concurrency at a time.
+// The results are in the order of hosts. done, if set, is called as each host
+// finishes.
+func RunOnHosts(hosts []SSHHost, command string, concurrency int, done func(ExecResult)) []ExecResult {
+	return runOnHosts(hosts, concurrency, func(h SSHHost) ExecResult { return execOnHost(h, command) }, done)
+}
+
+func runOnHosts(hosts []SSHHost, concurrency int, run func(SSHHost) ExecResult, done func(ExecResult)) []ExecResult {
+	results := make([]ExecResult, len(hosts))
+	sem := make(chan struct{}, max(concurrency, 1))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i, h := range hosts {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			results[i] = run(h)
+			if done != nil {
+				mu.Lock()
+				done(results[i])
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// ExecOptions configure ExecuteWithPrecheck
+type ExecOptions struct {
+	Concurrency int
+	// how long to wait for each host's ssh port
+	Timeout time.Duration
+	// called as each reachable host finishes, if set
+	Done func(ExecResult)
+}
+
+// unreachableError marks the results of hosts skipped by the pre-check
+type unreachableError struct{ err error }
+
+func (e *unreachableError) Error() string { return "unreachable: " + e.err.Error() }
+func (e *unreachableError) Unwrap() error { return e.err }
+
+func isUnreachable(err error) bool {
+	var unreachable *unreachableError
+	return errors.As(err, &unreachable)
+}
+
+// ExecuteWithPrecheck dials the ssh port of every host and runs command only
+// on the ones that answered within opts.Timeout. A host is checked by the
+// worker that then runs the command on it, so no host waits for the checks
+// of the others. The skipped hosts are returned separately, with the dial
+// error in Err. Hosts behind a proxy_jump can't be dialed from here and are
+// always run on.
+func ExecuteWithPrecheck(hosts []SSHHost, command string, opts ExecOptions) (results, unreachable []ExecResult) {
+	done := opts.Done
+	if done != nil {
+		done = func(r ExecResult) {
+			if !isUnreachable(r.Err) {
+				opts.Done(r)
+			}
+		}
+	}
+	all := runOnHosts(hosts, opts.Concurrency, func(h SSHHost) ExecResult {
+		if h.ProxyJump == "" {
+			if _, err := checkReachable(context.Background(), h, opts.Timeout); err != nil {
+				return ExecResult{Host: h.Host, ExitCode: -1, Err: &unreachableError{err}}
+			}
+		}
+		return execOnHost(h, command)
+	}, done)
+
+	for _, r := range all {
+		if isUnreachable(r.Err) {
+			unreachable = append(unreachable, r)
+		} else {
+			results = append(results, r)
+		}
+	}
+	return results, unreachable
+}
+
+// selectHosts resolves the hosts named by a comma separated alias list, or
+// all hosts with tag
+func selectHosts(hosts []SSHHost, aliases, tag string) ([]SSHHost, error) {
+	var selected []SSHHost
+	if tag != "" {
+		for _, h := range hosts {
+			if slices.Contains(h.Tags, tag) {
+				selected = append(selected, h)
+			}
+		}
+		if len(selected) == 0 {
+			return nil, fmt.Errorf("no host has the tag %q", tag)
+		}
+		return selected, nil
+	}
+
+	for _, alias := range strings.Split(aliases, ",") {
+		alias = strings.TrimSpace(alias)
+		i := slices.IndexFunc(hosts, func(h SSHHost) bool { return h.Host == alias })
+		if i < 0 {
+			return nil, fmt.Errorf("no host with alias %q", alias)
+		}
+		selected = append(selected, hosts[i])
+	}
+	return selected, nil
+}
+
+// hostGroup is a set of hosts run on together, name is empty when the
+// hosts aren't grouped
+type hostGroup struct {
+	name  string
+	hosts []SSHHost
+}
+
+// groupByFirstTag groups hosts by their first tag, sorted by tag, with the
+// hosts without tags last
+func groupByFirstTag(hosts []SSHHost) []hostGroup {
+	var groups []hostGroup
+	var untagged []SSHHost
+	for _, h := range hosts {
+		if len(h.Tags) == 0 {
+			untagged = append(untagged, h)
+			continue
+		}
+		i := slices.IndexFunc(groups, func(g hostGroup) bool { return g.name == h.Tags[0] })
+		if i < 0 {
+			groups = append(groups, hostGroup{name: h.Tags[0]})
+			i = len(groups) - 1
+		}
+		groups[i].hosts = append(groups[i].hosts, h)
+	}
+	slices.SortFunc(groups, func(a, b hostGroup) int { return strings.Compare(a.name, b.name) })
+	if len(untagged) > 0 {
+		groups = append(groups, hostGroup{name: "untagged", hosts: untagged})
+	}
+	return groups
+}
+
+// printExecResult writes the output of one host under a header line
+func printExecResult(r ExecResult) {
+	header := fmt.Sprintf("== %s (exit %d, %s) ==", r.Host, r.ExitCode, r.Elapsed.Round(time.Millisecond))
+	if r.ExitCode != 0 {
+		header = errorMessageStyle(header)
+	}
+	fmt.Println(header)
+	fmt.Print(r.Stdout)
+	if r.Stderr != "" {
+		fmt.Fprint(os.Stderr, r.Stderr)
+	}
+	if r.Err != nil {
+		fmt.Fprintln(os.Stderr, r.Err)
+	}
+}
+
+// renderExecTable shows one row per host, failures first
+func renderExecTable(results []ExecResult) string {
+	sorted := slices.Clone(results)
+	slices.SortStableFunc(sorted, func(a, b ExecResult) int {
+		if (a.ExitCode == 0) != (b.ExitCode == 0) {
+			if a.ExitCode != 0 {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Host, b.Host)
+	})
+
+	t := table.New().
+		Border(lipgloss.RoundedBorder()).
+		Headers("Host", "Exit", "Stdout", "Elapsed").
+		StyleFunc(func(row, col int) lipgloss.Style {
+			switch {
+			case row == table.HeaderRow:
+				return dashboardHeaderStyle
+			case col == 1 && sorted[row].ExitCode != 0:
+				return unreachableStyle.Padding(0, 1)
+			}
+			return lipgloss.NewStyle().Padding(0, 1)
+		})
+	for _, r := range sorted {
+		out := strings.Join(strings.Fields(r.Stdout), " ")
+		if r.Err != nil {
+			out = r.Err.Error()
+		}
+		t.Row(r.Host, strconv.Itoa(r.ExitCode), ansi.Truncate(out, execTableWidth, "…"), r.Elapsed.Round(time.Millisecond).String())
+	}
+	return t.String()
+}
+
+func runExec(args []string) int {
+	fs := flag.NewFlagSet("exec", flag.ExitOnError)
+	aliases := fs.String("hosts", "", "comma separated aliases to run the command on")
+	tag := fs.String("tag", "", "run on every host with this tag")
+	concurrency := fs.Int("concurrency", 10, "most hosts to run on at the same time, 1 runs them one after another")
+	output := fs.String("output", "text", "output format: text, table, json")
+	report := fs.String("report", "", "also write the full results to this file, as JSON if it ends in .json")
+	preCheck := fs.Bool("pre-check", false, "skip hosts whose ssh port doesn't answer within --timeout")
+	timeout := fs.Duration("timeout", 5*time.Second, "with --pre-check: how long to wait for each host")
+	foreachTag := fs.Bool("foreach-tag", false, "group the hosts by their first tag and run group by group, all hosts if none are given")
+	cmdFlag := fs.String("cmd", "", "command to run, instead of the arguments after --")
+	fs.Parse(args)
+
+	command := cmp.Or(*cmdFlag, strings.Join(fs.Args(), " "))
+	if command == "" || (*aliases == "" && *tag == "" && !*foreachTag) {
+		fmt.Fprintln(os.Stderr, "usage: quickssh exec --hosts a,b | --tag t | --foreach-tag [--concurrency n] [--output text|table|json] [--report file] [--pre-check [--timeout 5s]] -- <command>")
+		return 2
+	}
+	if *output != "text" && *output != "table" && *output != "json" {
+		fmt.Fprintf(os.Stderr, "unknown output %q\n", *output)
+		return 2
+	}
+	if *concurrency < 1 {
+		fmt.Fprintln(os.Stderr, "--concurrency must be at least 1")
+		return 2
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load config:", err)
+		return 1
+	}
+	hosts := config.Hosts
+	if *aliases != "" || *tag != "" {
+		if hosts, err = selectHosts(config.Hosts, *aliases, *tag); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	groups := []hostGroup{{hosts: hosts}}
+	if *foreachTag {
+		groups = groupByFirstTag(hosts)
+	}
+
+	var done func(ExecResult)
+	if *output == "text" {
+		done = printExecResult
+	}
+	started := time.Now()
+	var results, unreachable []ExecResult
+	for _, g := range groups {
+		if g.name != "" && *output != "json" {
+			fmt.Println(titleStyle.Render(fmt.Sprintf("%s (%d hosts)", g.name, len(g.hosts))))
+		}
+		var groupResults, groupUnreachable []ExecResult
+		if *preCheck {
+			groupResults, groupUnreachable = ExecuteWithPrecheck(g.hosts, command, ExecOptions{Concurrency: *concurrency, Timeout: *timeout, Done: done})
+		} else {
+			groupResults = RunOnHosts(g.hosts, command, *concurrency, done)
+		}
+		if *output == "table" {
+			fmt.Println(renderExecTable(groupResults))
+		}
+		results = append(results, groupResults...)
+		unreachable = append(unreachable, groupUnreachable...)
+	}
+	run := ExecReport{Command: command, Started: started, Elapsed: time.Since(started), Results: results}
+	if *output == "json" {
+		if err := writeExecReportJSON(os.Stdout, run); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	if *report != "" {
+		if err := saveExecReport(*report, run); err != nil {
+			fmt.Fprintln(os.Stderr, "failed to write the report:", err)
+			return 1
+		}
+		fmt.Fprintln(os.Stderr, "Wrote report to", *report)
+	}
+
+	if len(unreachable) > 0 {
+		fmt.Fprintf(os.Stderr, "Skipped %d unreachable hosts:\n", len(unreachable))
+		for _, r := range unreachable {
+			fmt.Fprintf(os.Stderr, "  %s: %v\n", r.Host, errors.Unwrap(r.Err))
+		}
+		return 1
+	}
+	for _, r := range results {
+		if r.ExitCode != 0 {
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ExecReport is a command run on several hosts, for exporting the results
+type ExecReport struct {
+	Command string
+	Started time.Time
+	Elapsed time.Duration
+	Results []ExecResult
+}
+
+// failed counts the hosts where the command didn't exit with 0
+func (r ExecReport) failed() int {
+	n := 0
+	for _, result := range r.Results {
+		if result.ExitCode != 0 {
+			n++
+		}
+	}
+	return n
+}
+
+func (r ExecResult) MarshalJSON() ([]byte, error) {
+	var errMsg string
+	if r.Err != nil {
+		errMsg = r.Err.Error()
+	}
+	return json.Marshal(struct {
+		Host      string `json:"host"`
+		ExitCode  int    `json:"exit_code"`
+		Stdout    string `json:"stdout"`
+		Stderr    string `json:"stderr"`
+		ElapsedMS int64  `json:"elapsed_ms"`
+		Error     string `json:"error,omitempty"`
+		Truncated bool   `json:"truncated,omitempty"`
+	}{r.Host, r.ExitCode, r.Stdout, r.Stderr, r.Elapsed.Milliseconds(), errMsg, r.Truncated})
+}
+
+func (r ExecReport) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Command   string       `json:"command"`
+		Started   time.Time    `json:"started"`
+		ElapsedMS int64        `json:"elapsed_ms"`
+		Failed    int          `json:"failed"`
+		Results   []ExecResult `json:"results"`
+	}{r.Command, r.Started, r.Elapsed.Milliseconds(), r.failed(), r.Results})
+}
+
+func writeExecReportJSON(w io.Writer, r ExecReport) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(r)
+}
+
+// writeExecReportText writes the report for reading: a summary and the full
+// output of every host in the order they were given
+func writeExecReportText(w io.Writer, r ExecReport) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Command: %s\n", r.Command)
+	fmt.Fprintf(&b, "Started: %s, took %s\n", r.Started.Format(time.DateTime), r.Elapsed.Round(time.Millisecond))
+	fmt.Fprintf(&b, "Hosts:   %d, %d failed\n", len(r.Results), r.failed())
+	for _, result := range r.Results {
+		fmt.Fprintf(&b, "\n== %s (exit %d, %s) ==\n", result.Host, result.ExitCode, result.Elapsed.Round(time.Millisecond))
+		if result.Err != nil {
+			fmt.Fprintf(&b, "error: %v\n", result.Err)
+		}
+		b.WriteString(result.Stdout)
+		if result.Stderr != "" {
+			b.WriteString("-- stderr --\n" + result.Stderr)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// saveExecReport writes r to path, as JSON if it ends in .json and as text
+// otherwise
+func saveExecReport(path string, r ExecReport) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = writeExecReportJSON(f, r)
+	} else {
+		err = writeExecReportText(f, r)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// execReportPath is where the TUI saves a report, in reports/ next to the
+// config
+func execReportPath(started time.Time) string {
+	return filepath.Join(filepath.Dir(configFilePath), "reports", "exec-"+started.Format("20060102-150405")+".json")
+}
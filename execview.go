@@ -0,0 +1,207 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// hosts a command started from the TUI runs on at the same time
+const execViewConcurrency = 10
+
+// execRun is a command run on the listed hosts from the TUI, started with X
+type execRun struct {
+	seq     int
+	hosts   []SSHHost
+	input   textinput.Model
+	running bool
+	report  *ExecReport
+	results viewport.Model
+	// folded sections of the results, only their header shows
+	foldFailed    bool
+	foldSucceeded bool
+}
+
+// sent when the command has finished on every host of the run with seq
+type execDoneMsg struct {
+	seq    int
+	report ExecReport
+}
+
+// openExecRun asks for the command to run on the visible hosts, all of
+// them or the ones matching the filter
+func (m *model) openExecRun() tea.Cmd {
+	hosts := m.visibleHosts()
+	if len(hosts) == 0 {
+		return nil
+	}
+	m.exec = execRun{seq: m.exec.seq + 1, hosts: hosts, input: textinput.New()}
+	m.exec.input.Prompt = "$ "
+	m.exec.input.Placeholder = "uptime"
+	m.view = execView
+	return m.exec.input.Focus()
+}
+
+func runExecReport(hosts []SSHHost, command string, seq int) tea.Cmd {
+	return func() tea.Msg {
+		started := time.Now()
+		results := RunOnHosts(hosts, command, execViewConcurrency, nil)
+		return execDoneMsg{seq: seq, report: ExecReport{Command: command, Started: started, Elapsed: time.Since(started), Results: results}}
+	}
+}
+
+func (m *model) updateExecRun(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case m.exec.running:
+		if msg.String() == "esc" {
+			// the sessions can't be taken back, only their results
+			m.exec.seq++
+			m.view = listView
+			return m.list.NewStatusMessage("Left the run, its results are discarded")
+		}
+		return nil
+
+	case m.exec.report == nil:
+		switch msg.String() {
+		case "esc":
+			m.view = listView
+			return nil
+		case "enter":
+			if m.exec.input.Value() == "" {
+				return nil
+			}
+			m.exec.running = true
+			m.exec.input.Blur()
+			return runExecReport(m.exec.hosts, m.exec.input.Value(), m.exec.seq)
+		}
+		var cmd tea.Cmd
+		m.exec.input, cmd = m.exec.input.Update(msg)
+		return cmd
+	}
+
+	switch msg.String() {
+	case "esc", "q":
+		m.view = listView
+		return nil
+	case "F":
+		m.exec.foldFailed = !m.exec.foldFailed
+		m.exec.results.SetContent(m.exec.renderResults())
+		return nil
+	case "S":
+		m.exec.foldSucceeded = !m.exec.foldSucceeded
+		m.exec.results.SetContent(m.exec.renderResults())
+		return nil
+	case "r":
+		return m.rerunFailed()
+	case "w":
+		path := execReportPath(m.exec.report.Started)
+		err := os.MkdirAll(filepath.Dir(path), 0o755)
+		if err == nil {
+			err = saveExecReport(path, *m.exec.report)
+		}
+		m.view = listView
+		if err != nil {
+			return m.list.NewStatusMessage(errorMessageStyle("Failed to write the report: " + err.Error()))
+		}
+		return m.list.NewStatusMessage(statusMessageStyle("Wrote report to " + path))
+	}
+	var cmd tea.Cmd
+	m.exec.results, cmd = m.exec.results.Update(msg)
+	return cmd
+}
+
+// showExecReport puts the summary table of a finished run in the viewport
+func (m *model) showExecReport(report ExecReport) {
+	m.exec.running = false
+	m.exec.report = &report
+	h, v := appStyle.GetFrameSize()
+	// title, command, summary and help lines
+	m.exec.results = viewport.New(max(m.width-h, 1), max(m.height-v-6, 1))
+	m.exec.results.SetContent(m.exec.renderResults())
+}
+
+// splitExecResults returns the results of the hosts that failed and the ones
+// that succeeded
+func splitExecResults(results []ExecResult) (failed, succeeded []ExecResult) {
+	for _, r := range results {
+		if r.ExitCode != 0 {
+			failed = append(failed, r)
+		} else {
+			succeeded = append(succeeded, r)
+		}
+	}
+	return failed, succeeded
+}
+
+// renderResults shows the failed hosts and then the ones that succeeded, each
+// under a header with their count
+func (e execRun) renderResults() string {
+	failed, succeeded := splitExecResults(e.report.Results)
+	var sections []string
+	section := func(title string, style func(...string) string, results []ExecResult, folded bool) {
+		if len(results) == 0 {
+			return
+		}
+		marker := "▾"
+		if folded {
+			marker = "▸"
+		}
+		header := style(fmt.Sprintf("%s %s (%d)", marker, title, len(results)))
+		if !folded {
+			header += "\n" + renderExecTable(results)
+		}
+		sections = append(sections, header)
+	}
+	section("Failed", errorMessageStyle, failed, e.foldFailed)
+	section("Succeeded", statusMessageStyle, succeeded, e.foldSucceeded)
+	return strings.Join(sections, "\n\n")
+}
+
+// rerunFailed runs the command of the finished run again on the hosts it
+// failed on
+func (m *model) rerunFailed() tea.Cmd {
+	failed, _ := splitExecResults(m.exec.report.Results)
+	if len(failed) == 0 {
+		return nil
+	}
+	var hosts []SSHHost
+	for _, h := range m.exec.hosts {
+		if slices.ContainsFunc(failed, func(r ExecResult) bool { return r.Host == h.Host }) {
+			hosts = append(hosts, h)
+		}
+	}
+	command := m.exec.report.Command
+	m.exec = execRun{seq: m.exec.seq + 1, hosts: hosts, input: textinput.New(), running: true}
+	m.exec.input.SetValue(command)
+	return runExecReport(hosts, command, m.exec.seq)
+}
+
+func (m model) execView() string {
+	title := titleStyle.Render(fmt.Sprintf("Run on %d hosts", len(m.exec.hosts)))
+	switch {
+	case m.exec.running:
+		return title + "\n\n$ " + m.exec.input.Value() + "\n\nRunning...\n\n" + checkFixStyle.Render("esc: leave")
+	case m.exec.report == nil:
+		return title + "\n\n" + m.exec.input.View() + "\n\n" + checkFixStyle.Render("enter: run • esc: cancel")
+	}
+	r := m.exec.report
+	summary := fmt.Sprintf("%d of %d hosts failed, took %s", r.failed(), len(r.Results), r.Elapsed.Round(time.Millisecond))
+	if r.failed() > 0 {
+		summary = errorMessageStyle(summary)
+	} else {
+		summary = statusMessageStyle(summary)
+	}
+	help := "j/k: scroll • F/S: fold failed/succeeded • w: write full report • esc: close"
+	if r.failed() > 0 {
+		help = "j/k: scroll • F/S: fold failed/succeeded • r: re-run failed • w: write full report • esc: close"
+	}
+	return title + "\n\n$ " + r.Command + "\n" + summary + "\n" + m.exec.results.View() + "\n" +
+		checkFixStyle.Render(help)
+}
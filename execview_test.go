@@ -0,0 +1,35 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestOpenExecRunFiltered(t *testing.T) {
+	m := newTestModel(t, bulkTestConfig)
+	m.list.SetFilterText("web")
+	m.openExecRun()
+	if got := aliasesOf(m.exec.hosts); !slices.Equal(got, []string{"web1", "web2"}) {
+		t.Errorf("running on %v, want [web1 web2]", got)
+	}
+}
+
+func TestRerunFailed(t *testing.T) {
+	m := newTestModel(t, bulkTestConfig)
+	m.list.SetFilterText("web")
+	m.openExecRun()
+	m.showExecReport(ExecReport{Command: "true", Results: []ExecResult{
+		{Host: "web1"},
+		{Host: "web2", ExitCode: 255, Err: errors.New("connection refused")},
+	}})
+	if m.rerunFailed() == nil {
+		t.Fatal("nothing rerun")
+	}
+	if got := aliasesOf(m.exec.hosts); !slices.Equal(got, []string{"web2"}) {
+		t.Errorf("rerunning on %v, want [web2]", got)
+	}
+	if !m.exec.running || m.exec.input.Value() != "true" {
+		t.Errorf("running = %v with command %q", m.exec.running, m.exec.input.Value())
+	}
+}
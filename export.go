@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// FormatHostsJSON encodes the hosts as a compact JSON array on one line.
+// Fields keep the order of SSHHost, empty optional ones are left out.
+func FormatHostsJSON(hosts []SSHHost) ([]byte, error) {
+	if hosts == nil {
+		hosts = []SSHHost{}
+	}
+	return json.Marshal(hosts)
+}
+
+// runJSON prints the hosts of the config as JSON for -json
+func runJSON() int {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load config:", err)
+		return 1
+	}
+	out, err := FormatHostsJSON(config.Hosts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to encode hosts:", err)
+		return 1
+	}
+	fmt.Println(string(out))
+	return 0
+}
+
+func runExport(args []string) int {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	format := fs.String("format", "openssh", "output format: openssh, inventory-json, wireguard")
+	output := fs.String("output", "", "write to this file instead of stdout")
+	fs.Parse(args)
+
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load config:", err)
+		return 1
+	}
+
+	var out string
+	switch *format {
+	case "openssh":
+		out = FormatOpenSSHConfig(config.Hosts)
+	case "inventory-json":
+		inventory, err := FormatAnsibleInventory(config.Hosts)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "failed to encode inventory:", err)
+			return 1
+		}
+		out = string(inventory) + "\n"
+	case "wireguard":
+		out, err = FormatWireGuardPeers(config.Hosts)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "failed to resolve the peer addresses:", err)
+			return 1
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "unknown format %q\n", *format)
+		return 2
+	}
+
+	if *output == "" {
+		fmt.Print(out)
+		return 0
+	}
+	if err := os.WriteFile(*output, []byte(out), 0o600); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write export:", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFormatHostsJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		hosts []SSHHost
+		want  string
+	}{
+		{"no hosts", nil, `[]`},
+		{"empty fields left out", []SSHHost{{Host: "web1", HostName: "10.0.0.1"}}, `[{"host":"web1","hostname":"10.0.0.1"}]`},
+		{
+			"fields in the order of SSHHost",
+			[]SSHHost{{Host: "web1", HostName: "10.0.0.1", User: "deploy", Tags: []string{"prod"}, Port: 2222, Options: map[string]string{"b": "2", "a": "1"}}},
+			`[{"host":"web1","hostname":"10.0.0.1","user":"deploy","tags":["prod"],"port":2222,"options":{"a":"1","b":"2"}}]`,
+		},
+		{"hosts from ssh_config look the same", []SSHHost{{Host: "db", fromSSHConfig: true}}, `[{"host":"db","hostname":""}]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := FormatHostsJSON(tt.hosts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(out) != tt.want {
+				t.Errorf("got  %s\nwant %s", out, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatHostsJSONValid(t *testing.T) {
+	hosts := syntheticConfig(50).Hosts
+	hosts[3].Desc = "quotes \" and\nnewlines"
+	hosts[4].Deploy = &DeployConfig{}
+	out, err := FormatHostsJSON(hosts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !json.Valid(out) || strings.Contains(string(out), "\n") {
+		t.Fatalf("not valid JSON on one line: %s", out)
+	}
+	var decoded []SSHHost
+	if err := json.Unmarshal(out, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, hosts) {
+		t.Error("decoding the output doesn't give the hosts back")
+	}
+}
@@ -0,0 +1,367 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	formLabelStyle  = lipgloss.NewStyle().Width(16)
+	formButtonStyle = lipgloss.NewStyle().Padding(0, 2).Border(lipgloss.RoundedBorder())
+	formFocusStyle  = formButtonStyle.BorderForeground(lipgloss.Color("#25A065")).Foreground(lipgloss.Color("#25A065")).Bold(true)
+)
+
+// fields of the host form, in display order
+const (
+	fieldAlias = iota
+	fieldHostName
+	fieldUser
+	fieldPort
+	fieldIdentityFile
+	fieldCertificateFile
+	fieldProxyJump
+	fieldAuth
+	fieldTags
+	fieldDescription
+	fieldCount
+)
+
+var formLabels = [fieldCount]string{"Alias", "HostName", "User", "Port", "IdentityFile", "CertificateFile", "ProxyJump", "Auth", "Tags", "Description"}
+
+// choices of the auth field, cycled with space or the arrow keys
+var authModes = []string{"any", "pubkey only", "password only"}
+
+// authLabel names the auth methods h is limited to, empty if it isn't
+func (h SSHHost) authLabel() string {
+	switch {
+	case h.PubkeyOnly:
+		return authModes[1]
+	case h.PasswordOnly:
+		return authModes[2]
+	}
+	return ""
+}
+
+// what the form asks the model to do after a key press
+type formResult int
+
+const (
+	formEditing formResult = iota
+	formSaved
+	formCancelled
+)
+
+// hostForm edits all the common fields of a host at once. Fields the form
+// doesn't show are kept from the host it was opened with.
+type hostForm struct {
+	// index in m.hosts of the edited host, -1 for a new one
+	index    int
+	original SSHHost
+	// aliases of the other hosts, an alias must stay unique
+	taken []string
+	// tags of all hosts, suggested while typing in the tags field
+	tags   []string
+	inputs [fieldCount]textinput.Model
+	// a field index, or fieldCount and fieldCount+1 for Save and Cancel
+	focus int
+	// errors of empty fields only show once saving was tried
+	tried bool
+	// editing a host template, the alias field holds its name
+	template bool
+}
+
+const (
+	focusSave   = fieldCount
+	focusCancel = fieldCount + 1
+)
+
+func newHostForm(h SSHHost, index int, taken, tags []string) hostForm {
+	f := hostForm{index: index, original: h, taken: taken, tags: tags}
+	values := [fieldCount]string{h.Host, h.HostName, h.User, "", h.IdentityFile, h.CertificateFile, h.ProxyJump, cmp.Or(h.authLabel(), authModes[0]), strings.Join(h.Tags, ", "), h.Desc}
+	if h.Port != 0 {
+		values[fieldPort] = strconv.Itoa(h.Port)
+	}
+	for i := range f.inputs {
+		input := textinput.New()
+		input.Prompt = ""
+		input.Width = 40
+		input.SetValue(values[i])
+		f.inputs[i] = input
+	}
+	f.inputs[fieldTags].Placeholder = "comma separated"
+	f.inputs[fieldPort].Placeholder = "22"
+	f.inputs[fieldAlias].Focus()
+	return f
+}
+
+// validate checks one field, the error is shown below it
+func (f hostForm) validate(field int) error {
+	value := strings.TrimSpace(f.inputs[field].Value())
+	switch field {
+	case fieldAlias:
+		if value == "" {
+			return errors.New("required")
+		}
+		if strings.ContainsAny(value, " \t") {
+			return errors.New("must not contain spaces")
+		}
+		if slices.Contains(f.taken, value) && f.template {
+			return errors.New("another template already uses this name")
+		}
+		if slices.Contains(f.taken, value) {
+			return errors.New("another host already uses this alias")
+		}
+	case fieldPort:
+		if value == "" {
+			return nil
+		}
+		if port, err := strconv.Atoi(value); err != nil || port < 1 || port > 65535 {
+			return errors.New("must be a number from 1 to 65535")
+		}
+	case fieldIdentityFile:
+		if value == "" {
+			return nil
+		}
+		if _, err := os.Stat(expandPath(value)); err != nil {
+			return errors.New("file not found")
+		}
+	}
+	return nil
+}
+
+func (f hostForm) valid() bool {
+	for i := range fieldCount {
+		if f.validate(i) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// host returns the original host with the form's values applied
+func (f hostForm) host() SSHHost {
+	h := f.original
+	value := func(field int) string { return strings.TrimSpace(f.inputs[field].Value()) }
+	h.Host = value(fieldAlias)
+	h.HostName = value(fieldHostName)
+	h.User = value(fieldUser)
+	h.Port, _ = strconv.Atoi(value(fieldPort))
+	h.IdentityFile = value(fieldIdentityFile)
+	h.CertificateFile = value(fieldCertificateFile)
+	h.ProxyJump = value(fieldProxyJump)
+	h.PubkeyOnly = value(fieldAuth) == authModes[1]
+	h.PasswordOnly = value(fieldAuth) == authModes[2]
+	h.Desc = value(fieldDescription)
+	h.Tags = nil
+	for _, tag := range strings.Split(value(fieldTags), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			h.Tags = append(h.Tags, tag)
+		}
+	}
+	return h
+}
+
+func (f *hostForm) setFocus(focus int) tea.Cmd {
+	if f.focus < fieldCount {
+		f.inputs[f.focus].Blur()
+	}
+	f.focus = (focus + fieldCount + 2) % (fieldCount + 2)
+	if f.focus < fieldCount {
+		return f.inputs[f.focus].Focus()
+	}
+	return nil
+}
+
+// tagSuggestions are the existing tags matching what is typed in the tags
+// field while it has the focus
+func (f hostForm) tagSuggestions() []string {
+	if f.focus != fieldTags {
+		return nil
+	}
+	return suggestTags(f.tags, f.inputs[fieldTags].Value())
+}
+
+// update moves between the fields with tab and shift+tab, enter on Save
+// submits once every field is valid. In the tags field tab completes the
+// first suggestion instead, if there is one.
+func (f *hostForm) update(msg tea.KeyMsg) (formResult, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		return formCancelled, nil
+	case "tab":
+		if suggestions := f.tagSuggestions(); len(suggestions) > 0 {
+			f.inputs[fieldTags].SetValue(completeTag(f.inputs[fieldTags].Value(), suggestions[0]) + ", ")
+			f.inputs[fieldTags].CursorEnd()
+			return formEditing, nil
+		}
+		return formEditing, f.setFocus(f.focus + 1)
+	case "down":
+		return formEditing, f.setFocus(f.focus + 1)
+	case "shift+tab", "up":
+		return formEditing, f.setFocus(f.focus - 1)
+	case "enter":
+		switch f.focus {
+		case focusCancel:
+			return formCancelled, nil
+		case focusSave:
+			if f.valid() {
+				return formSaved, nil
+			}
+			f.tried = true
+			for i := range fieldCount {
+				if f.validate(i) != nil {
+					return formEditing, f.setFocus(i)
+				}
+			}
+		}
+		return formEditing, f.setFocus(f.focus + 1)
+	}
+	if f.focus >= fieldCount {
+		return formEditing, nil
+	}
+	if f.focus == fieldAuth {
+		f.cycleAuth(msg.String())
+		return formEditing, nil
+	}
+	var cmd tea.Cmd
+	f.inputs[f.focus], cmd = f.inputs[f.focus].Update(msg)
+	return formEditing, cmd
+}
+
+// cycleAuth moves the auth field to the next choice on space or right and
+// back on left, it can't be typed into
+func (f *hostForm) cycleAuth(key string) {
+	step := 0
+	switch key {
+	case " ", "right":
+		step = 1
+	case "left":
+		step = len(authModes) - 1
+	}
+	i := slices.Index(authModes, f.inputs[fieldAuth].Value())
+	f.inputs[fieldAuth].SetValue(authModes[(max(i, 0)+step)%len(authModes)])
+}
+
+func (f hostForm) view() string {
+	title := "Add host"
+	switch {
+	case f.template && f.original.Host != "":
+		title = "Edit template " + f.original.Host
+	case f.template:
+		title = "Add template"
+	case f.index >= 0:
+		title = "Edit " + f.original.Host
+	}
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(title) + "\n\n")
+	for i := range fieldCount {
+		label := formLabels[i]
+		if i == fieldAlias && f.template {
+			label = "Name"
+		}
+		if i == f.focus {
+			label = selectedOptionStyle.Render("> " + label)
+		} else {
+			label = "  " + label
+		}
+		b.WriteString(formLabelStyle.Render(label) + f.inputs[i].View() + "\n")
+		if err := f.validate(i); err != nil && (f.tried || f.inputs[i].Value() != "") {
+			b.WriteString(formLabelStyle.Render("") + errorMessageStyle(err.Error()) + "\n")
+		}
+		if i == fieldAuth && f.focus == fieldAuth {
+			b.WriteString(formLabelStyle.Render("") + checkFixStyle.Render("space or ←/→ to change") + "\n")
+		}
+		if i == fieldTags {
+			for j, tag := range f.tagSuggestions() {
+				if j == 0 {
+					tag = selectedOptionStyle.Render(tag) + checkFixStyle.Render("  tab")
+				} else {
+					tag = checkFixStyle.Render(tag)
+				}
+				b.WriteString(formLabelStyle.Render("") + tag + "\n")
+			}
+		}
+	}
+
+	save, cancel := formButtonStyle, formButtonStyle
+	if f.focus == focusSave {
+		save = formFocusStyle
+	}
+	if f.focus == focusCancel {
+		cancel = formFocusStyle
+	}
+	b.WriteString("\n" + lipgloss.JoinHorizontal(lipgloss.Top, save.Render("Save"), " ", cancel.Render("Cancel")) + "\n\n")
+	b.WriteString(checkFixStyle.Render("tab/shift+tab: move • enter: next field or press button • esc: cancel"))
+	return b.String()
+}
+
+// openForm shows the form for the host at index, or for a new host if index
+// is -1
+func (m *model) openForm(index int) tea.Cmd {
+	var h SSHHost
+	if index >= 0 {
+		h = m.listedHosts()[index]
+	}
+	m.form = newHostForm(h, index, m.otherAliases(index), allTags(m.hosts))
+	m.view = formView
+	return nil
+}
+
+// openNewHostForm shows the form for a new host starting with the values of h
+func (m *model) openNewHostForm(h SSHHost) tea.Cmd {
+	m.form = newHostForm(h, -1, m.otherAliases(-1), allTags(m.hosts))
+	m.view = formView
+	return nil
+}
+
+// otherAliases are the aliases of all listed hosts but the one at index
+func (m model) otherAliases(index int) []string {
+	var taken []string
+	for i, other := range m.listedHosts() {
+		if i != index {
+			taken = append(taken, other.Host)
+		}
+	}
+	return taken
+}
+
+// updateForm passes keys to the form and commits the host once it is saved
+func (m *model) updateForm(msg tea.KeyMsg) tea.Cmd {
+	result, cmd := m.form.update(msg)
+	if m.form.template {
+		switch result {
+		case formCancelled:
+			m.openTemplates()
+			return nil
+		case formSaved:
+			return m.saveTemplate(m.form.host())
+		}
+		return cmd
+	}
+	switch result {
+	case formCancelled:
+		m.view = listView
+		return nil
+	case formSaved:
+		m.view = listView
+		h := m.form.host()
+		if m.form.index < 0 {
+			return tea.Batch(m.commit(edit{index: len(m.hosts), after: &h}),
+				m.list.NewStatusMessage(statusMessageStyle("Added "+h.Host)))
+		}
+		before := m.hosts[m.form.index]
+		return tea.Batch(m.commit(edit{index: m.form.index, before: &before, after: &h}),
+			m.list.NewStatusMessage(statusMessageStyle(fmt.Sprintf("Saved %s", h.Host))))
+	}
+	return cmd
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// sockets younger than this are left alone, their master may still be
+	// starting up
+	socketMinAge = 24 * time.Hour
+	// used when retention_days is not set
+	defaultRetentionDays = 30
+)
+
+func (s Settings) retentionDays() int {
+	if s.RetentionDays <= 0 {
+		return defaultRetentionDays
+	}
+	return s.RetentionDays
+}
+
+// logDir is where quickssh writes its logs, logs/ next to the config file if
+// log_dir is not set
+func (s Settings) logDir() string {
+	if s.LogDir != "" {
+		return expandPath(s.LogDir)
+	}
+	return filepath.Join(filepath.Dir(configFilePath), "logs")
+}
+
+// isControlSocket matches the usual names of ControlPath sockets, including
+// the ones of multiplexed hosts
+func isControlSocket(name string) bool {
+	return strings.HasSuffix(name, ".sock") || strings.HasPrefix(name, ".cm_") || strings.HasPrefix(name, "quickssh-")
+}
+
+// GCSocketFiles returns the ControlMaster sockets in ~/.ssh that are older
+// than a day and have no master behind them anymore
+func GCSocketFiles() ([]string, error) {
+	dir := expandPath("~/.ssh")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var stale []string
+	for _, e := range entries {
+		if !isControlSocket(e.Name()) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || info.Mode()&os.ModeSocket == 0 || time.Since(info.ModTime()) < socketMinAge {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		// the destination is ignored, ssh only talks to the socket
+		if exec.Command(sshBinary, "-S", path, "-O", "check", "quickssh-gc").Run() == nil {
+			continue
+		}
+		stale = append(stale, path)
+	}
+	return stale, nil
+}
+
+// GCLogFiles returns the .log files in dir last written more than maxAgeDays
+// ago. A missing dir has nothing to clean up.
+func GCLogFiles(dir string, maxAgeDays int) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -maxAgeDays)
+	var old []string
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".log" {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			continue
+		}
+		old = append(old, filepath.Join(dir, e.Name()))
+	}
+	return old, nil
+}
+
+func runGC(args []string) int {
+	fs := flag.NewFlagSet("gc", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "only list what would be deleted")
+	fs.Parse(args)
+
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load config:", err)
+		return 1
+	}
+
+	sockets, err := GCSocketFiles()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to scan ~/.ssh:", err)
+		return 1
+	}
+	logs, err := GCLogFiles(config.Settings.logDir(), config.Settings.retentionDays())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to scan the log directory:", err)
+		return 1
+	}
+
+	verb := "Deleted"
+	if *dryRun {
+		verb = "Would delete"
+	}
+	var reclaimed int64
+	removed := map[bool]int{}
+	failed := false
+	for _, path := range append(sockets, logs...) {
+		info, err := os.Lstat(path)
+		if err != nil {
+			continue
+		}
+		if !*dryRun {
+			if err := os.Remove(path); err != nil {
+				fmt.Fprintln(os.Stderr, "failed to delete:", err)
+				failed = true
+				continue
+			}
+		}
+		fmt.Println(verb, path)
+		reclaimed += info.Size()
+		removed[filepath.Ext(path) == ".log"]++
+	}
+	fmt.Printf("%s %d sockets and %d log files, %s\n", verb, removed[false], removed[true], formatBytes(reclaimed))
+	if failed {
+		return 1
+	}
+	return 0
+}
+
+// formatBytes prints n with a binary unit, e.g. 1.5 MiB
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// the selector entries that aren't group names
+const (
+	noGroupOption  = "(no group)"
+	newGroupOption = "+ new group"
+)
+
+// groupMover picks the group the selected host moves to, or a new one typed
+// into name
+type groupMover struct {
+	index    int
+	host     string
+	selector selector
+	name     textinput.Model
+	creating bool
+}
+
+// groups returns the names of all groups in use, sorted
+func groups(hosts []SSHHost) []string {
+	var names []string
+	for _, h := range hosts {
+		if h.Group != "" && !slices.Contains(names, h.Group) {
+			names = append(names, h.Group)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// openGroupMover shows the group selector for the host at index
+func (m *model) openGroupMover(index int) {
+	h := m.hosts[index]
+	var options []string
+	for _, g := range groups(m.hosts) {
+		if g != h.Group {
+			options = append(options, g)
+		}
+	}
+	if h.Group != "" {
+		options = append(options, noGroupOption)
+	}
+	options = append(options, newGroupOption)
+	m.group = groupMover{
+		index:    index,
+		host:     h.Host,
+		selector: newSelector("Move "+h.Host+" to", options),
+	}
+	m.view = moveGroupView
+}
+
+func (m *model) updateGroupMover(msg tea.KeyMsg) tea.Cmd {
+	if m.group.creating {
+		switch msg.String() {
+		case "esc":
+			m.group.creating = false
+			return nil
+		case "enter":
+			name := strings.TrimSpace(m.group.name.Value())
+			if name == "" {
+				return nil
+			}
+			return m.moveToGroup(name)
+		}
+		var cmd tea.Cmd
+		m.group.name, cmd = m.group.name.Update(msg)
+		return cmd
+	}
+
+	choice, done := m.group.selector.update(msg)
+	switch {
+	case !done:
+		return nil
+	case choice == "":
+		m.view = listView
+		return nil
+	case choice == newGroupOption:
+		m.group.name = textinput.New()
+		m.group.name.Prompt = "group: "
+		m.group.creating = true
+		return m.group.name.Focus()
+	case choice == noGroupOption:
+		return m.moveToGroup("")
+	}
+	return m.moveToGroup(choice)
+}
+
+// moveToGroup changes the group of the host as an undoable edit, everything
+// else about the host stays as it is
+func (m *model) moveToGroup(group string) tea.Cmd {
+	m.view = listView
+	before := m.hosts[m.group.index]
+	if before.Group == group {
+		return m.list.NewStatusMessage(before.Host + " is already in " + group)
+	}
+	after := before
+	after.Group = group
+	cmd := m.commit(edit{index: m.group.index, before: &before, after: &after})
+	status := "Moved " + before.Host + " to " + group
+	if group == "" {
+		status = "Removed " + before.Host + " from " + before.Group
+	}
+	return tea.Batch(cmd, m.list.NewStatusMessage(statusMessageStyle(status+", press s to save")))
+}
+
+func (m model) groupMoverView() string {
+	if !m.group.creating {
+		return m.group.selector.view()
+	}
+	return titleStyle.Render("Move "+m.group.host+" to a new group") + "\n\n" +
+		m.group.name.View() + "\n\n" +
+		checkFixStyle.Render("enter: move • esc: back")
+}
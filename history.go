@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// ConnectionEvent is one finished connection, appended to the history file
+type ConnectionEvent struct {
+	Host     string    `json:"host"`
+	Time     time.Time `json:"time"`
+	ExitCode int       `json:"exit_code"`
+}
+
+func historyFilePath() string {
+	return filepath.Join(filepath.Dir(configFilePath), "history.jsonl")
+}
+
+func appendHistory(event ConnectionEvent) error {
+	f, err := os.OpenFile(historyFilePath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return json.NewEncoder(f).Encode(event)
+}
+
+// loadHistory returns all recorded connections, oldest first. A missing
+// history file is not an error.
+func loadHistory() ([]ConnectionEvent, error) {
+	f, err := os.Open(historyFilePath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var events []ConnectionEvent
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var event ConnectionEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			// skip lines from an interrupted write
+			continue
+		}
+		events = append(events, event)
+	}
+	return events, scanner.Err()
+}
+
+// lastConnected maps each host alias to its most recent connection
+func lastConnected(events []ConnectionEvent) map[string]time.Time {
+	last := make(map[string]time.Time)
+	for _, e := range events {
+		if e.Time.After(last[e.Host]) {
+			last[e.Host] = e.Time
+		}
+	}
+	return last
+}
+
+// newConnectionEvent records the outcome of an ssh process
+func newConnectionEvent(h SSHHost, err error) ConnectionEvent {
+	event := ConnectionEvent{Host: h.Host, Time: time.Now()}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		event.ExitCode = exitErr.ExitCode()
+	} else if err != nil {
+		event.ExitCode = -1
+	}
+	return event
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// used when hook_timeout is not set
+const defaultHookTimeout = 30 * time.Second
+
+// hookVars are the placeholders of a hook command and their values for h.
+// exit_code is -1 before the connection.
+func hookVars(h SSHHost, exitCode int) map[string]string {
+	return map[string]string{
+		"host":      h.Host,
+		"hostname":  h.address(),
+		"user":      h.User,
+		"port":      strconv.Itoa(h.port()),
+		"tags":      strings.Join(h.Tags, ","),
+		"exit_code": strconv.Itoa(exitCode),
+	}
+}
+
+// expandHook replaces the {name} placeholders of command. The values are
+// quoted for sh so host fields can't inject commands, on Windows they are
+// inserted as they are.
+func expandHook(command string, vars map[string]string) string {
+	pairs := make([]string, 0, 2*len(vars))
+	for name, value := range vars {
+		if runtime.GOOS != "windows" {
+			value = shellQuote(value)
+		}
+		pairs = append(pairs, "{"+name+"}", value)
+	}
+	return strings.NewReplacer(pairs...).Replace(command)
+}
+
+// runHook runs a pre_connect or post_connect command through the shell. The
+// variables are also passed in the environment as QUICKSSH_<NAME>.
+func runHook(command string, h SSHHost, exitCode int, settings Settings, stdout, stderr io.Writer) error {
+	if command == "" {
+		return nil
+	}
+	timeout := defaultHookTimeout
+	if settings.HookTimeout > 0 {
+		timeout = time.Duration(settings.HookTimeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := hookCommand(ctx, command, hookVars(h, exitCode))
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+
+	err := cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %s", timeout)
+	}
+	return err
+}
+
+// hookCommand runs command through the shell with its placeholders expanded
+// and the variables in the environment
+func hookCommand(ctx context.Context, command string, vars map[string]string) *exec.Cmd {
+	cmd := shellCommand(ctx, expandHook(command, vars))
+	cmd.Env = os.Environ()
+	for name, value := range vars {
+		cmd.Env = append(cmd.Env, "QUICKSSH_"+strings.ToUpper(name)+"="+value)
+	}
+	return cmd
+}
+
+// shellCommand runs command with sh, or cmd on Windows
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
+
+// exitCode returns the exit code of a finished process, -1 if it couldn't
+// be run
+func exitCode(err error) int {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	if err != nil {
+		return -1
+	}
+	return 0
+}
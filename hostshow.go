@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+)
+
+// HostDetails is what host show prints about a host: its fields, how ssh is
+// run for it and what is known about connecting to it
+type HostDetails struct {
+	SSHHost
+	// Command is the ssh command line of an interactive session
+	Command string
+	// Reachable tells whether the ssh port answered, with the time it took
+	// or the error
+	Reachable bool
+	Latency   time.Duration
+	DialErr   error
+	// LastConnection is the latest entry of the history, nil if there is none
+	LastConnection *ConnectionEvent
+}
+
+// hostDetails collects the details of h, dialing its ssh port with timeout
+func hostDetails(h SSHHost, history []ConnectionEvent, timeout time.Duration) HostDetails {
+	d := HostDetails{SSHHost: h}
+	var args []string
+	for _, arg := range BuildSSHCommand(h).Args {
+		args = append(args, shellQuote(arg))
+	}
+	d.Command = strings.Join(args, " ")
+	d.Latency, d.DialErr = checkReachable(context.Background(), h, timeout)
+	d.Reachable = d.DialErr == nil
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Host == h.Host {
+			d.LastConnection = &history[i]
+			break
+		}
+	}
+	return d
+}
+
+func (d HostDetails) MarshalJSON() ([]byte, error) {
+	var dialErr string
+	if d.DialErr != nil {
+		dialErr = d.DialErr.Error()
+	}
+	return json.Marshal(struct {
+		SSHHost
+		Command        string           `json:"command"`
+		Reachable      bool             `json:"reachable"`
+		LatencyMS      int64            `json:"latency_ms,omitempty"`
+		DialError      string           `json:"dial_error,omitempty"`
+		LastConnection *ConnectionEvent `json:"last_connection,omitempty"`
+	}{d.SSHHost, d.Command, d.Reachable, d.Latency.Milliseconds(), dialErr, d.LastConnection})
+}
+
+// hostFields returns the set fields of h by their config name, in the order
+// of SSHHost
+func hostFields(h SSHHost) [][2]string {
+	var fields [][2]string
+	v := reflect.ValueOf(h)
+	for i := range v.NumField() {
+		f := v.Type().Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("toml"), ",")
+		if name == "" || v.Field(i).IsZero() {
+			continue
+		}
+		fields = append(fields, [2]string{name, formatField(v.Field(i))})
+	}
+	return fields
+}
+
+func formatField(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Bool:
+		return "yes"
+	case reflect.Slice:
+		return strings.Join(v.Interface().([]string), ", ")
+	case reflect.Map:
+		options := v.Interface().(map[string]string)
+		var pairs []string
+		for _, key := range slices.Sorted(maps.Keys(options)) {
+			pairs = append(pairs, key+"="+options[key])
+		}
+		return strings.Join(pairs, ", ")
+	case reflect.Pointer:
+		// the [deploy] table, printed like in the config
+		data, _ := json.Marshal(v.Interface())
+		return string(data)
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// writeHostDetails prints d as aligned name and value lines
+func writeHostDetails(w io.Writer, d HostDetails, now time.Time) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, field := range hostFields(d.SSHHost) {
+		fmt.Fprintf(tw, "%s\t%s\n", field[0], field[1])
+	}
+	fmt.Fprintf(tw, "command\t%s\n", d.Command)
+	if d.Reachable {
+		fmt.Fprintf(tw, "reachable\tyes (%s)\n", d.Latency.Round(time.Millisecond))
+	} else {
+		fmt.Fprintf(tw, "reachable\tno (%v)\n", d.DialErr)
+	}
+	if e := d.LastConnection; e != nil {
+		fmt.Fprintf(tw, "last connected\t%s (%s), exit code %d\n", e.Time.Local().Format(time.DateTime), relativeTime(e.Time, now), e.ExitCode)
+	} else {
+		fmt.Fprintln(tw, "last connected\tnever")
+	}
+	return tw.Flush()
+}
+
+func runHost(args []string) int {
+	if len(args) == 0 || args[0] != "show" {
+		fmt.Fprintln(os.Stderr, "usage: quickssh host show <alias> [--json | --format template] [--timeout 3s]")
+		return 2
+	}
+	return runHostShow(args[1:])
+}
+
+func runHostShow(args []string) int {
+	fs := flag.NewFlagSet("host show", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "print the details as JSON")
+	format := fs.String("format", "", `Go template to print instead, e.g. "{{.HostName}}"`)
+	timeout := fs.Duration("timeout", 3*time.Second, "how long to wait for the ssh port")
+	fs.Parse(args)
+	// the alias may come before the flags
+	query := fs.Arg(0)
+	if query != "" {
+		fs.Parse(fs.Args()[1:])
+	}
+
+	if query == "" || fs.NArg() > 0 || *asJSON && *format != "" {
+		fmt.Fprintln(os.Stderr, "usage: quickssh host show <alias> [--json | --format template] [--timeout 3s]")
+		return 2
+	}
+	var tmpl *template.Template
+	if *format != "" {
+		var err error
+		if tmpl, err = template.New("format").Parse(*format); err != nil {
+			fmt.Fprintln(os.Stderr, "invalid --format:", err)
+			return 2
+		}
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load config:", err)
+		return 1
+	}
+	hosts, err := listedHosts(config)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to read ~/.ssh/config:", err)
+	}
+	h, candidates, err := matchAlias(query, hosts)
+	if errors.Is(err, errAmbiguousAlias) {
+		fmt.Fprintf(os.Stderr, "%q matches several hosts:\n", query)
+		for _, c := range candidates {
+			fmt.Fprintln(os.Stderr, "  "+c.Host)
+		}
+		return 1
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	history, err := loadHistory()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to read the history:", err)
+	}
+	details := hostDetails(h, history, *timeout)
+
+	switch {
+	case *asJSON:
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(details)
+	case tmpl != nil:
+		if err = tmpl.Execute(os.Stdout, details); err == nil && !strings.HasSuffix(*format, "\n") {
+			fmt.Println()
+		}
+	default:
+		err = writeHostDetails(os.Stdout, details, time.Now())
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
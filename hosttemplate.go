@@ -0,0 +1,133 @@
+package main
+
+import (
+	"maps"
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// HostTemplate holds the values a new host made from it starts with, from
+// the [templates] table
+type HostTemplate struct {
+	HostName        string            `toml:"hostname,omitempty"`
+	User            string            `toml:"user,omitempty"`
+	Port            int               `toml:"port,omitempty"`
+	IdentityFile    string            `toml:"identity_file,omitempty"`
+	CertificateFile string            `toml:"certificate_file,omitempty"`
+	ProxyJump       string            `toml:"proxy_jump,omitempty"`
+	PubkeyOnly      bool              `toml:"pubkey_only,omitempty"`
+	PasswordOnly    bool              `toml:"password_only,omitempty"`
+	Tags            []string          `toml:"tags,omitempty"`
+	Desc            string            `toml:"description,omitempty"`
+	Options         map[string]string `toml:"options,omitempty"`
+}
+
+// host is a new host with the template's values, the alias is left empty
+func (t HostTemplate) host() SSHHost {
+	return SSHHost{
+		HostName:        t.HostName,
+		User:            t.User,
+		Port:            t.Port,
+		IdentityFile:    t.IdentityFile,
+		CertificateFile: t.CertificateFile,
+		ProxyJump:       t.ProxyJump,
+		PubkeyOnly:      t.PubkeyOnly,
+		PasswordOnly:    t.PasswordOnly,
+		Tags:            slices.Clone(t.Tags),
+		Desc:            t.Desc,
+		Options:         maps.Clone(t.Options),
+	}
+}
+
+func templateFrom(h SSHHost) HostTemplate {
+	return HostTemplate{
+		HostName:        h.HostName,
+		User:            h.User,
+		Port:            h.Port,
+		IdentityFile:    h.IdentityFile,
+		CertificateFile: h.CertificateFile,
+		ProxyJump:       h.ProxyJump,
+		PubkeyOnly:      h.PubkeyOnly,
+		PasswordOnly:    h.PasswordOnly,
+		Tags:            h.Tags,
+		Desc:            h.Desc,
+		Options:         h.Options,
+	}
+}
+
+// first option of the template picker, a host without template values
+const blankTemplate = "(blank)"
+
+// openTemplates shows the templates to start a new host from
+func (m *model) openTemplates() {
+	options := append([]string{blankTemplate}, slices.Sorted(maps.Keys(m.templates))...)
+	cursor := min(m.selector.cursor, len(options)-1)
+	m.selector = newSelector("New host from template", options)
+	if m.view != listView {
+		// back from editing or deleting a template
+		m.selector.cursor = cursor
+	}
+	m.view = templatesView
+}
+
+// updateTemplates opens the form for a new host with the chosen template's
+// values on enter. e edits the template, n adds one and d deletes it.
+func (m *model) updateTemplates(msg tea.KeyMsg) tea.Cmd {
+	current := m.selector.options[m.selector.cursor]
+	switch msg.String() {
+	case "n":
+		return m.openTemplateForm("", HostTemplate{})
+	case "e":
+		if current != blankTemplate {
+			return m.openTemplateForm(current, m.templates[current])
+		}
+		return nil
+	case "d":
+		if current == blankTemplate {
+			return nil
+		}
+		delete(m.templates, current)
+		m.openTemplates()
+		return tea.Batch(m.scheduleAutosave(), m.list.NewStatusMessage(statusMessageStyle("Deleted template "+current)))
+	}
+
+	choice, done := m.selector.update(msg)
+	if !done {
+		return nil
+	}
+	m.view = listView
+	if choice == "" {
+		return nil
+	}
+	return m.openNewHostForm(m.templates[choice].host())
+}
+
+// openTemplateForm edits the template name in the host form, a new one if
+// name is empty
+func (m *model) openTemplateForm(name string, t HostTemplate) tea.Cmd {
+	var taken []string
+	for other := range m.templates {
+		if other != name {
+			taken = append(taken, other)
+		}
+	}
+	h := t.host()
+	h.Host = name
+	m.form = newHostForm(h, -1, taken, allTags(m.hosts))
+	m.form.template = true
+	m.view = formView
+	return nil
+}
+
+// saveTemplate stores the template of the form, renaming it if the name was
+// changed
+func (m *model) saveTemplate(h SSHHost) tea.Cmd {
+	if m.templates == nil {
+		m.templates = make(map[string]HostTemplate)
+	}
+	delete(m.templates, m.form.original.Host)
+	m.templates[h.Host] = templateFrom(h)
+	m.openTemplates()
+	return tea.Batch(m.scheduleAutosave(), m.list.NewStatusMessage(statusMessageStyle("Saved template "+h.Host)))
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// how often the idle time is checked
+	idleTickInterval = time.Second
+	// the countdown shows for at most this long before quitting
+	idleWarning = 10 * time.Second
+)
+
+var idleWarningStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#1A1A1A")).Background(lipgloss.Color("#E8C547")).Padding(0, 1)
+
+func (s Settings) idleQuit() time.Duration {
+	return time.Duration(s.IdleQuitSeconds) * time.Second
+}
+
+// idleTimer quits the TUI after idle_quit_seconds without a key press
+type idleTimer struct {
+	lastInput time.Time
+	lastTick  time.Time
+}
+
+type idleTickMsg struct{}
+
+func idleTick() tea.Cmd {
+	return tea.Tick(idleTickInterval, func(time.Time) tea.Msg { return idleTickMsg{} })
+}
+
+// idleTicks starts the ticks if idle_quit_seconds is set
+func (m model) idleTicks() tea.Cmd {
+	if m.settings.idleQuit() <= 0 {
+		return nil
+	}
+	return idleTick()
+}
+
+// idleLeft is how long the TUI stays open without input
+func (m model) idleLeft(now time.Time) time.Duration {
+	return m.settings.idleQuit() - now.Sub(m.idle.lastInput)
+}
+
+// handleIdleTick saves and quits once the idle time has passed
+func (m *model) handleIdleTick() tea.Cmd {
+	now := time.Now()
+	// no ticks arrive while ssh or an editor has the terminal, that time
+	// doesn't count as idle
+	if now.Sub(m.idle.lastTick) > 2*idleTickInterval {
+		m.idle.lastInput = now
+	}
+	m.idle.lastTick = now
+	if m.idleLeft(now) > 0 {
+		return idleTick()
+	}
+
+	if m.autosave.unsaved && m.configErr == nil {
+		m.autosave.pending = false
+		if err := saveConfig(m.config()); err != nil {
+			// stay open rather than lose the changes
+			m.idle.lastInput = now
+			return tea.Batch(idleTick(), m.list.NewStatusMessage(errorMessageStyle("Not quitting after being idle, saving failed: "+err.Error())))
+		}
+		m.autosave.unsaved = false
+	}
+	return tea.Quit
+}
+
+// idleCountdown is shown in the last seconds before the idle quit
+func (m model) idleCountdown() string {
+	if m.settings.idleQuit() <= 0 {
+		return ""
+	}
+	left := m.idleLeft(time.Now())
+	if left > min(idleWarning, m.settings.idleQuit()/2) {
+		return ""
+	}
+	seconds := max(int((left+time.Second-1)/time.Second), 0)
+	return idleWarningStyle.Render(fmt.Sprintf("Are you still there? Quitting in %ds, press any key to stay", seconds))
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"cmp"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+)
+
+func runImport(args []string) int {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	source := fs.String("source", "", "where to import hosts from: vault, known-hosts, netbox, csv")
+	path := fs.String("path", "", "vault: KV path holding one secret per host")
+	addr := fs.String("addr", os.Getenv("VAULT_ADDR"), "vault: server address (default $VAULT_ADDR)")
+	token := fs.String("token", "", "vault, netbox: token (default $VAULT_TOKEN or $NETBOX_TOKEN)")
+	netboxURL := fs.String("url", "", "netbox: address of the NetBox instance")
+	site := fs.String("site", "", "netbox: only hosts of this site (slug)")
+	role := fs.String("role", "", "netbox: only hosts with this role (slug)")
+	knownHostsFile := fs.String("known-hosts-file", knownHostsPath(), "known-hosts: file to read")
+	csvFile := fs.String("file", "", "csv: file to read, with a header row")
+	guessFields := fs.Bool("guess-fields", false, "csv: also match columns like ip or login to their fields")
+	fs.Parse(args)
+
+	var imported []SSHHost
+	var hashed int
+	var err error
+	switch *source {
+	case "vault":
+		if *addr == "" || *path == "" {
+			fmt.Fprintln(os.Stderr, "vault import needs --addr and --path")
+			return 2
+		}
+		imported, err = FetchVaultHosts(*addr, cmp.Or(*token, os.Getenv("VAULT_TOKEN")), *path)
+	case "netbox":
+		if *netboxURL == "" {
+			fmt.Fprintln(os.Stderr, "netbox import needs --url")
+			return 2
+		}
+		filters := make(map[string]string)
+		if *site != "" {
+			filters["site"] = *site
+		}
+		if *role != "" {
+			filters["role"] = *role
+		}
+		imported, err = FetchNetBoxHosts(*netboxURL, cmp.Or(*token, os.Getenv("NETBOX_TOKEN")), filters)
+	case "known-hosts":
+		imported, hashed, err = importKnownHosts(*knownHostsFile)
+	case "csv":
+		if *csvFile == "" {
+			fmt.Fprintln(os.Stderr, "csv import needs --file")
+			return 2
+		}
+		imported, err = importCSV(*csvFile, *guessFields)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown source %q\n", *source)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "import failed:", err)
+		return 1
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load config:", err)
+		return 1
+	}
+
+	var skipped []string
+	config.Hosts, skipped = mergeHosts(config.Hosts, imported)
+	if err := saveConfig(config); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to save config:", err)
+		return 1
+	}
+
+	fmt.Printf("Imported %d hosts\n", len(imported)-len(skipped))
+	for _, alias := range skipped {
+		fmt.Printf("Skipped %s, the alias already exists\n", alias)
+	}
+	if hashed > 0 {
+		fmt.Printf("Skipped %d hashed entries, their hostnames can't be read\n", hashed)
+	}
+	return 0
+}
+
+// mergeHosts appends the imported hosts whose alias isn't taken yet and
+// returns the aliases that were skipped
+func mergeHosts(existing, imported []SSHHost) ([]SSHHost, []string) {
+	var skipped []string
+	for _, h := range imported {
+		if slices.ContainsFunc(existing, func(e SSHHost) bool { return e.Host == h.Host }) {
+			skipped = append(skipped, h.Host)
+			continue
+		}
+		existing = append(existing, h)
+	}
+	return existing, skipped
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+)
+
+type inventoryGroup struct {
+	Hosts    []string `json:"hosts,omitempty"`
+	Children []string `json:"children,omitempty"`
+}
+
+// ansibleHostVars maps a host onto the connection variables ansible uses
+func ansibleHostVars(h SSHHost) map[string]any {
+	vars := map[string]any{"ansible_host": h.address()}
+	if h.User != "" {
+		vars["ansible_user"] = h.User
+	}
+	if h.Port != 0 {
+		vars["ansible_port"] = h.Port
+	}
+	if h.IdentityFile != "" {
+		vars["ansible_ssh_private_key_file"] = expandPath(h.IdentityFile)
+	}
+	return vars
+}
+
+// FormatAnsibleInventory renders hosts in the JSON format ansible expects
+// from a dynamic inventory script. Aliases are the inventory names and each
+// tag becomes a group.
+func FormatAnsibleInventory(hosts []SSHHost) ([]byte, error) {
+	hostvars := make(map[string]any)
+	groups := make(map[string]*inventoryGroup)
+	all := &inventoryGroup{}
+	ungrouped := &inventoryGroup{}
+
+	for _, h := range hosts {
+		hostvars[h.Host] = ansibleHostVars(h)
+		all.Hosts = append(all.Hosts, h.Host)
+		if len(h.Tags) == 0 {
+			ungrouped.Hosts = append(ungrouped.Hosts, h.Host)
+		}
+		for _, tag := range h.Tags {
+			if groups[tag] == nil {
+				groups[tag] = &inventoryGroup{}
+				all.Children = append(all.Children, tag)
+			}
+			groups[tag].Hosts = append(groups[tag].Hosts, h.Host)
+		}
+	}
+	slices.Sort(all.Children)
+
+	inventory := map[string]any{
+		"_meta": map[string]any{"hostvars": hostvars},
+		"all":   all,
+	}
+	if len(ungrouped.Hosts) > 0 {
+		all.Children = append(all.Children, "ungrouped")
+		inventory["ungrouped"] = ungrouped
+	}
+	for name, group := range groups {
+		inventory[name] = group
+	}
+	return json.MarshalIndent(inventory, "", "  ")
+}
+
+// runInventory implements --inventory-mode. Ansible calls inventory scripts
+// with --list, or with --host <name> for a single host's variables.
+func runInventory(host string) int {
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load config:", err)
+		return 1
+	}
+
+	var out []byte
+	if host != "" {
+		vars := map[string]any{}
+		for _, h := range config.Hosts {
+			if h.Host == host {
+				vars = ansibleHostVars(h)
+			}
+		}
+		out, err = json.MarshalIndent(vars, "", "  ")
+	} else {
+		out, err = FormatAnsibleInventory(config.Hosts)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to encode inventory:", err)
+		return 1
+	}
+	fmt.Println(string(out))
+	return 0
+}
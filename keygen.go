@@ -0,0 +1,135 @@
+package main
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// bits of the RSA keys made by generate-keys
+const rsaKeyBits = 4096
+
+// generateKeyPair writes a new private key to path and its public key to
+// path.pub, and returns the public key in authorized_keys format
+func generateKeyPair(path, keyType, comment string) (string, error) {
+	var private crypto.Signer
+	var err error
+	switch keyType {
+	case "ed25519":
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	case "rsa":
+		private, err = rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	default:
+		return "", fmt.Errorf("unsupported key type %q", keyType)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	block, err := ssh.MarshalPrivateKey(private, comment)
+	if err != nil {
+		return "", err
+	}
+	public, err := ssh.NewPublicKey(private.Public())
+	if err != nil {
+		return "", err
+	}
+	authorized := string(ssh.MarshalAuthorizedKey(public))
+	authorized = authorized[:len(authorized)-1] + " " + comment + "\n"
+
+	// O_EXCL so an existing key is never replaced
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return "", err
+	}
+	if err := pem.Encode(f, block); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path+".pub", []byte(authorized), 0o644); err != nil {
+		return "", err
+	}
+	return authorized, nil
+}
+
+func runGenerateKeys(args []string) int {
+	fs := flag.NewFlagSet("generate-keys", flag.ExitOnError)
+	tag := fs.String("tag", "", "generate a key for every host with this tag")
+	keyType := fs.String("key-type", "ed25519", "key type: ed25519, rsa")
+	outputDir := fs.String("output-dir", "~/.ssh/quickssh", "directory to write the keys to")
+	fs.Parse(args)
+
+	if *tag == "" {
+		fmt.Fprintln(os.Stderr, "usage: quickssh generate-keys --tag <tag> [--key-type ed25519|rsa] [--output-dir dir]")
+		return 2
+	}
+	if *keyType != "ed25519" && *keyType != "rsa" {
+		fmt.Fprintf(os.Stderr, "unsupported key type %q\n", *keyType)
+		return 2
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load config:", err)
+		return 1
+	}
+	hosts, err := selectHosts(config.Hosts, "", *tag)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := os.MkdirAll(expandPath(*outputDir), 0o700); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	failed := false
+	generated := 0
+	for _, h := range hosts {
+		// kept as given, so ~ stays in the config
+		path := filepath.Join(*outputDir, h.Host+"_"+*keyType)
+		public, err := generateKeyPair(expandPath(path), *keyType, h.Host+"@quickssh")
+		if errors.Is(err, os.ErrExist) {
+			fmt.Fprintf(os.Stderr, "Skipped %s, %s already exists\n", h.Host, path)
+			failed = true
+			continue
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to generate a key for %s: %v\n", h.Host, err)
+			failed = true
+			continue
+		}
+
+		for i := range config.Hosts {
+			if config.Hosts[i].Host == h.Host {
+				config.Hosts[i].IdentityFile = path
+			}
+		}
+		generated++
+		fmt.Printf("# %s, append to ~/.ssh/authorized_keys on %s\n%s", h.Host, h.address(), public)
+	}
+
+	if generated > 0 {
+		if err := saveConfig(config); err != nil {
+			fmt.Fprintln(os.Stderr, "failed to save config:", err)
+			return 1
+		}
+		fmt.Fprintf(os.Stderr, "Generated %d keys in %s and set them as identity_file\n", generated, *outputDir)
+	}
+	if failed {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"errors"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+type hostKeyStatus uint
+
+const (
+	hostKeyKnown hostKeyStatus = iota
+	hostKeyUnknown
+	hostKeyMismatch
+)
+
+// returned from the host key callback to end the handshake once the key is in
+var errHostKeyReceived = errors.New("host key received")
+
+// sent once the pre-connect host key check has finished
+type hostKeyCheckedMsg struct {
+	host    SSHHost
+	profile Profile
+	status  hostKeyStatus
+	err     error
+}
+
+func knownHostsPath() string {
+	return expandPath("~/.ssh/known_hosts")
+}
+
+// CheckKnownHost fetches the host key of h and compares it against the
+// entries for HostName:Port in ~/.ssh/known_hosts. An error means the key
+// could not be checked, e.g. because the host is unreachable.
+func CheckKnownHost(h SSHHost, timeout time.Duration) (hostKeyStatus, error) {
+	addr := net.JoinHostPort(h.address(), strconv.Itoa(h.port()))
+
+	key, remote, err := fetchHostKey(addr, nil, timeout)
+	if err != nil {
+		return hostKeyUnknown, err
+	}
+
+	if _, err := os.Stat(knownHostsPath()); os.IsNotExist(err) {
+		return hostKeyUnknown, nil
+	}
+	callback, err := knownhosts.New(knownHostsPath())
+	if err != nil {
+		return hostKeyUnknown, err
+	}
+
+	err = callback(addr, remote, key)
+	var keyErr *knownhosts.KeyError
+	if errors.As(err, &keyErr) && len(keyErr.Want) > 0 && !hasKeyType(keyErr.Want, key.Type()) {
+		// the server offered a key type we have no entry for, which is not
+		// a mismatch, so ask again for one of the types we do know
+		key, remote, err = fetchHostKey(addr, keyAlgorithms(keyErr.Want), timeout)
+		if err != nil {
+			return hostKeyUnknown, err
+		}
+		err = callback(addr, remote, key)
+	}
+
+	var revokedErr *knownhosts.RevokedError
+	switch {
+	case err == nil:
+		return hostKeyKnown, nil
+	case errors.As(err, &keyErr) && len(keyErr.Want) == 0:
+		return hostKeyUnknown, nil
+	case errors.As(err, &keyErr), errors.As(err, &revokedErr):
+		return hostKeyMismatch, nil
+	}
+	return hostKeyUnknown, err
+}
+
+// fetchHostKey runs the ssh handshake until the server has sent its host key
+func fetchHostKey(addr string, algorithms []string, timeout time.Duration) (ssh.PublicKey, net.Addr, error) {
+	var key ssh.PublicKey
+	var remote net.Addr
+	config := &ssh.ClientConfig{
+		User:              "quickssh",
+		HostKeyAlgorithms: algorithms,
+		Timeout:           timeout,
+		HostKeyCallback: func(_ string, r net.Addr, k ssh.PublicKey) error {
+			key, remote = k, r
+			return errHostKeyReceived
+		},
+	}
+
+	conn, err := ssh.Dial("tcp", addr, config)
+	if err == nil {
+		conn.Close()
+	}
+	if key == nil {
+		return nil, nil, err
+	}
+	return key, remote, nil
+}
+
+func hasKeyType(keys []knownhosts.KnownKey, keyType string) bool {
+	for _, k := range keys {
+		if k.Key.Type() == keyType {
+			return true
+		}
+	}
+	return false
+}
+
+// keyAlgorithms maps known key types to the host key algorithms that produce
+// them, rsa keys are negotiated under the sha2 algorithm names
+func keyAlgorithms(keys []knownhosts.KnownKey) []string {
+	var algorithms []string
+	for _, k := range keys {
+		if k.Key.Type() == ssh.KeyAlgoRSA {
+			algorithms = append(algorithms, ssh.KeyAlgoRSASHA512, ssh.KeyAlgoRSASHA256)
+		}
+		algorithms = append(algorithms, k.Key.Type())
+	}
+	return algorithms
+}
+
+func checkHostKey(h SSHHost, p Profile) tea.Cmd {
+	return func() tea.Msg {
+		status, err := CheckKnownHost(h, 5*time.Second)
+		return hostKeyCheckedMsg{host: h, profile: p, status: status, err: err}
+	}
+}
+
+func (m model) hostKeyWarningView() string {
+	h := m.pending
+	addr := net.JoinHostPort(h.address(), strconv.Itoa(h.port()))
+
+	var warning string
+	if m.pendingStatus == hostKeyMismatch {
+		warning = errorMessageStyle("WARNING: the host key of "+addr+" does not match known_hosts!") +
+			"\n\nSomeone could be intercepting the connection (man-in-the-middle attack),\n" +
+			"or the host key has just been changed."
+	} else {
+		warning = "The host " + addr + " is not in known_hosts yet.\n\n" +
+			"This is expected when connecting for the first time."
+	}
+
+	return warningBoxStyle.Render(warning + "\n\nConnect to " + h.Host + " anyway? (y/N)")
+}
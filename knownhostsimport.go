@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// ImportFromKnownHosts creates a host for every entry of a known_hosts file,
+// with only the alias and hostname set. Hashed entries are skipped.
+func ImportFromKnownHosts(path string) ([]SSHHost, error) {
+	hosts, _, err := importKnownHosts(path)
+	return hosts, err
+}
+
+// importKnownHosts is ImportFromKnownHosts that also returns how many hashed
+// entries were skipped
+func importKnownHosts(path string) ([]SSHHost, int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var hosts []SSHHost
+	seen := make(map[string]bool)
+	hashed := 0
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		marker, names, _, _, _, err := ssh.ParseKnownHosts(line)
+		if err != nil {
+			// ssh ignores lines it can't parse as well
+			continue
+		}
+		if marker != "" {
+			// @cert-authority and @revoked lines don't name single hosts
+			continue
+		}
+		// a line can list a host by name and by address, the first name
+		// is enough
+		name := ""
+		for _, n := range names {
+			if !strings.HasPrefix(n, "|1|") && !strings.HasPrefix(n, "!") && !strings.ContainsAny(n, "*?") {
+				name = n
+				break
+			}
+		}
+		if name == "" {
+			if strings.HasPrefix(names[0], "|1|") {
+				hashed++
+			}
+			continue
+		}
+		h := knownHostsEntry(name)
+		if seen[h.Host] {
+			// every key type of a host has its own line
+			continue
+		}
+		seen[h.Host] = true
+		hosts = append(hosts, h)
+	}
+	return hosts, hashed, scanner.Err()
+}
+
+// knownHostsEntry turns a known_hosts name, host or [host]:port, into a
+// host. A port other than 22 becomes part of the alias.
+func knownHostsEntry(name string) SSHHost {
+	if strings.HasPrefix(name, "[") {
+		if host, port, err := net.SplitHostPort(name); err == nil {
+			if p, err := strconv.Atoi(port); err == nil && p != 22 {
+				return SSHHost{Host: host + "-" + port, HostName: host, Port: p}
+			}
+			name = host
+		}
+	}
+	return SSHHost{Host: name, HostName: name}
+}
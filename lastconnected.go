@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// shown for hosts without a recorded connection
+var neverStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#C0862E")).Italic(true)
+
+// relativeTime describes how long before now t was, in its largest unit,
+// e.g. "45s ago", "3h ago" or "2y ago"
+func relativeTime(t, now time.Time) string {
+	d := now.Sub(t)
+	switch {
+	case d < time.Second:
+		return "just now"
+	case d < time.Minute:
+		return fmt.Sprintf("%ds ago", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	}
+	days := int(d.Hours() / 24)
+	switch {
+	case days < 14:
+		return fmt.Sprintf("%dd ago", days)
+	case days < 60:
+		return fmt.Sprintf("%dw ago", days/7)
+	case days < 365:
+		return fmt.Sprintf("%dmo ago", days/30)
+	}
+	return fmt.Sprintf("%dy ago", days/365)
+}
+
+// lastConnectedLabel is "2d ago (2006-01-02 15:04)", or "never" if the host
+// has no recorded connection
+func lastConnectedLabel(last map[string]time.Time, alias string, now time.Time) string {
+	t, ok := last[alias]
+	if !ok {
+		return neverStyle.Render("never")
+	}
+	return relativeTime(t, now) + " (" + t.Format("2006-01-02 15:04") + ")"
+}
+
+// search prefix listing the hosts not connected to for a while
+const idlePrefix = "idle:"
+
+// parseIdle reads searches like "idle:30d", "idle:6mo" or just "idle:". The
+// units are h, d, w, mo and y.
+func parseIdle(term string) (time.Duration, bool) {
+	rest, ok := strings.CutPrefix(strings.ToLower(strings.TrimSpace(term)), idlePrefix)
+	if !ok {
+		return 0, false
+	}
+	if rest == "" {
+		return 0, true
+	}
+	units := []struct {
+		suffix string
+		unit   time.Duration
+	}{
+		{"mo", 30 * 24 * time.Hour},
+		{"h", time.Hour},
+		{"d", 24 * time.Hour},
+		{"w", 7 * 24 * time.Hour},
+		{"y", 365 * 24 * time.Hour},
+	}
+	for _, u := range units {
+		if number, ok := strings.CutSuffix(rest, u.suffix); ok {
+			n, err := strconv.Atoi(number)
+			if err != nil || n < 0 {
+				return 0, false
+			}
+			return time.Duration(n) * u.unit, true
+		}
+	}
+	return 0, false
+}
+
+// idleRanks keeps the hosts not connected to within idle, never connected
+// ones first and then the longest unused
+func idleRanks(hosts []SSHHost, last map[string]time.Time, idle time.Duration, now time.Time) []list.Rank {
+	var ranks []list.Rank
+	for i, h := range hosts {
+		if t, ok := last[h.Host]; !ok || now.Sub(t) >= idle {
+			ranks = append(ranks, list.Rank{Index: i})
+		}
+	}
+	slices.SortStableFunc(ranks, func(a, b list.Rank) int {
+		ta, okA := last[hosts[a.Index].Host]
+		tb, okB := last[hosts[b.Index].Host]
+		switch {
+		case !okA && !okB:
+			return 0
+		case !okA:
+			return -1
+		case !okB:
+			return 1
+		}
+		return ta.Compare(tb)
+	})
+	return ranks
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRelativeTime(t *testing.T) {
+	now := time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	tests := []struct {
+		ago  time.Duration
+		want string
+	}{
+		{0, "just now"},
+		{-time.Hour, "just now"},
+		{999 * time.Millisecond, "just now"},
+		{time.Second, "1s ago"},
+		{59 * time.Second, "59s ago"},
+		{time.Minute, "1m ago"},
+		{59*time.Minute + 59*time.Second, "59m ago"},
+		{time.Hour, "1h ago"},
+		{23 * time.Hour, "23h ago"},
+		{day, "1d ago"},
+		{13*day + 23*time.Hour, "13d ago"},
+		{14 * day, "2w ago"},
+		{59 * day, "8w ago"},
+		{60 * day, "2mo ago"},
+		{364 * day, "12mo ago"},
+		{365 * day, "1y ago"},
+		{3*365*day + 100*day, "3y ago"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ago.String(), func(t *testing.T) {
+			if got := relativeTime(now.Add(-tt.ago), now); got != tt.want {
+				t.Errorf("relativeTime(now - %s) = %q, want %q", tt.ago, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLastConnectedLabel(t *testing.T) {
+	now := time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC)
+	last := map[string]time.Time{"web1": now.Add(-50 * time.Hour)}
+	if got := lastConnectedLabel(last, "web1", now); got != "2d ago (2026-06-13 10:00)" {
+		t.Errorf("web1: %q", got)
+	}
+	if got := lastConnectedLabel(last, "db1", now); !strings.Contains(got, "never") {
+		t.Errorf("db1: %q, want never", got)
+	}
+}
+
+func TestParseIdle(t *testing.T) {
+	day := 24 * time.Hour
+	tests := []struct {
+		term string
+		want time.Duration
+		ok   bool
+	}{
+		{"idle:", 0, true},
+		{"idle:12h", 12 * time.Hour, true},
+		{"idle:30d", 30 * day, true},
+		{"idle:2w", 14 * day, true},
+		{"idle:6mo", 180 * day, true},
+		{"idle:1y", 365 * day, true},
+		{" IDLE:3D ", 3 * day, true},
+		{"idle:30", 0, false},
+		{"idle:-1d", 0, false},
+		{"idle:xd", 0, false},
+		{"web1", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.term, func(t *testing.T) {
+			got, ok := parseIdle(tt.term)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("parseIdle(%q) = %s, %t, want %s, %t", tt.term, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestIdleRanks(t *testing.T) {
+	now := time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	hosts := []SSHHost{{Host: "recent"}, {Host: "month"}, {Host: "never1"}, {Host: "year"}, {Host: "never2"}}
+	last := map[string]time.Time{
+		"recent": now.Add(-day),
+		"month":  now.Add(-30 * day),
+		"year":   now.Add(-365 * day),
+	}
+	tests := []struct {
+		idle time.Duration
+		want string
+	}{
+		{0, "never1 never2 year month recent"},
+		{7 * day, "never1 never2 year month"},
+		{100 * day, "never1 never2 year"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.idle.String(), func(t *testing.T) {
+			var got []string
+			for _, r := range idleRanks(hosts, last, tt.idle, now) {
+				got = append(got, hosts[r.Index].Host)
+			}
+			if strings.Join(got, " ") != tt.want {
+				t.Errorf("kept %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
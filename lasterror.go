@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+const (
+	// bytes of ssh's stderr kept per session
+	stderrTailSize = 4096
+	// lines of it shown in the detail panel
+	lastErrorLines = 5
+)
+
+// tailBuffer keeps the last max bytes written to it
+type tailBuffer struct {
+	buf []byte
+	max int
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.buf = append(t.buf, p...)
+	if len(t.buf) > t.max {
+		t.buf = t.buf[len(t.buf)-t.max:]
+	}
+	return len(p), nil
+}
+
+// sessionError is a failed ssh session together with the end of what ssh
+// wrote to stderr
+type sessionError struct {
+	err    error
+	stderr string
+}
+
+func (e *sessionError) Error() string { return e.err.Error() }
+func (e *sessionError) Unwrap() error { return e.err }
+
+// lastError is the most recent failed connection to a host, kept for the
+// session only
+type lastError struct {
+	time     time.Time
+	exitCode int
+	stderr   string
+}
+
+func newLastError(err error, stderr string) lastError {
+	var lines []string
+	for _, line := range strings.Split(stderr, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 {
+		lines = []string{err.Error()}
+	}
+	lines = lines[max(len(lines)-lastErrorLines, 0):]
+	return lastError{time: time.Now(), exitCode: exitCode(err), stderr: strings.Join(lines, "\n")}
+}
+
+func (e lastError) view(width int) string {
+	title := fmt.Sprintf("Last error (%s, exit code %d)", e.time.Format(time.TimeOnly), e.exitCode)
+	lines := []string{notesTitleStyle.Render(ansi.Truncate(title, width, "…"))}
+	for _, line := range strings.Split(e.stderr, "\n") {
+		lines = append(lines, errorMessageStyle(ansi.Truncate(line, width, "…")))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,326 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// only the end of a log is read, so a huge log opens as fast as a small one
+	logViewTail = 256 * 1024
+	// how often the open log is checked for new lines
+	logViewRefresh = time.Second
+)
+
+// logViewer shows the logs in log_dir, opened with L. seq tells reads of an
+// older viewer apart
+type logViewer struct {
+	seq   int
+	dir   string
+	files []string
+	file  int
+
+	// size and modification time of the last read, a tick only reads the
+	// file again when they change
+	size      int64
+	mod       time.Time
+	lines     []string
+	truncated bool
+	err       error
+
+	output    viewport.Model
+	search    textinput.Model
+	searching bool
+	query     string
+	// line of the match last jumped to, -1 for none
+	match int
+}
+
+// sent with the end of a log file, or with changed false if it's the same
+// as last time
+type logReadMsg struct {
+	seq       int
+	changed   bool
+	size      int64
+	mod       time.Time
+	content   []byte
+	truncated bool
+	err       error
+}
+
+type logTickMsg struct{ seq int }
+
+// logFiles returns the .log files in dir, last written first
+func logFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	type logFile struct {
+		path string
+		mod  time.Time
+	}
+	var files []logFile
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".log" {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, logFile{filepath.Join(dir, e.Name()), info.ModTime()})
+	}
+	slices.SortFunc(files, func(a, b logFile) int { return b.mod.Compare(a.mod) })
+	paths := make([]string, len(files))
+	for i, f := range files {
+		paths[i] = f.path
+	}
+	return paths, nil
+}
+
+// readLogTail returns the last max bytes of the file at path, starting at a
+// full line, and whether anything before them was left out
+func readLogTail(path string, max int64) ([]byte, bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, false, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, false, err
+	}
+	if info.Size() <= max {
+		data, err := io.ReadAll(f)
+		return data, false, err
+	}
+	if _, err := f.Seek(-max, io.SeekEnd); err != nil {
+		return nil, false, err
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, false, err
+	}
+	// the first line is most likely cut off
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		data = data[i+1:]
+	}
+	return data, true, nil
+}
+
+// readLog reads path if its size or modification time differ from the ones
+// given
+func readLog(path string, seq int, size int64, mod time.Time) tea.Cmd {
+	return func() tea.Msg {
+		info, err := os.Stat(path)
+		if err != nil {
+			return logReadMsg{seq: seq, changed: true, err: err}
+		}
+		if info.Size() == size && info.ModTime().Equal(mod) {
+			return logReadMsg{seq: seq}
+		}
+		content, truncated, err := readLogTail(path, logViewTail)
+		return logReadMsg{seq: seq, changed: true, size: info.Size(), mod: info.ModTime(), content: content, truncated: truncated, err: err}
+	}
+}
+
+func logTick(seq int) tea.Cmd {
+	return tea.Tick(logViewRefresh, func(time.Time) tea.Msg {
+		return logTickMsg{seq: seq}
+	})
+}
+
+// openLogs shows the log written last in log_dir
+func (m *model) openLogs() tea.Cmd {
+	dir := m.settings.logDir()
+	files, err := logFiles(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return m.list.NewStatusMessage(errorMessageStyle("Failed to list the logs: " + err.Error()))
+	}
+	if len(files) == 0 {
+		return m.list.NewStatusMessage("No logs in " + dir + " yet, log_operations writes one")
+	}
+	search := textinput.New()
+	search.Prompt = "/"
+	m.logs = logViewer{seq: m.logs.seq + 1, dir: dir, files: files, output: viewport.New(0, 0), search: search, match: -1}
+	m.view = logView
+	m.sizeLogs()
+	return readLog(files[0], m.logs.seq, -1, time.Time{})
+}
+
+// sizeLogs fits the log viewport into the window
+func (m *model) sizeLogs() {
+	h, v := appStyle.GetFrameSize()
+	// title, blank, status and help lines
+	m.logs.output.Width = max(m.width-h, 1)
+	m.logs.output.Height = max(m.height-v-4, 1)
+}
+
+// showLog puts a read of the open log in the viewport, staying at the end if
+// the viewport was there
+func (m *model) showLog(msg logReadMsg) {
+	follow := m.logs.lines == nil || m.logs.output.AtBottom()
+	m.logs.size, m.logs.mod, m.logs.err = msg.size, msg.mod, msg.err
+	m.logs.truncated = msg.truncated
+	m.logs.lines = strings.Split(strings.TrimSuffix(string(msg.content), "\n"), "\n")
+	m.renderLog()
+	if follow {
+		m.logs.output.GotoBottom()
+	}
+}
+
+// renderLog sets the viewport content, with the lines matching the search
+// highlighted
+func (m *model) renderLog() {
+	query := strings.ToLower(m.logs.query)
+	lines := make([]string, len(m.logs.lines))
+	for i, line := range m.logs.lines {
+		if query != "" && strings.Contains(strings.ToLower(line), query) {
+			line = watchChangedStyle.Render(line)
+		}
+		lines[i] = line
+	}
+	m.logs.output.SetContent(strings.Join(lines, "\n"))
+}
+
+// findLog scrolls to the next line matching the search after the last match,
+// or the previous one before it if back is set, wrapping around
+func (m *model) findLog(back bool) {
+	query := strings.ToLower(m.logs.query)
+	n := len(m.logs.lines)
+	if query == "" || n == 0 {
+		return
+	}
+	start := m.logs.match
+	if start < 0 {
+		// start from the lines on screen
+		start = m.logs.output.YOffset - 1
+		if back {
+			start = m.logs.output.YOffset + m.logs.output.Height
+		}
+	}
+	step := 1
+	if back {
+		step = -1
+	}
+	for i := 1; i <= n; i++ {
+		line := ((start+i*step)%n + n) % n
+		if strings.Contains(strings.ToLower(m.logs.lines[line]), query) {
+			m.logs.match = line
+			m.logs.output.SetYOffset(line)
+			return
+		}
+	}
+	m.logs.match = -1
+}
+
+func (m *model) updateLogs(msg tea.KeyMsg) tea.Cmd {
+	if m.logs.searching {
+		switch msg.String() {
+		case "esc":
+			m.logs.searching = false
+			m.logs.search.Blur()
+			return nil
+		case "enter":
+			m.logs.searching = false
+			m.logs.search.Blur()
+			m.logs.query = m.logs.search.Value()
+			m.logs.match = -1
+			m.renderLog()
+			m.findLog(false)
+			return nil
+		}
+		var cmd tea.Cmd
+		m.logs.search, cmd = m.logs.search.Update(msg)
+		return cmd
+	}
+
+	switch msg.String() {
+	case "esc", "q":
+		if m.logs.query != "" && msg.String() == "esc" {
+			m.logs.query = ""
+			m.logs.match = -1
+			m.renderLog()
+			return nil
+		}
+		m.logs.seq++
+		m.view = listView
+		return nil
+	case "/":
+		m.logs.searching = true
+		m.logs.search.SetValue(m.logs.query)
+		m.logs.search.CursorEnd()
+		return m.logs.search.Focus()
+	case "n":
+		m.findLog(false)
+		return nil
+	case "N":
+		m.findLog(true)
+		return nil
+	case "g", "home":
+		m.logs.output.GotoTop()
+		return nil
+	case "G", "end":
+		m.logs.output.GotoBottom()
+		return nil
+	case "tab":
+		if len(m.logs.files) < 2 {
+			return nil
+		}
+		m.logs.file = (m.logs.file + 1) % len(m.logs.files)
+		m.logs.seq++
+		m.logs.lines = nil
+		m.logs.match = -1
+		return readLog(m.logs.files[m.logs.file], m.logs.seq, -1, time.Time{})
+	}
+	var cmd tea.Cmd
+	m.logs.output, cmd = m.logs.output.Update(msg)
+	return cmd
+}
+
+func (m model) logView() string {
+	l := m.logs
+	title := titleStyle.Render("Log " + filepath.Base(l.files[l.file]))
+	if len(l.files) > 1 {
+		title += fmt.Sprintf(" %d/%d", l.file+1, len(l.files))
+	}
+
+	var status string
+	switch {
+	case l.searching:
+		status = l.search.View()
+	case l.err != nil:
+		status = errorMessageStyle("Failed to read the log: " + l.err.Error())
+	default:
+		switch {
+		case l.lines == nil:
+			status = "Reading..."
+		case l.truncated:
+			status = fmt.Sprintf("last %d KiB of %d KiB", logViewTail/1024, l.size/1024)
+		default:
+			status = fmt.Sprintf("%d lines", len(l.lines))
+		}
+		if l.query != "" {
+			status += " • searching for " + l.query
+		}
+		status = checkFixStyle.Render(status)
+	}
+
+	help := "j/k: scroll • g/G: top/end • /: search • n/N: next/previous match"
+	if len(l.files) > 1 {
+		help += " • tab: next log"
+	}
+	help += " • esc: close"
+	return title + "\n\n" + l.output.View() + "\n" + status + "\n" + checkFixStyle.Render(help)
+}
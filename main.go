@@ -1,16 +1,24 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
-	"math/rand"
+	"maps"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -20,6 +28,25 @@ type viewState uint
 const (
 	listView viewState = iota
 	detailView
+	confirmConnectView
+	dashboardView
+	selectProfileView
+	topView
+	checkAllView
+	portScanView
+	tagFilterView
+	rawTOMLView
+	formView
+	reloadView
+	moveGroupView
+	execView
+	duplicatesView
+	templatesView
+	removeTagView
+	bulkConfirmView
+	logView
+	bulkTagView
+	effectiveConfigView
 )
 
 var (
@@ -34,21 +61,33 @@ var (
 				Foreground(lipgloss.AdaptiveColor{Light: "#04B575", Dark: "#04B575"}).
 				Render
 
+	errorMessageStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.AdaptiveColor{Light: "#FF4672", Dark: "#ED567A"}).
+				Render
+
+	warningBoxStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("#ED567A")).
+			Padding(1, 2)
+
 	configFilePath string
 )
 
 func InitConfigPath() error {
-	if runtime.GOOS != "windows" {
-		// Optional: set a different default for non-Windows, or skip
-		return nil
-	}
-
-	localAppData := os.Getenv("LOCALAPPDATA")
-	if localAppData == "" {
-		return fmt.Errorf("LOCALAPPDATA environment variable is not set")
+	var configDir string
+	if runtime.GOOS == "windows" {
+		localAppData := os.Getenv("LOCALAPPDATA")
+		if localAppData == "" {
+			return fmt.Errorf("LOCALAPPDATA environment variable is not set")
+		}
+		configDir = filepath.Join(localAppData, "quickssh")
+	} else {
+		userConfigDir, err := os.UserConfigDir()
+		if err != nil {
+			return fmt.Errorf("failed to resolve config directory: %w", err)
+		}
+		configDir = filepath.Join(userConfigDir, "quickssh")
 	}
-
-	configDir := filepath.Join(localAppData, "quickssh")
 	configFilePath = filepath.Join(configDir, ".config")
 
 	err := os.MkdirAll(configDir, 0o755)
@@ -66,26 +105,132 @@ func InitConfigPath() error {
 
 	return nil
 }
-func (i SSHHost) Title() string { return i.Host }
+
+// expandPath resolves a leading ~ to the user's home directory
+func expandPath(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, `~\`) {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
+
+func (i SSHHost) Title() string {
+	title := i.Host
+	if i.Group != "" {
+		title = i.Group + "/" + title
+	}
+	if i.fromSSHConfig {
+		return title + " (ssh_config)"
+	}
+	if i.Decommissioned {
+		return title + " (decommissioned)"
+	}
+	return title
+}
 func (i SSHHost) Description() string {
 	nicedescription := i.Desc + " " + strings.Join(i.Tags, "<")
 	return nicedescription
 }
-func (i SSHHost) FilterValue() string { return i.Host }
+
+// hostItem is the list representation of a host. The filter string is built
+// once per item so filtering large lists doesn't rebuild it on every keystroke
+type hostItem struct {
+	SSHHost
+	filterValue string
+	reach       reachState
+	// another host has the same address
+	duplicate bool
+	// a master connection is up that sessions share
+	shared bool
+}
+
+func newHostItem(h SSHHost) hostItem {
+	fields := append([]string{h.Host, h.HostName, h.User, h.Group}, h.Tags...)
+	return hostItem{SSHHost: h, filterValue: strings.Join(fields, " ")}
+}
+
+func (i hostItem) Title() string {
+	title := i.SSHHost.Title() + i.reach.indicator()
+	if i.duplicate {
+		title += " " + duplicateStyle.Render("≡")
+	}
+	if i.shared {
+		title += " " + sharedStyle.Render("● shared")
+	}
+	return title
+}
+func (i hostItem) FilterValue() string { return i.filterValue }
+
+// itemFor builds the list item of h including its known status
+func (m model) itemFor(h SSHHost) hostItem {
+	item := newHostItem(h)
+	item.reach = m.reach[h.Host]
+	item.duplicate = m.duplicates[h.Host] && !h.fromSSHConfig
+	item.shared = m.shared[h.Host]
+	return item
+}
 
 // keys
 type listKeyMap struct {
-	insertItem key.Binding
-	deleteItem key.Binding
-	saveConfig key.Binding
+	connect        key.Binding
+	connectProfile key.Binding
+	insertItem     key.Binding
+	editItem       key.Binding
+	deleteItem     key.Binding
+	saveConfig     key.Binding
+	undo           key.Binding
+	redo           key.Binding
+	checkReach     key.Binding
+	dashboard      key.Binding
+	resources      key.Binding
+	notesDown      key.Binding
+	notesUp        key.Binding
+	copyConfigPath key.Binding
+	copyIdentity   key.Binding
+	editConfig     key.Binding
+	toggleNumbers  key.Binding
+	verbose        key.Binding
+	subtitle       key.Binding
+	portScan       key.Binding
+	checkAll       key.Binding
+	tagFilter      key.Binding
+	rawTOML        key.Binding
+	moveGroup      key.Binding
+	ping           key.Binding
+	execRun        key.Binding
+	duplicates     key.Binding
+	templates      key.Binding
+	bulkDelete     key.Binding
+	removeTag      key.Binding
+	bulkTag        key.Binding
+	logs           key.Binding
+	closeMaster    key.Binding
+	jumpHost       key.Binding
+	sshG           key.Binding
 }
 
 // information for new keys
 func newListKeyMap() *listKeyMap {
 	return &listKeyMap{
+		connect: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "connect"),
+		),
+		connectProfile: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "connect with profile"),
+		),
 		insertItem: key.NewBinding(
 			key.WithKeys("a"),
-			key.WithHelp("a", "add item"),
+			key.WithHelp("a", "add host"),
+		),
+		editItem: key.NewBinding(
+			key.WithKeys("e"),
+			key.WithHelp("e", "edit host"),
 		),
 		deleteItem: key.NewBinding(
 			key.WithKeys("d"),
@@ -95,171 +240,1206 @@ func newListKeyMap() *listKeyMap {
 			key.WithKeys("s"),
 			key.WithHelp("s", "save config"),
 		),
+		undo: key.NewBinding(
+			key.WithKeys("ctrl+z"),
+			key.WithHelp("ctrl+z", "undo"),
+		),
+		redo: key.NewBinding(
+			key.WithKeys("ctrl+y"),
+			key.WithHelp("ctrl+y", "redo"),
+		),
+		checkReach: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "check reachability"),
+		),
+		checkAll: key.NewBinding(
+			key.WithKeys("R"),
+			key.WithHelp("R", "check all hosts"),
+		),
+		dashboard: key.NewBinding(
+			key.WithKeys("H"),
+			key.WithHelp("H", "health dashboard"),
+		),
+		resources: key.NewBinding(
+			key.WithKeys("ctrl+d"),
+			key.WithHelp("ctrl+d", "cpu/memory dashboard"),
+		),
+		notesDown: key.NewBinding(
+			key.WithKeys("J"),
+			key.WithHelp("J", "scroll notes down"),
+		),
+		notesUp: key.NewBinding(
+			key.WithKeys("K"),
+			key.WithHelp("K", "scroll notes up"),
+		),
+		editConfig: key.NewBinding(
+			key.WithKeys("E"),
+			key.WithHelp("E", "edit config file"),
+		),
+		copyIdentity: key.NewBinding(
+			key.WithKeys("I"),
+			key.WithHelp("I", "copy identity file path"),
+		),
+		copyConfigPath: key.NewBinding(
+			key.WithKeys("C"),
+			key.WithHelp("C", "copy config path"),
+		),
+		toggleNumbers: key.NewBinding(
+			key.WithKeys("#"),
+			key.WithHelp("#", "toggle host numbers"),
+		),
+		portScan: key.NewBinding(
+			key.WithKeys("ctrl+p"),
+			key.WithHelp("ctrl+p", "scan ports"),
+		),
+		tagFilter: key.NewBinding(
+			key.WithKeys("t"),
+			key.WithHelp("t", "filter by tags"),
+		),
+		rawTOML: key.NewBinding(
+			key.WithKeys("T"),
+			key.WithHelp("T", "show host TOML"),
+		),
+		duplicates: key.NewBinding(
+			key.WithKeys("M"),
+			key.WithHelp("M", "merge duplicate hosts"),
+		),
+		templates: key.NewBinding(
+			key.WithKeys("A"),
+			key.WithHelp("A", "host templates"),
+		),
+		bulkDelete: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("x", "delete listed hosts"),
+		),
+		removeTag: key.NewBinding(
+			key.WithKeys("U"),
+			key.WithHelp("U", "remove tag from listed hosts"),
+		),
+		bulkTag: key.NewBinding(
+			key.WithKeys("+"),
+			key.WithHelp("+", "add tag to visible hosts"),
+		),
+		sshG: key.NewBinding(
+			key.WithKeys("S"),
+			key.WithHelp("S", "effective ssh config"),
+		),
+		jumpHost: key.NewBinding(
+			key.WithKeys("B"),
+			key.WithHelp("B", "shell on jump host"),
+		),
+		closeMaster: key.NewBinding(
+			key.WithKeys("O"),
+			key.WithHelp("O", "close shared connection"),
+		),
+		logs: key.NewBinding(
+			key.WithKeys("L"),
+			key.WithHelp("L", "logs"),
+		),
+		execRun: key.NewBinding(
+			key.WithKeys("X"),
+			key.WithHelp("X", "run on listed hosts"),
+		),
+		ping: key.NewBinding(
+			key.WithKeys("P"),
+			key.WithHelp("P", "ping"),
+		),
+		moveGroup: key.NewBinding(
+			key.WithKeys("m"),
+			key.WithHelp("m", "move to group"),
+		),
+		subtitle: key.NewBinding(
+			key.WithKeys("D"),
+			key.WithHelp("D", "cycle subtitle"),
+		),
+		verbose: key.NewBinding(
+			key.WithKeys("V"),
+			key.WithHelp("V", "ssh verbosity for next connect"),
+		),
 	}
 }
 
 // content of the entire model
 // TODO: add detailed view as its own model (maybe 2nd file?)
 type model struct {
-	list  list.Model
-	keys  *listKeyMap
-	hosts []SSHHost
-	view  viewState
+	initCmd tea.Cmd
+	// set when the config couldn't be read, saving would overwrite it
+	configErr error
+
+	list     list.Model
+	keys     *listKeyMap
+	hosts    []SSHHost
+	settings Settings
+	view     viewState
+	edits    undoStack
+	autosave autosave
+	idle     idleTimer
+
+	// global [deploy] steps, kept so saving doesn't drop them
+	deploy DeployConfig
+
+	// reachability results and last connection times by alias
+	reach    map[string]reachState
+	lastSeen map[string]time.Time
+	// hosts whose master connection is up, by alias
+	shared map[string]bool
+	// every recorded connection, used to rank search results
+	history []ConnectionEvent
+	// why the last connection to a host failed, by alias
+	lastErrors map[string]lastError
+
+	// terminal size and the scrollable notes of the host in the detail panel
+	width      int
+	height     int
+	notes      viewport.Model
+	detailHost string
+
+	// host waiting for confirmation after a failed host key check
+	pending        SSHHost
+	pendingProfile Profile
+	pendingStatus  hostKeyStatus
+
+	profiles  map[string]Profile
+	templates map[string]HostTemplate
+	selector  selector
+	top       topDashboard
+	batch     reachBatch
+	scan      portScanOverlay
+	raw       rawTOMLModal
+	form      hostForm
+	reload    reloadPanel
+	group     groupMover
+	exec      execRun
+	dups      duplicatesPanel
+	bulk      bulkChange
+	bulkTag   textinput.Model
+	logs      logViewer
+	effective effectiveConfig
+
+	// aliases of hosts sharing their address with another host
+	duplicates map[string]bool
+
+	// verbosity for the next connection only, set with V
+	verboseOnce int
+	verboseSet  bool
+
+	// digits typed so far for a quick connect by number
+	number    string
+	numberSeq int
+	// the number was started with g, it selects the host instead
+	numberJump bool
+
+	// tag expression typed with t, applied as the list filter
+	tagInput textinput.Model
+	tagQuery string
 }
 
 func (m model) Init() tea.Cmd {
-	return nil
+	return tea.Batch(m.initCmd, warmupHosts(m.hosts), checkShared(m.hosts), m.idleTicks())
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
+	if _, ok := msg.(tea.KeyMsg); ok {
+		m.idle.lastInput = time.Now()
+	}
 	switch msg := msg.(type) {
+	case hostKeyCheckedMsg:
+		if msg.err != nil || msg.status == hostKeyKnown {
+			// nothing to warn about, or ssh will report the problem itself
+			return m, connect(msg.host, msg.profile, m.settings)
+		}
+		m.pending = msg.host
+		m.pendingProfile = msg.profile
+		m.pendingStatus = msg.status
+		m.view = confirmConnectView
+		return m, nil
+
+	case topStatsMsg:
+		if m.view != topView || msg.seq != m.top.seq {
+			return m, nil
+		}
+		if msg.stats != nil {
+			m.top.stats, m.top.mem = msg.stats, msg.mem
+		}
+		m.top.err = msg.err
+		return m, topTick(msg.seq)
+
+	case topTickMsg:
+		if m.view != topView || msg.seq != m.top.seq {
+			return m, nil
+		}
+		return m, fetchTop(m.top.host, msg.seq)
+
+	case reachabilityMsg:
+		cmd := m.setReachability(msg.host, msg.state)
+		if msg.err != nil {
+			return m, tea.Batch(cmd, m.list.NewStatusMessage(errorMessageStyle(msg.host+" is unreachable: "+msg.err.Error())))
+		}
+		return m, tea.Batch(cmd, m.list.NewStatusMessage(statusMessageStyle(fmt.Sprintf("%s is reachable (%s)", msg.host, msg.latency.Round(time.Millisecond)))))
+
+	case effectiveConfigMsg:
+		if m.view == effectiveConfigView && msg.seq == m.effective.seq {
+			m.showEffectiveConfig(msg)
+		}
+		return m, nil
+
+	case logReadMsg:
+		if m.view != logView || msg.seq != m.logs.seq {
+			return m, nil
+		}
+		if msg.changed {
+			m.showLog(msg)
+		}
+		return m, logTick(msg.seq)
+
+	case logTickMsg:
+		if m.view != logView || msg.seq != m.logs.seq {
+			return m, nil
+		}
+		l := m.logs
+		return m, readLog(l.files[l.file], l.seq, l.size, l.mod)
+
+	case execDoneMsg:
+		if m.view != execView || msg.seq != m.exec.seq {
+			return m, nil
+		}
+		m.showExecReport(msg.report)
+		return m, nil
+
+	case pingResultMsg:
+		if msg.err != nil {
+			return m, m.list.NewStatusMessage(errorMessageStyle(fmt.Sprintf("Ping of %s failed: %v", msg.host, msg.err)))
+		}
+		return m, m.list.NewStatusMessage(statusMessageStyle(fmt.Sprintf("%s: %s ms (%s)", msg.host, milliseconds(msg.rtt), msg.method)))
+
+	case autosaveMsg:
+		return m, m.handleAutosave(msg)
+
+	case idleTickMsg:
+		return m, m.handleIdleTick()
+
+	case reloadRequestMsg:
+		return m, readConfig
+
+	case configReadMsg:
+		if msg.err != nil {
+			return m, m.list.NewStatusMessage(errorMessageStyle("Failed to reload config: " + msg.err.Error()))
+		}
+		return m, m.showReload(msg.config)
+
+	case warmupCompleteMsg:
+		if msg.err != nil {
+			return m, m.list.NewStatusMessage(errorMessageStyle("Warmup of " + msg.host + " failed: " + msg.err.Error()))
+		}
+		return m, tea.Batch(m.setReachability(msg.host, reachable), m.setShared(msg.host, true))
+
+	case sharedStateMsg:
+		return m, m.setShared(msg.host, msg.active)
+
+	case masterClosedMsg:
+		if msg.err != nil {
+			return m, m.list.NewStatusMessage(errorMessageStyle("Failed to close the shared connection to " + msg.host + ": " + msg.err.Error()))
+		}
+		return m, tea.Batch(m.setShared(msg.host, false), m.list.NewStatusMessage(statusMessageStyle("Closed the shared connection to "+msg.host)))
+
+	case numberTimeoutMsg:
+		if msg.seq == m.numberSeq && m.number != "" {
+			return m, m.quickConnect()
+		}
+		return m, nil
+
+	case batchReachMsg:
+		if msg.seq != m.batch.seq {
+			return m, nil
+		}
+		m.batch.done++
+		if msg.result.state == reachable {
+			m.batch.up++
+		}
+		return m, tea.Batch(m.setReachability(msg.result.host, msg.result.state), m.batch.next())
+
+	case batchDoneMsg:
+		if msg.seq != m.batch.seq || m.view != checkAllView {
+			return m, nil
+		}
+		m.view = listView
+		return m, m.list.NewStatusMessage(statusMessageStyle("Checked all hosts: " + m.batch.summary()))
+
+	case portScanMsg:
+		if m.view != portScanView || msg.host != m.scan.host {
+			return m, nil
+		}
+		m.scan.scanning = false
+		m.scan.results.SetContent(renderPortTable(msg.results))
+		return m, nil
+
+	case connectFinishedMsg:
+		event := newConnectionEvent(msg.host, msg.err)
+		m.lastSeen[event.Host] = event.Time
+		m.history = append(m.history, event)
+		// ControlPersist may keep the master up after the session
+		recheck := checkShared([]SSHHost{msg.host})
+		if err := appendHistory(event); err != nil {
+			return m, tea.Batch(recheck, m.list.NewStatusMessage(errorMessageStyle("Failed to record history: "+err.Error())))
+		}
+		if msg.err != nil {
+			var sessionErr *sessionError
+			stderr := ""
+			if errors.As(msg.err, &sessionErr) {
+				stderr = sessionErr.stderr
+			}
+			m.lastErrors[msg.host.Host] = newLastError(msg.err, stderr)
+			return m, tea.Batch(recheck, m.list.NewStatusMessage(errorMessageStyle("Connection to "+msg.host.Host+" failed: "+msg.err.Error())))
+		}
+		delete(m.lastErrors, msg.host.Host)
+		return m, tea.Batch(recheck, m.list.NewStatusMessage(statusMessageStyle("Disconnected from "+msg.host.Host)))
+
 	case tea.KeyMsg:
+		if key.Matches(msg, m.keys.copyConfigPath) && m.list.FilterState() != list.Filtering {
+			return m, m.copyWithStatus("config path", configFilePath)
+		}
+
+		if m.view == dashboardView {
+			if msg.String() == "esc" || key.Matches(msg, m.keys.dashboard) {
+				m.view = listView
+			}
+			return m, nil
+		}
+
+		if m.view == checkAllView {
+			if msg.String() == "esc" {
+				m.batch.cancel()
+				m.batch.seq++
+				m.view = listView
+				return m, m.list.NewStatusMessage("Cancelled after " + m.batch.summary())
+			}
+			return m, nil
+		}
+
+		if m.view == portScanView {
+			if msg.String() == "esc" || msg.String() == "q" {
+				m.view = listView
+				return m, nil
+			}
+			var cmd tea.Cmd
+			m.scan.results, cmd = m.scan.results.Update(msg)
+			return m, cmd
+		}
+
+		if m.view == tagFilterView {
+			return m, m.updateTagFilter(msg)
+		}
+
+		if m.view == rawTOMLView {
+			return m, m.updateRawTOML(msg)
+		}
+
+		if m.view == formView {
+			return m, m.updateForm(msg)
+		}
+
+		if m.view == reloadView {
+			return m, m.updateReload(msg)
+		}
+
+		if m.view == moveGroupView {
+			return m, m.updateGroupMover(msg)
+		}
+
+		if m.view == execView {
+			return m, m.updateExecRun(msg)
+		}
+
+		if m.view == duplicatesView {
+			return m, m.updateDuplicates(msg)
+		}
+
+		if m.view == templatesView {
+			return m, m.updateTemplates(msg)
+		}
+
+		if m.view == removeTagView {
+			return m, m.updateRemoveTag(msg)
+		}
+
+		if m.view == bulkConfirmView {
+			return m, m.updateBulkConfirm(msg)
+		}
+
+		if m.view == bulkTagView {
+			return m, m.updateBulkTag(msg)
+		}
+
+		if m.view == effectiveConfigView {
+			return m, m.updateEffectiveConfig(msg)
+		}
+
+		if m.view == logView {
+			return m, m.updateLogs(msg)
+		}
+
+		if m.view == topView {
+			if msg.String() == "esc" || msg.String() == "q" {
+				m.view = listView
+			}
+			return m, nil
+		}
+
+		if m.view == selectProfileView {
+			choice, done := m.selector.update(msg)
+			if !done {
+				return m, nil
+			}
+			m.view = listView
+			if h, ok := m.selectedHost(); ok && choice != "" {
+				return m, m.connectTo(h, m.profiles[choice])
+			}
+			return m, nil
+		}
+
+		if m.view == confirmConnectView {
+			m.view = listView
+			if msg.String() == "y" {
+				return m, connect(m.pending, m.pendingProfile, m.settings)
+			}
+			return m, m.list.NewStatusMessage("Connection to " + m.pending.Host + " cancelled")
+		}
 
 		if m.list.FilterState() == list.Filtering {
+			if msg.String() == "enter" {
+				// connect right away if the typed text names a single host
+				if h, _, err := matchAlias(m.list.FilterValue(), m.listedHosts()); err == nil {
+					m.list.ResetFilter()
+					return m, m.connectTo(h, Profile{})
+				}
+			}
 			break
 		}
+		digit := len(msg.Runes) == 1 && msg.Runes[0] >= '0' && msg.Runes[0] <= '9'
+		if !digit && m.number == "" {
+			m.numberJump = false
+		}
 		switch {
 
+		case m.settings.VimMode && msg.String() == "i":
+			return m, m.startInsert()
+
+		case digit && (m.settings.ShowNumbers || m.numberJump):
+			return m, m.typeDigit(msg.Runes[0])
+
+		case msg.String() == "g":
+			// g alone still goes to the top, g<n> then jumps to host n
+			m.numberJump = true
+
+		case key.Matches(msg, m.keys.verbose):
+			h, ok := m.selectedHost()
+			if !ok {
+				return m, nil
+			}
+			m.verboseOnce = (m.forConnect(h).Verbose + 1) % (maxVerbose + 1)
+			m.verboseSet = true
+			return m, m.list.NewStatusMessage(statusMessageStyle("Next connection: " + verbosityLabel(m.verboseOnce)))
+
+		case key.Matches(msg, m.keys.subtitle):
+			mode := m.cycleSubtitle()
+			status := "Showing " + mode
+			if !m.settings.Autosave {
+				status += ", press s to keep it"
+			}
+			return m, tea.Batch(m.scheduleAutosave(), m.list.NewStatusMessage(statusMessageStyle(status)))
+
+		case key.Matches(msg, m.keys.toggleNumbers):
+			m.toggleNumbers()
+			return m, nil
+
+		case key.Matches(msg, m.keys.connect):
+			h, ok := m.selectedHost()
+			if !ok {
+				return m, nil
+			}
+			return m, m.connectTo(h, Profile{})
+
+		case key.Matches(msg, m.keys.connectProfile):
+			if _, ok := m.selectedHost(); !ok {
+				return m, nil
+			}
+			if len(m.profiles) == 0 {
+				return m, m.list.NewStatusMessage("No profiles defined in the config")
+			}
+			m.selector = newSelector("Connect with profile", slices.Sorted(maps.Keys(m.profiles)))
+			m.view = selectProfileView
+			return m, nil
+
 		case key.Matches(msg, m.keys.insertItem):
-			newHost := generateRandomHost()
-			m.hosts = append(m.hosts, newHost)
-			insCmd := m.list.InsertItem(0, newHost)
-			statusCmd := m.list.NewStatusMessage(statusMessageStyle("Added " + newHost.HostName))
-			return m, tea.Batch(insCmd, statusCmd)
+			if len(m.templates) > 0 {
+				m.openTemplates()
+				return m, nil
+			}
+			return m, m.openForm(-1)
+
+		case key.Matches(msg, m.keys.templates):
+			m.openTemplates()
+			return m, nil
+
+		case key.Matches(msg, m.keys.editItem):
+			h, ok := m.selectedHost()
+			if !ok {
+				return m, nil
+			}
+			if h.fromSSHConfig {
+				return m, m.list.NewStatusMessage(errorMessageStyle(h.Host + " is managed in ~/.ssh/config"))
+			}
+			return m, m.openForm(m.list.GlobalIndex())
 
 		case key.Matches(msg, m.keys.deleteItem):
-			currentItem := m.list.SelectedItem().(SSHHost)
-			// remove from item list
-			m.list.RemoveItem(m.list.Index())
-			// remove from hsots list for config save
-			newHosts := make([]SSHHost, 0, len(m.hosts))
-			for _, p := range m.hosts {
-				if p.Host != currentItem.Host {
-					newHosts = append(newHosts, p)
-				}
+			h, ok := m.selectedHost()
+			if !ok {
+				return m, nil
+			}
+			if h.fromSSHConfig {
+				return m, m.list.NewStatusMessage(errorMessageStyle(h.Host + " is managed in ~/.ssh/config"))
+			}
+			index := m.list.GlobalIndex()
+			deleted := m.hosts[index]
+			return m, m.commit(edit{index: index, before: &deleted})
+
+		case key.Matches(msg, m.keys.checkReach):
+			if h, ok := m.selectedHost(); ok {
+				return m, checkReachability(h)
 			}
-			m.hosts = newHosts
-			return m, tea.Batch()
+			return m, nil
+
+		case key.Matches(msg, m.keys.duplicates):
+			return m, m.openDuplicates()
+
+		case key.Matches(msg, m.keys.bulkDelete):
+			return m, m.bulkDelete()
+
+		case key.Matches(msg, m.keys.removeTag):
+			return m, m.openRemoveTag()
+
+		case key.Matches(msg, m.keys.bulkTag):
+			return m, m.openBulkTag()
+
+		case key.Matches(msg, m.keys.execRun):
+			return m, m.openExecRun()
+
+		case key.Matches(msg, m.keys.logs):
+			return m, m.openLogs()
+
+		case key.Matches(msg, m.keys.closeMaster):
+			return m, m.closeSelectedMaster()
+
+		case key.Matches(msg, m.keys.jumpHost):
+			return m, m.connectToJumpHost()
+
+		case key.Matches(msg, m.keys.sshG):
+			return m, m.openEffectiveConfig()
+
+		case key.Matches(msg, m.keys.ping):
+			if h, ok := m.selectedHost(); ok {
+				return m, tea.Batch(pingHost(h), m.list.NewStatusMessage("Pinging "+h.Host+"…"))
+			}
+			return m, nil
+
+		case key.Matches(msg, m.keys.checkAll):
+			hosts := m.listedHosts()
+			if len(hosts) == 0 {
+				return m, nil
+			}
+			m.batch = startReachBatch(hosts, m.batch.seq+1)
+			m.view = checkAllView
+			return m, m.batch.next()
+
+		case key.Matches(msg, m.keys.editConfig):
+			return m, editConfig()
+
+		case key.Matches(msg, m.keys.copyIdentity):
+			h, ok := m.selectedHost()
+			if !ok {
+				return m, nil
+			}
+			if h.IdentityFile == "" {
+				return m, m.list.NewStatusMessage(errorMessageStyle(h.Host + " has no identity file configured"))
+			}
+			return m, m.copyWithStatus("identity file path", expandPath(h.IdentityFile))
+
+		case key.Matches(msg, m.keys.rawTOML):
+			return m, m.openRawTOML()
+
+		case key.Matches(msg, m.keys.moveGroup):
+			h, ok := m.selectedHost()
+			if !ok {
+				return m, nil
+			}
+			if h.fromSSHConfig {
+				return m, m.list.NewStatusMessage(errorMessageStyle(h.Host + " is managed in ~/.ssh/config"))
+			}
+			m.openGroupMover(m.list.GlobalIndex())
+			return m, nil
+
+		case key.Matches(msg, m.keys.tagFilter):
+			return m, m.openTagFilter()
+
+		case key.Matches(msg, m.keys.portScan):
+			h, ok := m.selectedHost()
+			if !ok {
+				return m, nil
+			}
+			m.scan = portScanOverlay{host: h.Host, scanning: true, results: viewport.New(0, 0)}
+			m.sizeScanOverlay()
+			m.view = portScanView
+			return m, scanHostPorts(h)
+
+		case key.Matches(msg, m.keys.resources):
+			h, ok := m.selectedHost()
+			if !ok {
+				return m, nil
+			}
+			m.top = topDashboard{host: h, seq: m.top.seq + 1}
+			m.view = topView
+			return m, fetchTop(h, m.top.seq)
+
+		case key.Matches(msg, m.keys.dashboard):
+			m.view = dashboardView
+			return m, nil
+
+		case key.Matches(msg, m.keys.notesDown):
+			m.notes.ScrollDown(1)
+			return m, nil
+
+		case key.Matches(msg, m.keys.notesUp):
+			m.notes.ScrollUp(1)
+			return m, nil
+
+		case key.Matches(msg, m.keys.undo):
+			return m, m.undo()
+
+		case key.Matches(msg, m.keys.redo):
+			return m, m.redo()
 
 		case key.Matches(msg, m.keys.saveConfig):
-			config := &Config{Hosts: m.hosts}
-			saveConfig(config)
+			if m.configErr != nil {
+				return m, m.list.NewStatusMessage(errorMessageStyle("Not saving, fix the config file first: " + m.configErr.Error()))
+			}
+			m.autosave.pending = false
+			if err := saveConfig(m.config()); err != nil {
+				return m, m.list.NewStatusMessage(errorMessageStyle("Failed to save config: " + err.Error()))
+			}
+			m.autosave.unsaved = false
 			statusCmd := m.list.NewStatusMessage("Saved Config")
 			return m, tea.Batch(statusCmd)
 		}
 
 	case tea.WindowSizeMsg:
-		h, v := appStyle.GetFrameSize()
-		m.list.SetSize(msg.Width-h, msg.Height-v)
+		m.width, m.height = msg.Width, msg.Height
+		m.sizeList()
+		m.sizeScanOverlay()
+		m.sizeLogs()
+		m.sizeEffectiveConfig()
+	}
+
+	if msg, ok := msg.(tea.KeyMsg); ok && m.quitting(msg) && m.autosave.pending {
+		if cmd := m.flushOnQuit(); cmd != nil {
+			return m, cmd
+		}
 	}
 
+	// the filter runs in a command, so it gets the hosts as they are now
+	m.list.Filter = m.rankFilter()
 	newListModel, cmd := m.list.Update(msg)
 	m.list = newListModel
 	cmds = append(cmds, cmd)
+	if m.list.FilterState() != list.FilterApplied {
+		// the tag filter was reset or replaced by a typed search
+		m.tagQuery = ""
+	}
+	m.syncDetail()
 	return m, tea.Batch(cmds...)
 }
 
+// the list items mirror m.hosts index by index, so mutations go through these
+// helpers to keep both in sync without rebuilding the whole item slice
+
+func (m *model) insertHost(index int, h SSHHost) tea.Cmd {
+	m.hosts = slices.Insert(m.hosts, index, h)
+	return m.list.InsertItem(index, m.itemFor(h))
+}
+
+func (m *model) setHost(index int, h SSHHost) tea.Cmd {
+	m.hosts[index] = h
+	return m.list.SetItem(index, m.itemFor(h))
+}
+
+func (m *model) removeHost(index int) {
+	m.hosts = slices.Delete(m.hosts, index, index+1)
+	m.list.RemoveItem(index)
+	// RemoveItem drops the wrong filter match when the list is filtered
+	if m.list.FilterState() == list.FilterApplied {
+		m.list.Filter = m.rankFilter()
+		m.list.SetFilterText(m.list.FilterValue())
+	}
+}
+
+// selectedHost returns the host under the cursor, if any
+func (m model) selectedHost() (SSHHost, bool) {
+	item, ok := m.list.SelectedItem().(hostItem)
+	return item.SSHHost, ok
+}
+
 func (m model) View() string {
-	var details string
-	index := m.list.Index()
-	if index >= 0 && index < len(m.hosts) {
-		h := m.hosts[index]
-		// TODO: Replace with good looking input mask
-		details = fmt.Sprintf(
-			"Host: %s\nHostName: %s\nUser: %s\nDescription: %s",
-			h.Host, h.HostName, h.User, h.Desc,
-		)
-	} else {
-		details = "No item selected"
+	view := m.render()
+	if m.settings.VimMode {
+		view += "\n" + m.modeIndicator()
 	}
-	return lipgloss.JoinHorizontal(lipgloss.Center, appStyle.Render(m.list.View()), lipgloss.NewStyle().MarginLeft(2).Render(details))
+	if countdown := m.idleCountdown(); countdown != "" {
+		view += "\n" + lipgloss.NewStyle().PaddingLeft(appStyle.GetPaddingLeft()).Render(countdown)
+	}
+	return view
+}
+
+func (m model) render() string {
+	if m.view == confirmConnectView {
+		return appStyle.Render(m.hostKeyWarningView())
+	}
+	if m.view == dashboardView {
+		return appStyle.Render(m.dashboardView())
+	}
+	if m.view == selectProfileView {
+		return appStyle.Render(m.selector.view())
+	}
+	if m.view == removeTagView {
+		return appStyle.Render(m.selector.view())
+	}
+	if m.view == bulkConfirmView {
+		return appStyle.Render(m.bulkConfirmView())
+	}
+	if m.view == logView {
+		return appStyle.Render(m.logView())
+	}
+	if m.view == effectiveConfigView {
+		return appStyle.Render(m.effectiveConfigView())
+	}
+	if m.view == templatesView {
+		return appStyle.Render(m.selector.view() + "\n" + checkFixStyle.Render("e: edit template • n: new template • d: delete template"))
+	}
+	if m.view == topView {
+		return appStyle.Render(m.topView())
+	}
+	if m.view == checkAllView {
+		return appStyle.Render(m.checkAllView())
+	}
+	if m.view == rawTOMLView {
+		return appStyle.Render(m.rawTOMLView())
+	}
+	if m.view == formView {
+		return appStyle.Render(m.form.view())
+	}
+	if m.view == reloadView {
+		return appStyle.Render(m.reloadView())
+	}
+	if m.view == execView {
+		return appStyle.Render(m.execView())
+	}
+	if m.view == duplicatesView {
+		return appStyle.Render(m.duplicatesView())
+	}
+
+	listPanel := appStyle.Width(listWidth + 2*appStyle.GetHorizontalPadding()).Render(m.list.View())
+	if m.view == portScanView {
+		return lipgloss.JoinHorizontal(lipgloss.Top, listPanel, appStyle.Render(m.portScanView()))
+	}
+	if m.view == tagFilterView {
+		return lipgloss.JoinHorizontal(lipgloss.Top, listPanel, appStyle.Render(m.tagFilterView()))
+	}
+	if m.view == bulkTagView {
+		return lipgloss.JoinHorizontal(lipgloss.Top, listPanel, appStyle.Render(m.bulkTagView()))
+	}
+	if m.view == moveGroupView {
+		return lipgloss.JoinHorizontal(lipgloss.Top, listPanel, appStyle.Render(m.groupMoverView()))
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, listPanel, appStyle.Render(m.detailView()))
 }
 
 type Config struct {
-	Hosts []SSHHost `toml:"hosts"`
+	Settings Settings           `toml:"settings"`
+	Profiles map[string]Profile `toml:"profiles,omitempty"`
+	// values new hosts can start with, see HostTemplate
+	Templates map[string]HostTemplate `toml:"templates,omitempty"`
+	Hosts     []SSHHost               `toml:"hosts"`
+	// deploy steps for all hosts, see DeployConfig
+	Deploy DeployConfig `toml:"deploy,omitempty"`
+}
+
+// global options, stored in the [settings] table
+type Settings struct {
+	// compare the host key against known_hosts before connecting, so a
+	// changed key isn't hidden behind the alt screen
+	CheckKnownHosts bool `toml:"check_known_hosts"`
+	// list the hosts of ~/.ssh/config next to the ones managed here
+	ShowSSHConfig bool `toml:"show_ssh_config"`
+	// hosts not connected to for this many days show up as stale in the
+	// health dashboard
+	StaleDays int `toml:"stale_days"`
+	// render inline instead of in the alternate screen, same as -no-altscreen
+	NoAltScreen bool `toml:"no_altscreen"`
+	// number the hosts so typing the number connects, toggled with #
+	ShowNumbers bool `toml:"show_numbers"`
+	// show a NORMAL/INSERT mode indicator, i starts the search
+	VimMode bool `toml:"vim_mode,omitempty"`
+	// second line of the list items: description, address, tags or
+	// last_connected, cycled with D
+	Subtitle string `toml:"subtitle,omitempty"`
+	// most hosts shown per page, 20 if not set
+	PageSize int `toml:"page_size,omitempty"`
+	// Vault server signing certificates for vault_ssh_role, $VAULT_ADDR if
+	// not set
+	VaultAddr string `toml:"vault_addr,omitempty"`
+	// ssh binary to run instead of the ssh found on the PATH
+	SSHPath string `toml:"ssh_path,omitempty"`
+	// on Windows, connect with the ssh of WSL
+	UseWSL bool `toml:"use_wsl,omitempty"`
+	// title above the host list, "SSH Hosts" if not set
+	Title string `toml:"title,omitempty"`
+	// hide the title bar, the filter still shows there while typing
+	HideTitle bool `toml:"hide_title,omitempty"`
+	// backups of the config kept in backups/, 10 if not set, negative for none
+	KeepBackups int `toml:"keep_backups,omitempty"`
+	// where quickssh writes its logs, logs/ next to the config if not set
+	LogDir string `toml:"log_dir,omitempty"`
+	// logs older than this many days are removed by gc, 30 if not set
+	RetentionDays int `toml:"retention_days,omitempty"`
+
+	// commands run before and after every connection, see README
+	PreConnect  string `toml:"pre_connect,omitempty"`
+	PostConnect string `toml:"post_connect,omitempty"`
+	// seconds a hook may run, 30 if not set
+	HookTimeout int `toml:"hook_timeout,omitempty"`
+	// connect even if pre_connect fails
+	ContinueOnHookFailure bool `toml:"continue_on_hook_failure,omitempty"`
+
+	// append bulk changes like deleting all listed hosts to
+	// operations.log in log_dir
+	LogOperations bool `toml:"log_operations,omitempty"`
+
+	// save host changes without pressing s, once none were made for
+	// autosave_delay seconds (2 if not set)
+	Autosave      bool `toml:"autosave,omitempty"`
+	AutosaveDelay int  `toml:"autosave_delay,omitempty"`
+
+	// save and quit the TUI after this many seconds without a key press,
+	// for shared terminals
+	IdleQuitSeconds int `toml:"idle_quit_seconds,omitempty"`
 }
 
+// used when title is not set
+const defaultTitle = "SSH Hosts"
+
+func (s Settings) title() string {
+	if s.Title == "" {
+		return defaultTitle
+	}
+	return s.Title
+}
+
+// loadConfig reads the config file. A missing or empty file is a valid,
+// empty config.
 func loadConfig() (*Config, error) {
 	var config Config
 	if _, err := toml.DecodeFile(configFilePath, &config); err != nil {
+		if os.IsNotExist(err) {
+			return &config, nil
+		}
 		return nil, err
 	}
 	return &config, nil
 }
 
 func saveConfig(config *Config) error {
-	f, err := os.Create(configFilePath)
-	if err != nil {
-		return err
+	if err := backupConfig(config.Settings, time.Now()); err != nil {
+		return fmt.Errorf("failed to back up the config: %w", err)
 	}
-	defer f.Close()
-
-	encoder := toml.NewEncoder(f)
-	return encoder.Encode(config)
+	return writeConfigFile(configFilePath, config)
 }
 
 type SSHHost struct {
-	Host         string   `toml:"host"`
-	HostName     string   `toml:"hostname"`
-	User         string   `toml:"user"`
-	ForwardAgent bool     `toml:"forward_agent"`
-	Tags         []string `toml:"tags"`
-	Desc         string   `toml:"description"`
+	Host         string   `toml:"host" json:"host"`
+	HostName     string   `toml:"hostname" json:"hostname"`
+	User         string   `toml:"user" json:"user,omitempty"`
+	ForwardAgent bool     `toml:"forward_agent" json:"forward_agent,omitempty"`
+	Tags         []string `toml:"tags" json:"tags,omitempty"`
+	Desc         string   `toml:"description" json:"description,omitempty"`
+	Notes        string   `toml:"notes,omitempty" json:"notes,omitempty"`
+	// Group sorts the host into one named group, shown before the alias
+	Group string `toml:"group,omitempty" json:"group,omitempty"`
+
+	Port         int    `toml:"port,omitempty" json:"port,omitempty"`
+	IdentityFile string `toml:"identity_file,omitempty" json:"identity_file,omitempty"`
+	// CertificateFile is the signed *-cert.pub file matching IdentityFile
+	CertificateFile     string `toml:"certificate_file,omitempty" json:"certificate_file,omitempty"`
+	ProxyJump           string `toml:"proxy_jump,omitempty" json:"proxy_jump,omitempty"`
+	ServerAliveInterval int    `toml:"server_alive_interval,omitempty" json:"server_alive_interval,omitempty"`
+	ServerAliveCountMax int    `toml:"server_alive_count_max,omitempty" json:"server_alive_count_max,omitempty"`
+	// forwards use ssh_config syntax, e.g. "8080 localhost:80"
+	LocalForward   []string `toml:"local_forward,omitempty" json:"local_forward,omitempty"`
+	RemoteForward  []string `toml:"remote_forward,omitempty" json:"remote_forward,omitempty"`
+	ControlMaster  string   `toml:"control_master,omitempty" json:"control_master,omitempty"`
+	ControlPath    string   `toml:"control_path,omitempty" json:"control_path,omitempty"`
+	ControlPersist string   `toml:"control_persist,omitempty" json:"control_persist,omitempty"`
+	// TunnelTarget is forwarded from a free local port on connect, starting
+	// at TunnelPort or the target's own port, e.g. "localhost:5432"
+	TunnelTarget string `toml:"tunnel_target,omitempty" json:"tunnel_target,omitempty"`
+	TunnelPort   int    `toml:"tunnel_port,omitempty" json:"tunnel_port,omitempty"`
+	// Verbose adds -v up to three times, for debugging the connection
+	Verbose int `toml:"verbose,omitempty" json:"verbose,omitempty"`
+	// Deploy overrides the global [deploy] steps for this host
+	Deploy *DeployConfig `toml:"deploy,omitempty" json:"deploy,omitempty"`
+	// VaultSSHRole has Vault sign a certificate for every connection, as
+	// "<mount>/<role>" of its SSH secrets engine
+	VaultSSHRole string `toml:"vault_ssh_role,omitempty" json:"vault_ssh_role,omitempty"`
+	// PasswordCommand prints the password for password authentication,
+	// e.g. "pass show ssh/web1"
+	PasswordCommand string `toml:"password_command,omitempty" json:"password_command,omitempty"`
+	// PubkeyOnly and PasswordOnly limit ssh to one way of authenticating,
+	// for servers that misbehave when the other is tried first
+	PubkeyOnly   bool `toml:"pubkey_only,omitempty" json:"pubkey_only,omitempty"`
+	PasswordOnly bool `toml:"password_only,omitempty" json:"password_only,omitempty"`
+	// WireGuardPublicKey and WireGuardPresharedKey make export --format
+	// wireguard write a [Peer] section for the host
+	WireGuardPublicKey    string `toml:"wg_public_key,omitempty" json:"wg_public_key,omitempty"`
+	WireGuardPresharedKey string `toml:"wg_preshared_key,omitempty" json:"wg_preshared_key,omitempty"`
+	// Options are passed to ssh as -o key=value
+	Options map[string]string `toml:"options,omitempty" json:"options,omitempty"`
+	// Multiplexing shares one connection between sessions via ControlMaster
+	Multiplexing bool `toml:"multiplexing,omitempty" json:"multiplexing,omitempty"`
+	// Pinned hosts with Multiplexing get their connection opened on startup
+	Pinned bool `toml:"pinned,omitempty" json:"pinned,omitempty"`
+
+	// CloudID is "<provider>:<instance id>" for hosts added by cloud-sync
+	CloudID string `toml:"cloud_id,omitempty" json:"cloud_id,omitempty"`
+	// set by cloud-sync once the instance is gone, the host is kept
+	Decommissioned bool `toml:"decommissioned,omitempty" json:"decommissioned,omitempty"`
+
+	// set for hosts read live from ~/.ssh/config, they can't be edited
+	fromSSHConfig bool
 }
 
 func toItems(hosts []SSHHost) []list.Item {
-	var items []list.Item
-	for _, h := range hosts {
-		items = append(items, h)
+	items := make([]list.Item, len(hosts))
+	for i, h := range hosts {
+		items[i] = newHostItem(h)
 	}
 	return items
 }
 
+// withoutAliases drops the hosts whose alias is already taken in existing
+func withoutAliases(hosts, existing []SSHHost) []SSHHost {
+	var kept []SSHHost
+	for _, h := range hosts {
+		if !slices.ContainsFunc(existing, func(e SSHHost) bool { return e.Host == h.Host }) {
+			kept = append(kept, h)
+		}
+	}
+	return kept
+}
+
+// listedHosts returns the hosts of cfg followed by the ones from ssh_config
+// if enabled. The config hosts are returned even if ssh_config can't be read.
+func listedHosts(cfg *Config) ([]SSHHost, error) {
+	if !cfg.Settings.ShowSSHConfig {
+		return cfg.Hosts, nil
+	}
+	external, err := loadSSHConfigHosts()
+	return slices.Concat(cfg.Hosts, withoutAliases(external, cfg.Hosts)), err
+}
+
 func newModel() model {
 	listKeys := newListKeyMap()
 
 	// Load Config
-	cfg, err := loadConfig()
+	cfg, configErr := loadConfig()
+	var recovery configRecovery
+	if configErr != nil {
+		cfg = &Config{}
+		// a file cut off by a crash still has its first hosts
+		var parseErr toml.ParseError
+		if errors.As(configErr, &parseErr) {
+			if recovered, r, err := recoverConfigFile(); err == nil {
+				cfg, recovery, configErr = recovered, r, nil
+			}
+		}
+	}
+
+	// hosts from ssh_config are only listed, m.hosts stays the saved set
+	listed, err := listedHosts(cfg)
+	if err != nil {
+		fmt.Printf("Error reading ~/.ssh/config: %v\n", err)
+	}
+
+	history, err := loadHistory()
 	if err != nil {
-		fmt.Printf("Error loading config: %v\n", err)
+		fmt.Printf("Error loading history: %v\n", err)
 	}
+	lastSeen := lastConnected(history)
 
-	items := toItems(cfg.Hosts)
-	hosts := list.New(items, list.NewDefaultDelegate(), 0, 0)
-	hosts.Title = "Available Hosts"
+	items := toItems(listed)
+	hosts := list.New(items, newHostDelegate(cfg.Settings, lastSeen), 0, 0)
+	hosts.Title = cfg.Settings.title()
+	hosts.SetShowTitle(!cfg.Settings.HideTitle)
 	hosts.Styles.Title = titleStyle
+	setupPagination(&hosts)
 	hosts.AdditionalFullHelpKeys = func() []key.Binding {
 		return []key.Binding{
+			listKeys.connect,
+			listKeys.connectProfile,
 			listKeys.deleteItem,
 			listKeys.insertItem,
+			listKeys.editItem,
 			listKeys.saveConfig,
+			listKeys.undo,
+			listKeys.redo,
+			listKeys.checkReach,
+			listKeys.checkAll,
+			listKeys.dashboard,
+			listKeys.resources,
+			listKeys.notesDown,
+			listKeys.notesUp,
+			listKeys.copyConfigPath,
+			listKeys.copyIdentity,
+			listKeys.editConfig,
+			listKeys.toggleNumbers,
+			listKeys.verbose,
+			listKeys.subtitle,
+			listKeys.portScan,
+			listKeys.tagFilter,
+			listKeys.rawTOML,
+			listKeys.moveGroup,
+			listKeys.ping,
+			listKeys.execRun,
+			listKeys.duplicates,
+			listKeys.templates,
+			listKeys.bulkDelete,
+			listKeys.removeTag,
+			listKeys.bulkTag,
+			listKeys.logs,
+			listKeys.closeMaster,
+			listKeys.jumpHost,
+			listKeys.sshG,
 		}
 	}
 
-	return model{
-		list:  hosts,
-		keys:  listKeys,
-		hosts: cfg.Hosts,
+	// the alt screen hides anything printed before it, so startup problems
+	// are shown in the status bar instead
+	sshWarning := useSSHPath(cfg.Settings)
+	var initCmd tea.Cmd
+	if configErr != nil {
+		initCmd = hosts.NewStatusMessage(errorMessageStyle("Error loading config: " + configErr.Error()))
+	} else if recovery.backup != "" {
+		initCmd = hosts.NewStatusMessage(errorMessageStyle(recovery.String()))
+	} else if sshWarning != "" {
+		initCmd = hosts.NewStatusMessage(errorMessageStyle(sshWarning))
+	} else if warning := cfg.Settings.wslWarning(); warning != "" {
+		initCmd = hosts.NewStatusMessage(errorMessageStyle(warning))
+	} else if dups := findDuplicates(cfg.Hosts); len(dups) > 0 {
+		initCmd = hosts.NewStatusMessage(fmt.Sprintf("%d addresses are used by more than one host (≡), press M", len(dups)))
 	}
-}
 
-func generateRandomHost() SSHHost {
-	newHost := SSHHost{
-		Host:         string(rand.Intn(100)),
-		HostName:     string(rand.Intn(100)),
-		User:         string(rand.Intn(100)),
-		ForwardAgent: true,
-		Tags:         []string{},
-		Desc:         string(rand.Intn(100)),
+	m := model{
+		initCmd:    initCmd,
+		configErr:  configErr,
+		list:       hosts,
+		keys:       listKeys,
+		hosts:      cfg.Hosts,
+		settings:   cfg.Settings,
+		profiles:   cfg.Profiles,
+		templates:  cfg.Templates,
+		deploy:     cfg.Deploy,
+		reach:      make(map[string]reachState),
+		shared:     make(map[string]bool),
+		lastErrors: make(map[string]lastError),
+		notes:      viewport.New(0, 0),
+		lastSeen:   lastSeen,
+		history:    history,
+		idle:       idleTimer{lastInput: time.Now(), lastTick: time.Now()},
 	}
-
-	return newHost
+	m.refreshDuplicates()
+	return m
 }
 
 func main() {
-	InitConfigPath()
-	p := tea.NewProgram(newModel(), tea.WithAltScreen())
+	// ssh runs quickssh as its askpass helper for password_command
+	if file := os.Getenv(askpassFileEnv); file != "" {
+		os.Exit(runAskpass(file, os.Args[1:]))
+	}
+	// hidden from -help, checked before the flags are parsed
+	if len(os.Args) == 2 && (os.Args[1] == "-selfcheck" || os.Args[1] == "--selfcheck") {
+		if err := InitConfigPath(); err != nil {
+			fmt.Fprintln(os.Stderr, "Error initializing config:", err)
+			os.Exit(1)
+		}
+		os.Exit(runSelfcheck(os.Stdout, os.Stderr))
+	}
+
+	inventoryMode := flag.Bool("inventory-mode", false, "print the hosts as Ansible dynamic inventory JSON and exit")
+	// passed by ansible to inventory scripts
+	flag.Bool("list", false, "with -inventory-mode: print the whole inventory (default)")
+	inventoryHost := flag.String("host", "", "with -inventory-mode: print the variables of a single host")
+	jsonMode := flag.Bool("json", false, "print the hosts as JSON and exit")
+	importAnsible := flag.String("import-ansible", "", "add the hosts of an INI-style Ansible inventory file and exit")
+	noAltScreen := flag.Bool("no-altscreen", false, "render inline instead of in the alternate screen, e.g. for recording")
+	flag.Parse()
+
+	if err := InitConfigPath(); err != nil {
+		fmt.Println("Error initializing config:", err)
+		os.Exit(1)
+	}
+
+	if *inventoryMode {
+		os.Exit(runInventory(*inventoryHost))
+	}
+	if *jsonMode {
+		os.Exit(runJSON())
+	}
+	if *importAnsible != "" {
+		os.Exit(runImportAnsible(*importAnsible))
+	}
+
+	if flag.NArg() > 0 {
+		// the TUI shows the warning in its status bar instead
+		if config, err := loadConfig(); err == nil {
+			if warning := useSSHPath(config.Settings); warning != "" {
+				fmt.Fprintln(os.Stderr, "warning:", warning)
+			}
+		}
+		os.Exit(runCommand(flag.Arg(0), flag.Args()[1:]))
+	}
+
+	// asked before the alt screen hides the terminal
+	migrated, migrateErr := offerLegacyMigration()
+
+	m := newModel()
+	// the migration happened just now, its outcome goes before other
+	// startup notes
+	if migrateErr != nil {
+		m.initCmd = m.list.NewStatusMessage(errorMessageStyle(migrateErr.Error()))
+	} else if migrated != "" {
+		m.initCmd = m.list.NewStatusMessage(statusMessageStyle(migrated))
+	}
+	var options []tea.ProgramOption
+	if !*noAltScreen && !m.settings.NoAltScreen {
+		options = append(options, tea.WithAltScreen())
+	}
+	p := tea.NewProgram(m, options...)
+
+	// reload the config on SIGHUP, e.g. after a script changed it
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			p.Send(reloadRequestMsg{})
+		}
+	}()
 
 	if _, err := p.Run(); err != nil {
 		fmt.Println("Error running program:", err)
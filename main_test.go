@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// newLargeModel starts the TUI model on a config of n synthetic hosts
+func newLargeModel(tb testing.TB, n int) model {
+	tb.Helper()
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(syntheticConfig(n)); err != nil {
+		tb.Fatal(err)
+	}
+	return newTestModel(tb, buf.String())
+}
+
+// filterTargets are the strings the list hands to its filter
+func filterTargets(m model) []string {
+	items := m.list.Items()
+	targets := make([]string, len(items))
+	for i, item := range items {
+		targets[i] = item.FilterValue()
+	}
+	return targets
+}
+
+func TestFilterLatency(t *testing.T) {
+	m := newLargeModel(t, 1000)
+	filter := m.rankFilter()
+	targets := filterTargets(m)
+	tests := []struct {
+		term    string
+		matches int
+	}{
+		{"host-999", 1},
+		{"env-2", 250},
+		{"10.0.3.", 232},
+		{"nothing like it", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.term, func(t *testing.T) {
+			const runs = 10
+			start := time.Now()
+			var ranks int
+			for range runs {
+				ranks = len(filter(tt.term, targets))
+			}
+			if ranks != tt.matches {
+				t.Errorf("%d matches, want %d", ranks, tt.matches)
+			}
+			// a keystroke, generous for slow CI machines and -race
+			if took := time.Since(start) / runs; took > 100*time.Millisecond {
+				t.Errorf("filtering 1000 hosts took %s", took)
+			}
+		})
+	}
+}
+
+func BenchmarkFilter(b *testing.B) {
+	m := newLargeModel(b, 1000)
+	filter := m.rankFilter()
+	targets := filterTargets(m)
+	for b.Loop() {
+		filter("env-2", targets)
+	}
+}
+
+func BenchmarkToItems(b *testing.B) {
+	hosts := syntheticConfig(1000).Hosts
+	for b.Loop() {
+		toItems(hosts)
+	}
+}
+
+// an edit replaces the one item instead of rebuilding the list
+func BenchmarkSetHost(b *testing.B) {
+	m := newLargeModel(b, 1000)
+	h := m.hosts[500]
+	for b.Loop() {
+		h.Desc += "."
+		m.setHost(500, h)
+	}
+}
+
+func TestStartOnEmptyConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *string
+		wantErr bool
+	}{
+		{"created by InitConfigPath", nil, false},
+		{"empty", ptr(""), false},
+		{"blank lines and comments", ptr("\n# hosts go here\n\n"), false},
+		{"settings only", ptr("[settings]\npage_size = 10\n"), false},
+		{"not TOML", ptr("this isn't a config\n"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			old := configFilePath
+			t.Cleanup(func() { configFilePath = old })
+			if tt.config == nil {
+				dir := t.TempDir()
+				t.Setenv("XDG_CONFIG_HOME", dir)
+				t.Setenv("LOCALAPPDATA", dir)
+				if runtime.GOOS == "darwin" {
+					t.Setenv("HOME", dir)
+				}
+				if err := InitConfigPath(); err != nil {
+					t.Fatal(err)
+				}
+				if info, err := os.Stat(configFilePath); err != nil || info.Size() != 0 {
+					t.Fatalf("InitConfigPath didn't create an empty file: %v", err)
+				}
+			} else {
+				configFilePath = filepath.Join(t.TempDir(), ".config")
+				if err := os.WriteFile(configFilePath, []byte(*tt.config), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if _, err := loadConfig(); (err != nil) != tt.wantErr {
+				t.Errorf("loadConfig error = %v, want one: %t", err, tt.wantErr)
+			}
+			m := startTestModel(t)
+			if (m.configErr != nil) != tt.wantErr {
+				t.Errorf("configErr = %v, want one: %t", m.configErr, tt.wantErr)
+			}
+			if (m.Init() != nil) != tt.wantErr {
+				t.Errorf("status message on start: %t, want %t", m.Init() != nil, tt.wantErr)
+			}
+			if len(m.hosts) > 0 || len(m.list.Items()) > 0 {
+				t.Errorf("%d hosts and %d items, want none", len(m.hosts), len(m.list.Items()))
+			}
+		})
+	}
+}
+
+func ptr(s string) *string { return &s }
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/charmbracelet/x/term"
+)
+
+// config file of early versions, read from the working directory
+const legacyConfigName = ".mysshconfig.toml"
+
+// legacyMarkerPath is written once the legacy config was offered, so the
+// question isn't asked again
+func legacyMarkerPath() string {
+	return filepath.Join(filepath.Dir(configFilePath), "legacy_migrated")
+}
+
+// findLegacyConfigs returns the legacy config files in the working directory
+// and the home directory
+func findLegacyConfigs() []string {
+	var dirs []string
+	if wd, err := os.Getwd(); err == nil {
+		dirs = append(dirs, wd)
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, home)
+	}
+
+	var found []string
+	for _, dir := range dirs {
+		path := filepath.Join(dir, legacyConfigName)
+		// the working directory may be the home directory
+		if _, err := os.Stat(path); err != nil || slices.Contains(found, path) {
+			continue
+		}
+		found = append(found, path)
+	}
+	return found
+}
+
+// offerLegacyMigration asks once, on a terminal, whether to import the hosts
+// of legacy config files into the config. The legacy files are left as they
+// are. It returns a summary for the status bar, empty if nothing was
+// imported.
+func offerLegacyMigration() (string, error) {
+	if _, err := os.Stat(legacyMarkerPath()); err == nil || !term.IsTerminal(os.Stdin.Fd()) {
+		return "", nil
+	}
+	paths := findLegacyConfigs()
+	if len(paths) == 0 {
+		return "", nil
+	}
+	config, err := loadConfig()
+	if err != nil {
+		// the TUI reports the broken config, don't add to it
+		return "", nil
+	}
+
+	var summaries []string
+	for _, path := range paths {
+		var legacy Config
+		if _, err := toml.DecodeFile(path, &legacy); err != nil {
+			fmt.Fprintf(os.Stderr, "Skipping %s: %v\n", path, err)
+			continue
+		}
+		if len(legacy.Hosts) == 0 {
+			continue
+		}
+		if !confirm(fmt.Sprintf("Found %d hosts in %s from an older quickssh. Import them into %s?", len(legacy.Hosts), path, configFilePath)) {
+			continue
+		}
+		var skipped []string
+		config.Hosts, skipped = mergeHosts(config.Hosts, legacy.Hosts)
+		summary := fmt.Sprintf("Imported %d hosts from %s", len(legacy.Hosts)-len(skipped), path)
+		if len(skipped) > 0 {
+			summary += fmt.Sprintf(", skipped %d with an existing alias (%s)", len(skipped), strings.Join(skipped, ", "))
+		}
+		summaries = append(summaries, summary)
+	}
+
+	if len(summaries) > 0 {
+		if err := saveConfig(config); err != nil {
+			return "", fmt.Errorf("failed to save the imported hosts: %w", err)
+		}
+	}
+	if err := os.WriteFile(legacyMarkerPath(), []byte(strings.Join(paths, "\n")+"\n"), 0o644); err != nil {
+		return "", fmt.Errorf("failed to remember the legacy config was offered: %w", err)
+	}
+	return strings.Join(summaries, "; "), nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// MkdirErrors holds one error per path that couldn't be created, the other
+// paths were created
+type MkdirErrors []*os.PathError
+
+func (e MkdirErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// RemoteMkdir creates paths on h with their parents and sets mode on each,
+// one ssh connection per path, all at once. Failures are returned as
+// MkdirErrors in the order of paths.
+func RemoteMkdir(h SSHHost, paths []string, mode os.FileMode) error {
+	errs := make([]error, len(paths))
+	var wg sync.WaitGroup
+	for i, path := range paths {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			quoted := shellQuote(path)
+			command := fmt.Sprintf("mkdir -p -- %s && chmod %o -- %s", quoted, mode.Perm(), quoted)
+			_, errs[i] = runRemote(h, command)
+		}()
+	}
+	wg.Wait()
+
+	var failed MkdirErrors
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, &os.PathError{Op: "mkdir", Path: paths[i], Err: err})
+		}
+	}
+	if failed != nil {
+		return failed
+	}
+	return nil
+}
+
+func runMkdir(args []string) int {
+	fs := flag.NewFlagSet("mkdir", flag.ExitOnError)
+	alias := fs.String("host", "", "alias of the host")
+	var paths []string
+	fs.Var((*stringsFlag)(&paths), "remote", "directory to create, can be repeated")
+	modeFlag := fs.String("mode", "0755", "permissions of the directories, in octal")
+	fs.Parse(args)
+
+	if len(paths) == 0 {
+		fmt.Fprintln(os.Stderr, "no directory given, use --remote <path>")
+		return 2
+	}
+	mode, err := strconv.ParseUint(*modeFlag, 8, 32)
+	if err != nil || mode > 0o777 {
+		fmt.Fprintf(os.Stderr, "invalid mode %q, use octal like 0755\n", *modeFlag)
+		return 2
+	}
+	h, err := loadHost(*alias)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	err = RemoteMkdir(h, paths, os.FileMode(mode))
+	var failed MkdirErrors
+	if err != nil && !errors.As(err, &failed) {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	for _, path := range paths {
+		i := slices.IndexFunc(failed, func(e *os.PathError) bool { return e.Path == path })
+		if i >= 0 {
+			fmt.Fprintf(os.Stderr, "Failed %s: %v\n", path, failed[i].Err)
+		} else {
+			fmt.Printf("Created %s\n", path)
+		}
+	}
+	if len(failed) > 0 {
+		return 1
+	}
+	return 0
+}
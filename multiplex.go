@@ -0,0 +1,265 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/creack/pty"
+)
+
+var (
+	paneStyle       = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("#444444"))
+	activePaneStyle = paneStyle.BorderForeground(lipgloss.Color("#25A065"))
+
+	nextPaneKey  = key.NewBinding(key.WithKeys("ctrl+o"), key.WithHelp("ctrl+o", "next pane"))
+	quitPanesKey = key.NewBinding(key.WithKeys("ctrl+q"), key.WithHelp("ctrl+q", "quit"))
+)
+
+// PtySession is an ssh process running in a pseudo terminal. A goroutine
+// reads its output into a channel, which the program drains one message at
+// a time.
+type PtySession struct {
+	host   SSHHost
+	cmd    *exec.Cmd
+	pty    *os.File
+	output chan []byte
+	screen *paneScreen
+	closed bool
+	err    error
+}
+
+// sent for each chunk of output of session
+type ptyOutputMsg struct {
+	session int
+	data    []byte
+}
+
+// sent once the ssh process of session has exited
+type ptyClosedMsg struct {
+	session int
+	err     error
+}
+
+// startPtySession connects to h in a new pty of the given size
+func startPtySession(h SSHHost, cols, rows int) (*PtySession, error) {
+	cmd := BuildSSHCommand(h)
+	f, err := pty.StartWithSize(cmd, &pty.Winsize{Cols: uint16(cols), Rows: uint16(rows)})
+	if err != nil {
+		return nil, err
+	}
+
+	s := &PtySession{host: h, cmd: cmd, pty: f, output: make(chan []byte, 64), screen: newPaneScreen(cols, rows)}
+	go func() {
+		defer close(s.output)
+		for {
+			buf := make([]byte, 4096)
+			n, err := f.Read(buf)
+			if n > 0 {
+				s.output <- buf[:n]
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return s, nil
+}
+
+// wait returns the next output of the session at index i
+func (s *PtySession) wait(i int) tea.Cmd {
+	return func() tea.Msg {
+		data, ok := <-s.output
+		if !ok {
+			return ptyClosedMsg{session: i, err: s.cmd.Wait()}
+		}
+		return ptyOutputMsg{session: i, data: data}
+	}
+}
+
+func (s *PtySession) resize(cols, rows int) {
+	s.screen.resize(cols, rows)
+	if !s.closed {
+		pty.Setsize(s.pty, &pty.Winsize{Cols: uint16(cols), Rows: uint16(rows)})
+	}
+}
+
+// close hangs up the terminal, which ends the ssh process
+func (s *PtySession) close() {
+	if !s.closed {
+		s.pty.Close()
+	}
+}
+
+type multiplexModel struct {
+	hosts    []SSHHost
+	sessions []*PtySession
+	active   int
+	width    int
+	height   int
+}
+
+func (m multiplexModel) Init() tea.Cmd { return nil }
+
+// paneSize returns the terminal size inside each pane
+func (m multiplexModel) paneSize() (int, int) {
+	// the border takes two columns and rows, the title and help one row each
+	cols := max(m.width/len(m.hosts)-2, 1)
+	rows := max(m.height-4, 1)
+	return cols, rows
+}
+
+func (m multiplexModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		cols, rows := m.paneSize()
+		if m.sessions != nil {
+			for _, s := range m.sessions {
+				s.resize(cols, rows)
+			}
+			return m, nil
+		}
+
+		// the sessions start once the pane size is known
+		var cmds []tea.Cmd
+		for i, h := range m.hosts {
+			s, err := startPtySession(h, cols, rows)
+			if err != nil {
+				s = &PtySession{host: h, screen: newPaneScreen(cols, rows), closed: true, err: err}
+			} else {
+				cmds = append(cmds, s.wait(i))
+			}
+			m.sessions = append(m.sessions, s)
+		}
+		return m, tea.Batch(cmds...)
+
+	case ptyOutputMsg:
+		s := m.sessions[msg.session]
+		s.screen.Write(msg.data)
+		return m, s.wait(msg.session)
+
+	case ptyClosedMsg:
+		s := m.sessions[msg.session]
+		s.closed, s.err = true, msg.err
+		return m, nil
+
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, quitPanesKey):
+			for _, s := range m.sessions {
+				s.close()
+			}
+			return m, tea.Quit
+		case key.Matches(msg, nextPaneKey):
+			if len(m.sessions) > 0 {
+				m.active = (m.active + 1) % len(m.sessions)
+			}
+			return m, nil
+		}
+		if m.active < len(m.sessions) && !m.sessions[m.active].closed {
+			m.sessions[m.active].pty.Write(keyBytes(msg))
+		}
+	}
+	return m, nil
+}
+
+func (m multiplexModel) View() string {
+	if m.sessions == nil {
+		return "Connecting..."
+	}
+
+	panes := make([]string, len(m.sessions))
+	for i, s := range m.sessions {
+		title := titleStyle.Render(s.host.Host)
+		switch {
+		case s.err != nil:
+			title += " " + errorMessageStyle(s.err.Error())
+		case s.closed:
+			title += " " + statusMessageStyle("session ended")
+		}
+
+		style := paneStyle
+		if i == m.active {
+			style = activePaneStyle
+		}
+		cols, _ := m.paneSize()
+		title = lipgloss.NewStyle().MaxWidth(cols).Render(title)
+		panes[i] = style.Render(title + "\n" + s.screen.View(i == m.active && !s.closed))
+	}
+
+	help := checkFixStyle.Render(fmt.Sprintf("%s: %s • %s: %s",
+		nextPaneKey.Help().Key, nextPaneKey.Help().Desc, quitPanesKey.Help().Key, quitPanesKey.Help().Desc))
+	return lipgloss.JoinHorizontal(lipgloss.Top, panes...) + "\n" + help
+}
+
+// keyBytes turns a key press back into the bytes a terminal would send
+func keyBytes(msg tea.KeyMsg) []byte {
+	var b []byte
+	if msg.Alt {
+		b = append(b, 0x1b)
+	}
+	switch msg.Type {
+	case tea.KeyRunes:
+		return append(b, string(msg.Runes)...)
+	case tea.KeySpace:
+		return append(b, ' ')
+	case tea.KeyUp:
+		return append(b, "\x1b[A"...)
+	case tea.KeyDown:
+		return append(b, "\x1b[B"...)
+	case tea.KeyRight:
+		return append(b, "\x1b[C"...)
+	case tea.KeyLeft:
+		return append(b, "\x1b[D"...)
+	case tea.KeyHome:
+		return append(b, "\x1b[H"...)
+	case tea.KeyEnd:
+		return append(b, "\x1b[F"...)
+	case tea.KeyPgUp:
+		return append(b, "\x1b[5~"...)
+	case tea.KeyPgDown:
+		return append(b, "\x1b[6~"...)
+	case tea.KeyDelete:
+		return append(b, "\x1b[3~"...)
+	case tea.KeyShiftTab:
+		return append(b, "\x1b[Z"...)
+	}
+	// the remaining types are control characters and map to themselves
+	if msg.Type >= 0 && msg.Type < 0x20 || msg.Type == 0x7f {
+		return append(b, byte(msg.Type))
+	}
+	return nil
+}
+
+func runMultiplex(args []string) int {
+	fs := flag.NewFlagSet("multiplex", flag.ExitOnError)
+	hostList := fs.String("hosts", "", "comma separated aliases to open side by side")
+	fs.Parse(args)
+
+	if *hostList == "" {
+		fmt.Fprintln(os.Stderr, "usage: quickssh multiplex --hosts host1,host2,...")
+		return 2
+	}
+	var hosts []SSHHost
+	for _, alias := range strings.Split(*hostList, ",") {
+		h, err := loadHost(strings.TrimSpace(alias))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		hosts = append(hosts, h)
+	}
+
+	p := tea.NewProgram(multiplexModel{hosts: hosts}, tea.WithAltScreen())
+	if _, err := p.Run(); err != nil {
+		fmt.Fprintln(os.Stderr, "Error running program:", err)
+		return 1
+	}
+	return 0
+}
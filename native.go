@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// nativeClientConfig builds an in-process ssh client config for h, used for
+// non-interactive commands that don't need the ssh binary. Keys are taken
+// from the agent and the host's identity file, paired with its certificate
+// when one is configured.
+func nativeClientConfig(h SSHHost) (*ssh.ClientConfig, error) {
+	hostKeyCallback, err := knownhosts.New(knownHostsPath())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read known_hosts: %w", err)
+	}
+
+	var signers []ssh.Signer
+	if h.IdentityFile != "" {
+		signer, err := loadSigner(h)
+		if err != nil {
+			return nil, err
+		}
+		signers = append(signers, signer)
+	}
+
+	// one method for all keys, the client doesn't try a second publickey
+	// method after the first one failed
+	auth := []ssh.AuthMethod{ssh.PublicKeysCallback(func() ([]ssh.Signer, error) {
+		return append(signers, agentSigners()...), nil
+	})}
+
+	user := h.User
+	if user == "" {
+		user = os.Getenv("USER")
+	}
+
+	return &ssh.ClientConfig{
+		User:            user,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         10 * time.Second,
+	}, nil
+}
+
+// nativeAgent is the one agent connection of the process. Its signers sign
+// through it, so it stays open for as long as any client may use them.
+var nativeAgent struct {
+	sync.Mutex
+	sock   string
+	conn   net.Conn
+	client agent.ExtendedAgent
+}
+
+// agentSigners returns the keys of the agent at SSH_AUTH_SOCK, none if
+// there is no agent
+func agentSigners() []ssh.Signer {
+	sock := os.Getenv("SSH_AUTH_SOCK")
+	if sock == "" {
+		return nil
+	}
+
+	nativeAgent.Lock()
+	defer nativeAgent.Unlock()
+	if nativeAgent.conn != nil && nativeAgent.sock != sock {
+		nativeAgent.conn.Close()
+		nativeAgent.conn = nil
+	}
+	if nativeAgent.conn == nil {
+		conn, err := net.Dial("unix", sock)
+		if err != nil {
+			return nil
+		}
+		nativeAgent.sock, nativeAgent.conn, nativeAgent.client = sock, conn, agent.NewClient(conn)
+	}
+
+	signers, err := nativeAgent.client.Signers()
+	if err != nil {
+		// the agent went away, dial again next time
+		nativeAgent.conn.Close()
+		nativeAgent.conn = nil
+		return nil
+	}
+	return signers
+}
+
+// loadSigner reads the identity file of h and wraps it in a certificate
+// signer if the host has a certificate file
+func loadSigner(h SSHHost) (ssh.Signer, error) {
+	keyBytes, err := os.ReadFile(expandPath(h.IdentityFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read identity file: %w", err)
+	}
+	signer, err := ssh.ParsePrivateKey(keyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse identity file: %w", err)
+	}
+
+	if h.CertificateFile == "" {
+		return signer, nil
+	}
+	cert, err := loadCertificate(h.CertificateFile)
+	if err != nil {
+		return nil, err
+	}
+	return ssh.NewCertSigner(cert, signer)
+}
+
+// dialNative opens an in-process ssh connection to h
+func dialNative(h SSHHost) (*ssh.Client, error) {
+	config, err := nativeClientConfig(h)
+	if err != nil {
+		return nil, err
+	}
+	return ssh.Dial("tcp", net.JoinHostPort(h.address(), strconv.Itoa(h.port())), config)
+}
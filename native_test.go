@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"net"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+func newTestKey(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// startTestServer runs an ssh server on localhost accepting only key, and
+// writes its host key to known_hosts in home
+func startTestServer(t *testing.T, home string, key ed25519.PrivateKey) (string, int) {
+	t.Helper()
+	allowed, err := ssh.NewPublicKey(key.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(_ ssh.ConnMetadata, k ssh.PublicKey) (*ssh.Permissions, error) {
+			if bytes.Equal(k.Marshal(), allowed.Marshal()) {
+				return nil, nil
+			}
+			return nil, ssh.ErrNoAuth
+		},
+	}
+	hostSigner, err := ssh.NewSignerFromKey(newTestKey(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.AddHostKey(hostSigner)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_, chans, reqs, err := ssh.NewServerConn(conn, config)
+				if err != nil {
+					return
+				}
+				go ssh.DiscardRequests(reqs)
+				for ch := range chans {
+					ch.Reject(ssh.Prohibited, "test server")
+				}
+			}()
+		}
+	}()
+
+	addr := l.Addr().(*net.TCPAddr)
+	line := knownhosts.Line([]string{knownhosts.Normalize(addr.String())}, hostSigner.PublicKey())
+	os.MkdirAll(filepath.Join(home, ".ssh"), 0o700)
+	if err := os.WriteFile(filepath.Join(home, ".ssh", "known_hosts"), []byte(line+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return addr.IP.String(), addr.Port
+}
+
+// startTestAgent serves an agent holding keys and points SSH_AUTH_SOCK to
+// it. Returned is the number of connections the agent accepted.
+func startTestAgent(t *testing.T, keys ...ed25519.PrivateKey) *atomic.Int32 {
+	t.Helper()
+	keyring := agent.NewKeyring()
+	for _, key := range keys {
+		if err := keyring.Add(agent.AddedKey{PrivateKey: key}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sock := filepath.Join(t.TempDir(), "agent.sock")
+	l, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	var accepted atomic.Int32
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			accepted.Add(1)
+			go agent.ServeAgent(keyring, conn)
+		}
+	}()
+	t.Setenv("SSH_AUTH_SOCK", sock)
+	return &accepted
+}
+
+func writeTestIdentity(t *testing.T, dir string, key ed25519.PrivateKey) string {
+	t.Helper()
+	block, err := ssh.MarshalPrivateKey(key, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "id_ed25519")
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDialNativeAuth(t *testing.T) {
+	tests := []struct {
+		name string
+		// whether the accepted key is in the identity file and the agent,
+		// the other one holds an unrelated key
+		inFile, inAgent bool
+		wantErr         bool
+	}{
+		{"agent only", false, true, false},
+		{"identity file only", true, false, false},
+		{"identity file and agent", true, true, false},
+		{"neither", false, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+			key, other := newTestKey(t), newTestKey(t)
+			address, port := startTestServer(t, home, key)
+
+			fileKey, agentKey := other, other
+			if tt.inFile {
+				fileKey = key
+			}
+			if tt.inAgent {
+				agentKey = key
+			}
+			startTestAgent(t, agentKey)
+			h := SSHHost{Host: "test", HostName: address, Port: port, User: "me", IdentityFile: writeTestIdentity(t, home, fileKey)}
+
+			client, err := dialNative(h)
+			if client != nil {
+				client.Close()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("dialNative: err = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDialNativeReusesAgent(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	key := newTestKey(t)
+	address, port := startTestServer(t, home, key)
+	accepted := startTestAgent(t, key)
+	h := SSHHost{Host: "test", HostName: address, Port: port, User: "me"}
+
+	for range 5 {
+		client, err := dialNative(h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		client.Close()
+	}
+	if n := accepted.Load(); n != 1 {
+		t.Errorf("agent accepted %d connections for 5 dials, want 1", n)
+	}
+}
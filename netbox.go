@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var netboxClient = &http.Client{Timeout: 30 * time.Second}
+
+// NetBox endpoints listing the devices and virtual machines
+var netboxEndpoints = []string{"/api/dcim/devices/", "/api/virtualization/virtual-machines/"}
+
+// netboxRecord holds the fields of a device or VM that make up a host
+type netboxRecord struct {
+	Name      string `json:"name"`
+	PrimaryIP *struct {
+		Address string `json:"address"`
+	} `json:"primary_ip"`
+	CustomFields map[string]any `json:"custom_fields"`
+	Tags         []struct {
+		Name string `json:"name"`
+	} `json:"tags"`
+}
+
+// FetchNetBoxHosts reads the devices and virtual machines of a NetBox
+// instance, narrowed by filters like site or role, and makes a host of each
+// one with a name and a primary IP. The ssh_user custom field becomes the
+// user and NetBox tags become tags.
+func FetchNetBoxHosts(baseURL, token string, filters map[string]string) ([]SSHHost, error) {
+	query := url.Values{"limit": {"1000"}}
+	for k, v := range filters {
+		query.Set(k, v)
+	}
+
+	var hosts []SSHHost
+	for _, endpoint := range netboxEndpoints {
+		next := strings.TrimRight(baseURL, "/") + endpoint + "?" + query.Encode()
+		for next != "" {
+			var page struct {
+				Next    string         `json:"next"`
+				Results []netboxRecord `json:"results"`
+			}
+			if err := netboxGet(next, token, &page); err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", endpoint, err)
+			}
+			for _, r := range page.Results {
+				if h, ok := r.host(); ok {
+					hosts = append(hosts, h)
+				}
+			}
+			next = page.Next
+		}
+	}
+	return hosts, nil
+}
+
+// host is false for records without a name or primary IP
+func (r netboxRecord) host() (SSHHost, bool) {
+	if r.Name == "" || r.PrimaryIP == nil || r.PrimaryIP.Address == "" {
+		return SSHHost{}, false
+	}
+	// addresses come with their prefix length, e.g. 10.0.0.5/24
+	addr, _, _ := strings.Cut(r.PrimaryIP.Address, "/")
+	h := SSHHost{
+		Host:     strings.Join(strings.Fields(r.Name), "-"),
+		HostName: addr,
+	}
+	if user, ok := r.CustomFields["ssh_user"].(string); ok {
+		h.User = user
+	}
+	for _, tag := range r.Tags {
+		h.Tags = append(h.Tags, tag.Name)
+	}
+	return h, true
+}
+
+func netboxGet(url, token string, v any) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Token "+token)
+	}
+
+	resp, err := netboxClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("netbox returned %s", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// file in the log dir that log_operations appends to
+const operationsLogName = "operations.log"
+
+// logOperation appends a line about a change to many hosts to the operations
+// log, if log_operations is set
+func logOperation(s Settings, op string, aliases []string) error {
+	if !s.LogOperations {
+		return nil
+	}
+	if err := os.MkdirAll(s.logDir(), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(s.logDir(), operationsLogName), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(f, "%s %s (%d hosts): %s\n", time.Now().Format(time.RFC3339), op, len(aliases), strings.Join(aliases, ", "))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
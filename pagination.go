@@ -0,0 +1,25 @@
+package main
+
+import (
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/paginator"
+)
+
+// used when page_size is not set
+const defaultPageSize = 20
+
+func (s Settings) pageSize() int {
+	if s.PageSize <= 0 {
+		return defaultPageSize
+	}
+	return s.PageSize
+}
+
+// setupPagination numbers the pages instead of showing dots and adds < and >
+// to the list's page keys
+func setupPagination(l *list.Model) {
+	l.Paginator.Type = paginator.Arabic
+	l.Paginator.ArabicFormat = "Page %d/%d"
+	l.KeyMap.NextPage.SetKeys(append(l.KeyMap.NextPage.Keys(), ">")...)
+	l.KeyMap.PrevPage.SetKeys(append(l.KeyMap.PrevPage.Keys(), "<")...)
+}
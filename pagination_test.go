@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestPageSizeFilterOnLastPage(t *testing.T) {
+	config := syntheticConfig(30)
+	config.Settings.PageSize = 5
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(config); err != nil {
+		t.Fatal(err)
+	}
+	m := newTestModel(t, buf.String())
+
+	var tm tea.Model = m
+	for range 6 {
+		tm, _ = tm.Update(keyPress(">"))
+	}
+	m = tm.(model)
+	if m.list.Paginator.PerPage != 5 || !m.list.Paginator.OnLastPage() {
+		t.Fatalf("on page %d/%d with %d per page, want the last of 6 with 5", m.list.Paginator.Page+1, m.list.Paginator.TotalPages, m.list.Paginator.PerPage)
+	}
+
+	// the list repaginates itself here, without going through Update
+	m.list.SetFilterText("host-2")
+	p := m.list.Paginator
+	if p.PerPage != 5 {
+		t.Errorf("%d hosts per page after filtering, want 5", p.PerPage)
+	}
+	if p.Page != 0 {
+		t.Errorf("on page %d/%d after filtering, want the first", p.Page+1, p.TotalPages)
+	}
+	if p.ItemsOnPage(len(m.list.VisibleItems())) == 0 {
+		t.Errorf("page %d/%d shows none of the %d matches", p.Page+1, p.TotalPages, len(m.list.VisibleItems()))
+	}
+
+	m.list.ResetFilter()
+	if p := m.list.Paginator; p.PerPage != 5 || p.TotalPages != 6 {
+		t.Errorf("%d pages of %d hosts after clearing the search, want 6 of 5", p.TotalPages, p.PerPage)
+	}
+}
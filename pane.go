@@ -0,0 +1,214 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// lines kept per pane once output scrolls off the top
+const paneScrollback = 1000
+
+var cursorStyle = lipgloss.NewStyle().Reverse(true)
+
+// parser states of paneScreen
+const (
+	paneText = iota
+	paneEscape
+	paneCSI
+	paneOSC
+	paneCharset
+)
+
+// paneScreen is a minimal terminal for the output of a pty session. It keeps
+// the text and handles line editing and cursor movement, colours and other
+// attributes are dropped, so full screen programs won't look right.
+type paneScreen struct {
+	lines    [][]rune
+	row, col int
+	width    int
+	height   int
+
+	state   int
+	params  []byte
+	partial []byte
+}
+
+func newPaneScreen(width, height int) *paneScreen {
+	s := &paneScreen{lines: [][]rune{nil}}
+	s.resize(width, height)
+	return s
+}
+
+func (s *paneScreen) resize(width, height int) {
+	s.width, s.height = max(width, 1), max(height, 1)
+}
+
+// Write feeds output of the session into the screen
+func (s *paneScreen) Write(data []byte) (int, error) {
+	n := len(data)
+	data = append(s.partial, data...)
+	s.partial = nil
+	for len(data) > 0 {
+		r, size := utf8.DecodeRune(data)
+		if r == utf8.RuneError && !utf8.FullRune(data) {
+			// the rest of the rune comes with the next read
+			s.partial = append([]byte(nil), data...)
+			break
+		}
+		s.put(r)
+		data = data[size:]
+	}
+	return n, nil
+}
+
+func (s *paneScreen) put(r rune) {
+	switch s.state {
+	case paneEscape:
+		switch r {
+		case '[':
+			s.state, s.params = paneCSI, s.params[:0]
+		case ']':
+			s.state = paneOSC
+		case '(', ')':
+			s.state = paneCharset
+		default:
+			s.state = paneText
+		}
+		return
+	case paneCSI:
+		if r >= 0x40 && r <= 0x7e {
+			s.csi(r)
+			s.state = paneText
+		} else {
+			s.params = append(s.params, byte(r))
+		}
+		return
+	case paneOSC:
+		// ends with BEL or ESC \, the backslash is then dropped as an escape
+		switch r {
+		case '\a':
+			s.state = paneText
+		case 0x1b:
+			s.state = paneEscape
+		}
+		return
+	case paneCharset:
+		s.state = paneText
+		return
+	}
+
+	switch r {
+	case 0x1b:
+		s.state = paneEscape
+	case '\r':
+		s.col = 0
+	case '\n':
+		s.moveTo(s.row+1, s.col)
+	case '\b':
+		s.col = max(s.col-1, 0)
+	case '\t':
+		s.col = min((s.col/8+1)*8, max(s.width-1, 0))
+	default:
+		if r < 0x20 || r == 0x7f {
+			return
+		}
+		if s.col >= s.width {
+			s.moveTo(s.row+1, 0)
+		}
+		line := s.lines[s.row]
+		for len(line) <= s.col {
+			line = append(line, ' ')
+		}
+		line[s.col] = r
+		s.lines[s.row] = line
+		s.col++
+	}
+}
+
+// csi handles the control sequences that move the cursor or erase text
+func (s *paneScreen) csi(final rune) {
+	var args []int
+	for _, p := range strings.Split(strings.TrimLeft(string(s.params), "?"), ";") {
+		n, _ := strconv.Atoi(p)
+		args = append(args, n)
+	}
+	arg := func(i, def int) int {
+		if i < len(args) && args[i] > 0 {
+			return args[i]
+		}
+		return def
+	}
+
+	top := max(len(s.lines)-s.height, 0)
+	switch final {
+	case 'A':
+		s.moveTo(max(s.row-arg(0, 1), top), s.col)
+	case 'B':
+		s.moveTo(s.row+arg(0, 1), s.col)
+	case 'C':
+		s.col = min(s.col+arg(0, 1), max(s.width-1, 0))
+	case 'D':
+		s.col = max(s.col-arg(0, 1), 0)
+	case 'G':
+		s.col = arg(0, 1) - 1
+	case 'H', 'f':
+		s.moveTo(top+arg(0, 1)-1, arg(1, 1)-1)
+	case 'K':
+		line := s.lines[s.row]
+		switch arg(0, 0) {
+		case 0:
+			s.lines[s.row] = line[:min(s.col, len(line))]
+		case 1:
+			for i := 0; i < min(s.col+1, len(line)); i++ {
+				line[i] = ' '
+			}
+		case 2:
+			s.lines[s.row] = nil
+		}
+	case 'J':
+		if arg(0, 0) >= 2 {
+			s.lines, s.row, s.col = [][]rune{nil}, 0, 0
+		} else if arg(0, 0) == 0 {
+			s.lines[s.row] = s.lines[s.row][:min(s.col, len(s.lines[s.row]))]
+			s.lines = s.lines[:s.row+1]
+		}
+	}
+}
+
+// moveTo puts the cursor on row, adding lines as needed and dropping the
+// oldest ones beyond the scrollback
+func (s *paneScreen) moveTo(row, col int) {
+	for len(s.lines) <= row {
+		s.lines = append(s.lines, nil)
+	}
+	s.row, s.col = max(row, 0), max(col, 0)
+	if drop := len(s.lines) - paneScrollback; drop > 0 {
+		s.lines = s.lines[drop:]
+		s.row -= drop
+	}
+}
+
+// View renders the last height lines, with the cursor if showCursor is set
+func (s *paneScreen) View(showCursor bool) string {
+	top := max(len(s.lines)-s.height, 0)
+	rendered := make([]string, 0, s.height)
+	for row := top; row < top+s.height; row++ {
+		var line []rune
+		if row < len(s.lines) {
+			line = s.lines[row]
+		}
+		if len(line) > s.width {
+			line = line[:s.width]
+		}
+		text := string(line) + strings.Repeat(" ", s.width-len(line))
+		if showCursor && row == s.row && s.col < s.width {
+			padded := []rune(text)
+			text = string(padded[:s.col]) + cursorStyle.Render(string(padded[s.col])) + string(padded[s.col+1:])
+		}
+		rendered = append(rendered, text)
+	}
+	return strings.Join(rendered, "\n")
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// set in the environment of ssh to make quickssh its askpass helper
+const askpassFileEnv = "QUICKSSH_ASKPASS_FILE"
+
+// ResolvePassword runs the password_command of h and returns what it printed,
+// without surrounding whitespace. The command may ask for input, e.g. to
+// unlock the password manager.
+func ResolvePassword(h SSHHost) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := shellCommand(context.Background(), h.PasswordCommand)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("password_command failed: %w: %s", err, lastLine(msg))
+		}
+		return "", fmt.Errorf("password_command failed: %w", err)
+	}
+	password := strings.TrimSpace(stdout.String())
+	if password == "" {
+		return "", errors.New("password_command printed nothing")
+	}
+	return password, nil
+}
+
+// setupAskpass resolves the password of h and points ssh at quickssh itself
+// to read it back from a private temp file. The returned function removes
+// the file.
+func setupAskpass(c *connectExec) (cleanup func(), err error) {
+	password, err := ResolvePassword(c.host)
+	if err != nil {
+		return nil, err
+	}
+	self, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.CreateTemp("", "quickssh-askpass-*")
+	if err != nil {
+		return nil, err
+	}
+	cleanup = func() { os.Remove(f.Name()) }
+	// CreateTemp already makes it readable by the owner only
+	_, err = f.WriteString(password)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		cleanup()
+		return nil, err
+	}
+
+	// keep an environment the caller already set for ssh
+	env := c.Cmd.Env
+	if env == nil {
+		env = os.Environ()
+	}
+	c.Cmd.Env = append(env,
+		"SSH_ASKPASS="+self,
+		// use it even though there is a terminal, OpenSSH 8.4 and later
+		"SSH_ASKPASS_REQUIRE=force",
+		askpassFileEnv+"="+f.Name(),
+	)
+	return cleanup, nil
+}
+
+// runAskpass is quickssh running as ssh's askpass helper. Only password
+// prompts are answered, a host key question gets no answer instead of the
+// password.
+func runAskpass(file string, args []string) int {
+	prompt := strings.Join(args, " ")
+	if !strings.Contains(strings.ToLower(prompt), "password") {
+		return 1
+	}
+	password, err := os.ReadFile(file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "quickssh askpass:", err)
+		return 1
+	}
+	fmt.Println(string(password))
+	return 0
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"os/exec"
+	"slices"
+	"testing"
+)
+
+func TestSetupAskpassKeepsEnv(t *testing.T) {
+	t.Setenv("QUICKSSH_TEST_INHERITED", "1")
+	tests := []struct {
+		name string
+		env  []string
+		want string
+		lost string
+	}{
+		{"inherited", nil, "QUICKSSH_TEST_INHERITED=1", ""},
+		{"set by the caller", []string{"QUICKSSH_TEST_SET=1"}, "QUICKSSH_TEST_SET=1", "QUICKSSH_TEST_INHERITED=1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command("ssh")
+			cmd.Env = tt.env
+			c := newConnectExec(SSHHost{Host: "web1", PasswordCommand: "echo secret"}, cmd, Settings{})
+			cleanup, err := setupAskpass(c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer cleanup()
+			if !slices.Contains(cmd.Env, tt.want) {
+				t.Errorf("env lacks %s", tt.want)
+			}
+			if tt.lost != "" && slices.Contains(cmd.Env, tt.lost) {
+				t.Errorf("env has %s although the caller set its own", tt.lost)
+			}
+			if !slices.Contains(cmd.Env, "SSH_ASKPASS_REQUIRE=force") {
+				t.Error("env lacks the askpass variables")
+			}
+		})
+	}
+}
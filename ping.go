@@ -0,0 +1,289 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strconv"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+var pingTimeRegexp = regexp.MustCompile(`time[=<]\s*([\d.]+)\s*ms`)
+
+// errPingTimeout is a ping that got no reply in time
+var errPingTimeout = errors.New("timeout")
+
+// pinger sends one echo request at a time and returns the round trip time
+type pinger interface {
+	ping(seq int, timeout time.Duration) (time.Duration, error)
+	// method names how it pings, icmp, ping or tcp
+	method() string
+	Close() error
+}
+
+// newPinger pings h with ICMP if the system allows this process to, with the
+// ping command otherwise, and by connecting to the ssh port as a last resort
+func newPinger(h SSHHost) (pinger, error) {
+	ips, err := net.LookupIP(h.address())
+	if err != nil {
+		return nil, err
+	}
+	ip := ips[0]
+	if p, err := newICMPPinger(ip); err == nil {
+		return p, nil
+	}
+	if path, err := exec.LookPath("ping"); err == nil {
+		return &execPinger{path: path, ip: ip}, nil
+	}
+	return &tcpPinger{host: h}, nil
+}
+
+type icmpPinger struct {
+	conn     *icmp.PacketConn
+	dst      net.Addr
+	ip       net.IP
+	id       int
+	protocol int
+	request  icmp.Type
+	reply    icmp.Type
+}
+
+// newICMPPinger opens an unprivileged ICMP socket, which Linux allows for
+// the groups in net.ipv4.ping_group_range and macOS always, or a raw socket
+// when running as root
+func newICMPPinger(ip net.IP) (*icmpPinger, error) {
+	p := &icmpPinger{ip: ip, id: os.Getpid() & 0xffff}
+	network, privileged, address := "udp4", "ip4:icmp", "0.0.0.0"
+	p.protocol, p.request, p.reply = 1, ipv4.ICMPTypeEcho, ipv4.ICMPTypeEchoReply
+	if ip.To4() == nil {
+		network, privileged, address = "udp6", "ip6:ipv6-icmp", "::"
+		p.protocol, p.request, p.reply = 58, ipv6.ICMPTypeEchoRequest, ipv6.ICMPTypeEchoReply
+	}
+
+	conn, err := icmp.ListenPacket(network, address)
+	if err == nil {
+		p.conn, p.dst = conn, &net.UDPAddr{IP: ip}
+		return p, nil
+	}
+	if conn, err = icmp.ListenPacket(privileged, address); err != nil {
+		return nil, err
+	}
+	p.conn, p.dst = conn, &net.IPAddr{IP: ip}
+	return p, nil
+}
+
+func (p *icmpPinger) method() string { return "icmp" }
+func (p *icmpPinger) Close() error   { return p.conn.Close() }
+
+func (p *icmpPinger) ping(seq int, timeout time.Duration) (time.Duration, error) {
+	request := icmp.Message{Type: p.request, Body: &icmp.Echo{ID: p.id, Seq: seq, Data: []byte("quickssh")}}
+	b, err := request.Marshal(nil)
+	if err != nil {
+		return 0, err
+	}
+	start := time.Now()
+	if _, err := p.conn.WriteTo(b, p.dst); err != nil {
+		return 0, err
+	}
+	p.conn.SetReadDeadline(start.Add(timeout))
+
+	buf := make([]byte, 1500)
+	for {
+		n, from, err := p.conn.ReadFrom(buf)
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			return 0, errPingTimeout
+		} else if err != nil {
+			return 0, err
+		}
+		reply, err := icmp.ParseMessage(p.protocol, buf[:n])
+		if err != nil || reply.Type != p.reply || !sameIP(from, p.ip) {
+			continue
+		}
+		// unprivileged sockets get a kernel chosen id, so only the
+		// sequence number tells our replies apart
+		if echo, ok := reply.Body.(*icmp.Echo); ok && echo.Seq == seq {
+			return time.Since(start), nil
+		}
+	}
+}
+
+func sameIP(addr net.Addr, ip net.IP) bool {
+	switch a := addr.(type) {
+	case *net.UDPAddr:
+		return a.IP.Equal(ip)
+	case *net.IPAddr:
+		return a.IP.Equal(ip)
+	}
+	return false
+}
+
+// execPinger runs the system's ping, which is setuid or has the capability
+// to send ICMP where quickssh can't
+type execPinger struct {
+	path string
+	ip   net.IP
+}
+
+func (p *execPinger) method() string { return "ping" }
+func (p *execPinger) Close() error   { return nil }
+
+func (p *execPinger) ping(_ int, timeout time.Duration) (time.Duration, error) {
+	var args []string
+	switch runtime.GOOS {
+	case "windows":
+		args = []string{"-n", "1", "-w", strconv.Itoa(int(timeout.Milliseconds()))}
+	case "darwin", "freebsd", "openbsd", "netbsd":
+		// -W is in milliseconds here, -t is the whole run in seconds
+		args = []string{"-c", "1", "-t", strconv.Itoa(max(int(timeout.Seconds()), 1))}
+	default:
+		args = []string{"-c", "1", "-W", strconv.Itoa(max(int(timeout.Seconds()), 1))}
+	}
+	if p.ip.To4() == nil && runtime.GOOS != "windows" {
+		args = append(args, "-6")
+	}
+	out, err := exec.Command(p.path, append(args, p.ip.String())...).Output()
+	match := pingTimeRegexp.FindSubmatch(out)
+	if match == nil {
+		if err != nil {
+			return 0, errPingTimeout
+		}
+		return 0, fmt.Errorf("no round trip time in the output of %s", p.path)
+	}
+	ms, err := strconv.ParseFloat(string(match[1]), 64)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(ms * float64(time.Millisecond)), nil
+}
+
+// tcpPinger measures how long connecting to the ssh port takes
+type tcpPinger struct {
+	host SSHHost
+}
+
+func (p *tcpPinger) method() string { return "tcp port " + strconv.Itoa(p.host.port()) }
+func (p *tcpPinger) Close() error   { return nil }
+
+func (p *tcpPinger) ping(_ int, timeout time.Duration) (time.Duration, error) {
+	rtt, err := checkReachable(context.Background(), p.host, timeout)
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return 0, errPingTimeout
+	}
+	return rtt, err
+}
+
+// PingStats summarizes the round trip times of a number of pings
+type PingStats struct {
+	Sent, Received int
+	Min, Avg, Max  time.Duration
+}
+
+func newPingStats(sent int, rtts []time.Duration) PingStats {
+	s := PingStats{Sent: sent, Received: len(rtts)}
+	var total time.Duration
+	for i, rtt := range rtts {
+		if i == 0 || rtt < s.Min {
+			s.Min = rtt
+		}
+		s.Max = max(s.Max, rtt)
+		total += rtt
+	}
+	if len(rtts) > 0 {
+		s.Avg = total / time.Duration(len(rtts))
+	}
+	return s
+}
+
+// Loss is the share of pings without a reply, in percent
+func (s PingStats) Loss() float64 {
+	if s.Sent == 0 {
+		return 0
+	}
+	return 100 * float64(s.Sent-s.Received) / float64(s.Sent)
+}
+
+// milliseconds returns d in ms with one decimal, like ping prints it
+func milliseconds(d time.Duration) string {
+	return strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', 1, 64)
+}
+
+// sent when the single ping of P has an answer
+type pingResultMsg struct {
+	host   string
+	method string
+	rtt    time.Duration
+	err    error
+}
+
+// pingHost pings h once in the background for the TUI
+func pingHost(h SSHHost) tea.Cmd {
+	return func() tea.Msg {
+		p, err := newPinger(h)
+		if err != nil {
+			return pingResultMsg{host: h.Host, err: err}
+		}
+		defer p.Close()
+		rtt, err := p.ping(1, 2*time.Second)
+		return pingResultMsg{host: h.Host, method: p.method(), rtt: rtt, err: err}
+	}
+}
+
+func runPing(args []string) int {
+	fs := flag.NewFlagSet("ping", flag.ExitOnError)
+	alias := fs.String("host", "", "alias of the host to ping")
+	count := fs.Int("count", 4, "number of pings")
+	interval := fs.Duration("interval", time.Second, "time between pings")
+	timeout := fs.Duration("timeout", 2*time.Second, "how long to wait for each reply")
+	fs.Parse(args)
+
+	if *count < 1 {
+		fmt.Fprintln(os.Stderr, "usage: quickssh ping --host <alias> [--count 4] [--interval 1s] [--timeout 2s]")
+		return 2
+	}
+	h, err := loadHost(*alias)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	p, err := newPinger(h)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to ping", h.Host+":", err)
+		return 1
+	}
+	defer p.Close()
+
+	fmt.Printf("Pinging %s (%s) with %s\n", h.Host, h.address(), p.method())
+	var rtts []time.Duration
+	for seq := 1; seq <= *count; seq++ {
+		if seq > 1 {
+			time.Sleep(*interval)
+		}
+		rtt, err := p.ping(seq, *timeout)
+		if err != nil {
+			fmt.Printf("seq=%d %v\n", seq, err)
+			continue
+		}
+		rtts = append(rtts, rtt)
+		fmt.Printf("seq=%d time=%s ms\n", seq, milliseconds(rtt))
+	}
+
+	stats := newPingStats(*count, rtts)
+	fmt.Printf("\n%d sent, %d received, %.0f%% loss\n", stats.Sent, stats.Received, stats.Loss())
+	if stats.Received == 0 {
+		return 1
+	}
+	fmt.Printf("min/avg/max = %s/%s/%s ms\n", milliseconds(stats.Min), milliseconds(stats.Avg), milliseconds(stats.Max))
+	return 0
+}
@@ -0,0 +1,355 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+)
+
+// TunnelInfo is a local port an ssh process listens on to forward it
+type TunnelInfo struct {
+	LocalPort int
+	// where the host forwards connections to, e.g. "localhost:5432", empty
+	// if it couldn't be found out
+	Remote string
+	// alias of the host, empty for tunnels not opened by quickssh
+	Alias   string
+	PID     int
+	Started time.Time
+}
+
+// tunnelState is written for every ssh session quickssh starts with local
+// forwards and removed when it ends
+type tunnelState struct {
+	Alias    string          `json:"alias"`
+	PID      int             `json:"pid"`
+	Started  time.Time       `json:"started"`
+	Forwards []tunnelForward `json:"forwards"`
+}
+
+type tunnelForward struct {
+	LocalPort int    `json:"local_port"`
+	Remote    string `json:"remote"`
+}
+
+// tunnelStateDir holds a file per running session with local forwards
+func tunnelStateDir() string {
+	return filepath.Join(filepath.Dir(configFilePath), "tunnels")
+}
+
+// parseForwardArgs returns the local forwards in ssh arguments, given with
+// -L or -o LocalForward=
+func parseForwardArgs(args []string) []tunnelForward {
+	var forwards []tunnelForward
+	for i := 0; i < len(args); i++ {
+		var f tunnelForward
+		var ok bool
+		switch arg := args[i]; {
+		case arg == "-L" && i+1 < len(args):
+			i++
+			f, ok = parseForwardSpec(args[i])
+		case strings.HasPrefix(arg, "-L") && len(arg) > 2:
+			f, ok = parseForwardSpec(arg[2:])
+		case arg == "-o" && i+1 < len(args):
+			i++
+			key, value, _ := strings.Cut(args[i], "=")
+			if strings.EqualFold(key, "LocalForward") {
+				f, ok = parseLocalForward(value)
+			}
+		}
+		if ok {
+			forwards = append(forwards, f)
+		}
+	}
+	return forwards
+}
+
+// parseForwardSpec reads the -L form [bind_address:]port:host:hostport
+func parseForwardSpec(spec string) (tunnelForward, bool) {
+	parts := strings.Split(spec, ":")
+	if len(parts) < 3 {
+		return tunnelForward{}, false
+	}
+	n := len(parts)
+	port, err := strconv.Atoi(parts[n-3])
+	if err != nil {
+		return tunnelForward{}, false
+	}
+	return tunnelForward{LocalPort: port, Remote: parts[n-2] + ":" + parts[n-1]}, true
+}
+
+// parseLocalForward reads the ssh_config form "[bind_address:]port host:hostport"
+func parseLocalForward(value string) (tunnelForward, bool) {
+	fields := strings.Fields(value)
+	if len(fields) != 2 {
+		return tunnelForward{}, false
+	}
+	local := fields[0]
+	if i := strings.LastIndexByte(local, ':'); i >= 0 {
+		local = local[i+1:]
+	}
+	port, err := strconv.Atoi(local)
+	if err != nil {
+		return tunnelForward{}, false
+	}
+	return tunnelForward{LocalPort: port, Remote: fields[1]}, true
+}
+
+// recordTunnels writes the state file of a started ssh session with local
+// forwards, the returned func removes it again
+func recordTunnels(alias string, cmd *exec.Cmd) func() {
+	forwards := parseForwardArgs(cmd.Args[1:])
+	if len(forwards) == 0 || cmd.Process == nil {
+		return func() {}
+	}
+	state := tunnelState{Alias: alias, PID: cmd.Process.Pid, Started: time.Now(), Forwards: forwards}
+	path := filepath.Join(tunnelStateDir(), strconv.Itoa(state.PID)+".json")
+	data, err := json.Marshal(state)
+	if err == nil {
+		err = os.MkdirAll(tunnelStateDir(), 0o755)
+	}
+	if err == nil {
+		err = os.WriteFile(path, data, 0o644)
+	}
+	if err != nil {
+		// the session matters more than the list of tunnels
+		return func() {}
+	}
+	return func() { os.Remove(path) }
+}
+
+func loadTunnelStates() []tunnelState {
+	entries, err := os.ReadDir(tunnelStateDir())
+	if err != nil {
+		return nil
+	}
+	var states []tunnelState
+	for _, e := range entries {
+		data, err := os.ReadFile(filepath.Join(tunnelStateDir(), e.Name()))
+		if err != nil {
+			continue
+		}
+		var s tunnelState
+		if json.Unmarshal(data, &s) == nil {
+			states = append(states, s)
+		}
+	}
+	return states
+}
+
+// listener is a listening TCP port, with the process owning it if known
+type listener struct {
+	port int
+	pid  int
+}
+
+// listeningPorts returns the listening TCP ports, on Linux only the ones of
+// ssh processes
+func listeningPorts() ([]listener, error) {
+	if runtime.GOOS == "linux" {
+		return procListeners()
+	}
+	return netstatListeners()
+}
+
+// procListeners reads the listening sockets from /proc/net and finds the ssh
+// processes holding them through their file descriptors
+func procListeners() ([]listener, error) {
+	ports := make(map[string]int)
+	for _, file := range []string{"/proc/net/tcp", "/proc/net/tcp6"} {
+		f, err := os.Open(file)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		scanner := bufio.NewScanner(f)
+		scanner.Scan() // header
+		for scanner.Scan() {
+			// sl local_address rem_address st tx:rx tr:when retrnsmt uid timeout inode
+			fields := strings.Fields(scanner.Text())
+			if len(fields) < 10 || fields[3] != "0A" {
+				continue
+			}
+			_, hexPort, _ := strings.Cut(fields[1], ":")
+			port, err := strconv.ParseInt(hexPort, 16, 32)
+			if err == nil {
+				ports[fields[9]] = int(port)
+			}
+		}
+		f.Close()
+	}
+
+	procs, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+	var listeners []listener
+	for _, p := range procs {
+		pid, err := strconv.Atoi(p.Name())
+		if err != nil {
+			continue
+		}
+		comm, err := os.ReadFile(filepath.Join("/proc", p.Name(), "comm"))
+		if err != nil || strings.TrimSpace(string(comm)) != "ssh" {
+			continue
+		}
+		fds, err := os.ReadDir(filepath.Join("/proc", p.Name(), "fd"))
+		if err != nil {
+			continue
+		}
+		for _, fd := range fds {
+			target, err := os.Readlink(filepath.Join("/proc", p.Name(), "fd", fd.Name()))
+			if err != nil || !strings.HasPrefix(target, "socket:[") {
+				continue
+			}
+			inode := strings.TrimSuffix(strings.TrimPrefix(target, "socket:["), "]")
+			if port, ok := ports[inode]; ok {
+				l := listener{port: port, pid: pid}
+				// ssh listens on the IPv4 and IPv6 loopback
+				if !slices.Contains(listeners, l) {
+					listeners = append(listeners, l)
+				}
+			}
+		}
+	}
+	return listeners, nil
+}
+
+// netstatListeners parses netstat -an, which doesn't tell the process
+func netstatListeners() ([]listener, error) {
+	out, err := exec.Command("netstat", "-an").Output()
+	if err != nil {
+		return nil, fmt.Errorf("netstat: %w", err)
+	}
+	var listeners []listener
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		// LISTEN on macOS, LISTENING on Windows
+		if len(fields) < 4 || !strings.HasPrefix(strings.ToLower(fields[0]), "tcp") || !strings.HasPrefix(fields[len(fields)-1], "LISTEN") {
+			continue
+		}
+		// 127.0.0.1.8080 on macOS, 127.0.0.1:8080 on Windows
+		local := fields[3]
+		if runtime.GOOS == "windows" {
+			local = fields[1]
+		}
+		i := strings.LastIndexAny(local, ".:")
+		port, err := strconv.Atoi(local[i+1:])
+		if err != nil {
+			continue
+		}
+		l := listener{port: port}
+		if !slices.Contains(listeners, l) {
+			listeners = append(listeners, l)
+		}
+	}
+	return listeners, nil
+}
+
+// ListActiveTunnels returns the ports ssh processes forward, with the host
+// and target recorded by quickssh. On Linux tunnels of other ssh processes
+// are listed too, with the target read from their command line. Elsewhere
+// only quickssh's own tunnels are found.
+func ListActiveTunnels() ([]TunnelInfo, error) {
+	listeners, err := listeningPorts()
+	if err != nil {
+		return nil, err
+	}
+	states := loadTunnelStates()
+
+	var tunnels []TunnelInfo
+	for _, l := range listeners {
+		t := TunnelInfo{LocalPort: l.port, PID: l.pid}
+		for _, s := range states {
+			if l.pid != 0 && s.PID != l.pid {
+				continue
+			}
+			if i := slices.IndexFunc(s.Forwards, func(f tunnelForward) bool { return f.LocalPort == l.port }); i >= 0 {
+				t.Alias, t.PID, t.Started, t.Remote = s.Alias, s.PID, s.Started, s.Forwards[i].Remote
+				break
+			}
+		}
+		if l.pid == 0 && t.Alias == "" {
+			// without the process, a port is only known to be a tunnel
+			// from a state file
+			continue
+		}
+		if t.Alias == "" {
+			t.Remote, t.Started = procForward(l.pid, l.port)
+		}
+		tunnels = append(tunnels, t)
+	}
+	slices.SortFunc(tunnels, func(a, b TunnelInfo) int { return a.LocalPort - b.LocalPort })
+	return tunnels, nil
+}
+
+// procForward finds the target of port in the command line of the ssh
+// process pid, and when it started
+func procForward(pid, port int) (string, time.Time) {
+	dir := filepath.Join("/proc", strconv.Itoa(pid))
+	var started time.Time
+	if info, err := os.Stat(dir); err == nil {
+		started = info.ModTime()
+	}
+	cmdline, err := os.ReadFile(filepath.Join(dir, "cmdline"))
+	if err != nil {
+		return "", started
+	}
+	args := strings.Split(strings.TrimRight(string(cmdline), "\x00"), "\x00")
+	for _, f := range parseForwardArgs(args) {
+		if f.LocalPort == port {
+			return f.Remote, started
+		}
+	}
+	return "", started
+}
+
+func runPortForwardList(args []string) int {
+	fs := flag.NewFlagSet("port-forward-list", flag.ExitOnError)
+	fs.Parse(args)
+
+	tunnels, err := ListActiveTunnels()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to list the tunnels:", err)
+		return 1
+	}
+	if len(tunnels) == 0 {
+		fmt.Println("No active tunnels")
+		return 0
+	}
+
+	now := time.Now()
+	t := table.New().
+		Border(lipgloss.RoundedBorder()).
+		Headers("Local port", "Remote", "Host", "PID", "Started").
+		StyleFunc(func(row, col int) lipgloss.Style {
+			if row == table.HeaderRow {
+				return dashboardHeaderStyle
+			}
+			return lipgloss.NewStyle().Padding(0, 1)
+		})
+	for _, tunnel := range tunnels {
+		started := "-"
+		if !tunnel.Started.IsZero() {
+			started = relativeTime(tunnel.Started, now)
+		}
+		t.Row(strconv.Itoa(tunnel.LocalPort), cmp.Or(tunnel.Remote, "-"), cmp.Or(tunnel.Alias, "-"), strconv.Itoa(tunnel.PID), started)
+	}
+	fmt.Println(t)
+	return 0
+}
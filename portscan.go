@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+)
+
+const (
+	defaultScanPorts = "22,80,443,8080"
+	scanTimeout      = 2 * time.Second
+	// ports dialed at the same time
+	scanWorkers = 64
+)
+
+// used where /etc/services is missing, e.g. on Windows
+var wellKnownPorts = map[int]string{
+	21: "ftp", 22: "ssh", 23: "telnet", 25: "smtp", 53: "domain", 80: "http",
+	110: "pop3", 143: "imap", 443: "https", 465: "submissions", 587: "submission",
+	993: "imaps", 995: "pop3s", 3306: "mysql", 3389: "ms-wbt-server",
+	5432: "postgresql", 6379: "redis", 8080: "http-alt", 9200: "elasticsearch",
+}
+
+// the tcp services of /etc/services, read on first use
+var loadServices = sync.OnceValue(func() map[int]string {
+	services := make(map[int]string)
+	f, err := os.Open("/etc/services")
+	if err != nil {
+		return wellKnownPorts
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		port, proto, ok := strings.Cut(fields[1], "/")
+		n, err := strconv.Atoi(port)
+		if !ok || proto != "tcp" || err != nil {
+			continue
+		}
+		if _, ok := services[n]; !ok {
+			services[n] = fields[0]
+		}
+	}
+	return services
+})
+
+// PortResult is the outcome of dialing one port
+type PortResult struct {
+	Port    int
+	Open    bool
+	Service string
+}
+
+// ScanPorts dials every port of hostname concurrently and returns the
+// results in the order of ports
+func ScanPorts(hostname string, ports []int, timeout time.Duration) []PortResult {
+	results := make([]PortResult, len(ports))
+	services := loadServices()
+	sem := make(chan struct{}, scanWorkers)
+	var wg sync.WaitGroup
+	for i, port := range ports {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			results[i] = PortResult{Port: port, Service: services[port]}
+			conn, err := net.DialTimeout("tcp", net.JoinHostPort(hostname, strconv.Itoa(port)), timeout)
+			if err == nil {
+				conn.Close()
+				results[i].Open = true
+			}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// parsePorts reads a comma separated list of ports and ranges like 8000-8010
+func parsePorts(spec string) ([]int, error) {
+	var ports []int
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		from, to, isRange := strings.Cut(part, "-")
+		if !isRange {
+			to = from
+		}
+		start, err1 := strconv.Atoi(from)
+		end, err2 := strconv.Atoi(to)
+		if err1 != nil || err2 != nil || start < 1 || end > 65535 || start > end {
+			return nil, fmt.Errorf("invalid port or range %q", part)
+		}
+		for port := start; port <= end; port++ {
+			ports = append(ports, port)
+		}
+	}
+	return ports, nil
+}
+
+// renderPortTable shows the scan results as a table with open ports in green
+func renderPortTable(results []PortResult) string {
+	t := table.New().
+		Border(lipgloss.RoundedBorder()).
+		Headers("Port", "State", "Service").
+		StyleFunc(func(row, col int) lipgloss.Style {
+			switch {
+			case row == table.HeaderRow:
+				return dashboardHeaderStyle
+			case col == 1 && results[row].Open:
+				return reachableStyle.Padding(0, 1)
+			case col == 1:
+				return unreachableStyle.Padding(0, 1)
+			}
+			return lipgloss.NewStyle().Padding(0, 1)
+		})
+	for _, r := range results {
+		state := "closed"
+		if r.Open {
+			state = "open"
+		}
+		t.Row(strconv.Itoa(r.Port), state, r.Service)
+	}
+	return t.String()
+}
+
+func runPortScan(args []string) int {
+	fs := flag.NewFlagSet("port-scan", flag.ExitOnError)
+	alias := fs.String("host", "", "alias of the host to scan")
+	spec := fs.String("ports", defaultScanPorts, "comma separated ports or ranges, e.g. 22,80,8000-8010")
+	fs.Parse(args)
+
+	h, err := loadHost(*alias)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	ports, err := parsePorts(*spec)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	fmt.Println(renderPortTable(ScanPorts(h.address(), ports, scanTimeout)))
+	return 0
+}
+
+// sent when the port scan started from the TUI has finished
+type portScanMsg struct {
+	host    string
+	results []PortResult
+}
+
+// scanHostPorts scans the default ports and the ssh port of h
+func scanHostPorts(h SSHHost) tea.Cmd {
+	return func() tea.Msg {
+		ports, _ := parsePorts(defaultScanPorts)
+		if !strings.Contains(","+defaultScanPorts+",", ","+strconv.Itoa(h.port())+",") {
+			ports = append([]int{h.port()}, ports...)
+		}
+		return portScanMsg{host: h.Host, results: ScanPorts(h.address(), ports, scanTimeout)}
+	}
+}
+
+// portScanOverlay shows the scan results in place of the detail panel
+type portScanOverlay struct {
+	host     string
+	scanning bool
+	results  viewport.Model
+}
+
+func (m model) portScanView() string {
+	title := titleStyle.Render("Ports of " + m.scan.host)
+	if m.scan.scanning {
+		return title + "\n\nScanning..."
+	}
+	return title + "\n\n" + m.scan.results.View() + "\n" + checkFixStyle.Render("j/k: scroll • esc: close")
+}
+
+// sizeScanOverlay fits the results viewport next to the list
+func (m *model) sizeScanOverlay() {
+	_, v := appStyle.GetFrameSize()
+	m.scan.results.Width = m.detailWidth()
+	// title, blank line and help
+	m.scan.results.Height = max(m.height-v-3, 1)
+}
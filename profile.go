@@ -0,0 +1,51 @@
+package main
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Profile is a reusable set of ssh flags and options from the [profiles]
+// table that can be layered onto any host at connect time
+type Profile struct {
+	Flags   []string          `toml:"flags,omitempty"`
+	Options map[string]string `toml:"options,omitempty"`
+}
+
+func (p Profile) args() []string {
+	args := slices.Clone(p.Flags)
+	for _, key := range slices.Sorted(maps.Keys(p.Options)) {
+		args = append(args, "-o", key+"="+p.Options[key])
+	}
+	return args
+}
+
+// connectTo starts a connection, checking the host key first if enabled
+func (m *model) connectTo(h SSHHost, p Profile) tea.Cmd {
+	h = m.forConnect(h)
+	m.verboseSet = false
+	if m.settings.CheckKnownHosts {
+		statusCmd := m.list.NewStatusMessage("Checking host key of " + h.Host)
+		return tea.Batch(statusCmd, checkHostKey(h, p))
+	}
+	return connect(h, p, m.settings)
+}
+
+// forConnect applies the one-shot verbosity to h
+func (m model) forConnect(h SSHHost) SSHHost {
+	if m.verboseSet {
+		h.Verbose = m.verboseOnce
+	}
+	return h
+}
+
+// verbosityLabel describes a verbosity level as the flag passed to ssh
+func verbosityLabel(level int) string {
+	if level <= 0 {
+		return "not verbose"
+	}
+	return "-" + strings.Repeat("v", min(level, maxVerbose))
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// how long to wait for another digit before connecting to the typed number
+const numberTimeout = 500 * time.Millisecond
+
+// sent when no further digit was typed, seq identifies the typed number
+type numberTimeoutMsg struct{ seq int }
+
+// toggleNumbers shows or hides the quick connect numbers
+func (m *model) toggleNumbers() {
+	m.settings.ShowNumbers = !m.settings.ShowNumbers
+	m.refreshDelegate()
+}
+
+// typeDigit adds a digit to the number being typed. The host is connected to
+// right away when no further digit could make a valid number, otherwise once
+// the timeout passes.
+func (m *model) typeDigit(digit rune) tea.Cmd {
+	m.number += string(digit)
+	m.numberSeq++
+	n, _ := strconv.Atoi(m.number)
+	if n*10 > len(m.list.VisibleItems()) {
+		return m.quickConnect()
+	}
+	seq := m.numberSeq
+	return tea.Tick(numberTimeout, func(time.Time) tea.Msg {
+		return numberTimeoutMsg{seq: seq}
+	})
+}
+
+// quickConnect selects the host numbered by the typed digits and connects,
+// or only selects it after g
+func (m *model) quickConnect() tea.Cmd {
+	n, _ := strconv.Atoi(m.number)
+	jump := m.numberJump
+	m.number, m.numberJump = "", false
+	if n < 1 || n > len(m.list.VisibleItems()) {
+		return m.list.NewStatusMessage(errorMessageStyle(fmt.Sprintf("No host number %d", n)))
+	}
+	m.list.Select(n - 1)
+	if jump {
+		return nil
+	}
+	h, ok := m.selectedHost()
+	if !ok {
+		return nil
+	}
+	return m.connectTo(h, Profile{})
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestJumpToNumber(t *testing.T) {
+	var config strings.Builder
+	for i := 1; i <= 12; i++ {
+		fmt.Fprintf(&config, "[[hosts]]\nhost = \"host%d\"\nhostname = \"10.0.0.%d\"\n\n", i, i)
+	}
+	tests := []struct {
+		keys string
+		want int
+	}{
+		{"g2", 1},
+		{"g12", 11},
+		{"g1", 0},
+		// no host 13, the selection stays at the top
+		{"g13", 0},
+		// digits alone do nothing while the numbers are hidden
+		{"j5", 1},
+		// another key in between ends the jump
+		{"gj3", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.keys, func(t *testing.T) {
+			var tm tea.Model = newTestModel(t, config.String())
+			for _, r := range tt.keys {
+				tm, _ = tm.Update(keyPress(string(r)))
+			}
+			m := tm.(model)
+			if m.number != "" {
+				// the timeout for a further digit
+				tm, _ = m.Update(numberTimeoutMsg{seq: m.numberSeq})
+				m = tm.(model)
+			}
+			if got := m.list.Index(); got != tt.want {
+				t.Errorf("selected %d, want %d", got, tt.want)
+			}
+			if m.view != listView {
+				t.Errorf("view = %d, want the list", m.view)
+			}
+		})
+	}
+}
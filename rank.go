@@ -0,0 +1,160 @@
+package main
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+)
+
+// relevance of a query match, an alias prefix beats a prefix of any other
+// field, which beats a substring, which beats a fuzzy match
+const (
+	matchFuzzy = iota + 1
+	matchSubstring
+	matchFieldPrefix
+	matchAliasPrefix
+)
+
+// RankedHost is a host matching a search query together with its score
+type RankedHost struct {
+	Host  SSHHost
+	Index int
+	Score float64
+	// rune positions of the match in the alias, for highlighting
+	Matched []int
+}
+
+// RankHosts scores the hosts matching query and returns them best first. The
+// match relevance weighs most, recent and frequent connections from history
+// move hosts up within the same kind of match.
+func RankHosts(hosts []SSHHost, query string, history []ConnectionEvent) []RankedHost {
+	return rankHosts(hosts, query, history, time.Now())
+}
+
+func rankHosts(hosts []SSHHost, query string, history []ConnectionEvent, now time.Time) []RankedHost {
+	counts := make(map[string]int)
+	maxCount := 0
+	for _, e := range history {
+		counts[e.Host]++
+		maxCount = max(maxCount, counts[e.Host])
+	}
+	last := lastConnected(history)
+
+	var ranked []RankedHost
+	for i, h := range hosts {
+		relevance, matched := matchHost(h, query)
+		if relevance == 0 {
+			continue
+		}
+
+		score := 2 * float64(relevance)
+		if t, ok := last[h.Host]; ok {
+			// 1 for a connection just now, halving every day
+			score += 1 / (1 + max(now.Sub(t).Hours(), 0)/24)
+		}
+		if counts[h.Host] > 0 {
+			score += math.Log1p(float64(counts[h.Host])) / math.Log1p(float64(maxCount))
+		}
+		ranked = append(ranked, RankedHost{Host: h, Index: i, Score: score, Matched: matched})
+	}
+
+	slices.SortStableFunc(ranked, func(a, b RankedHost) int {
+		switch {
+		case a.Score > b.Score:
+			return -1
+		case a.Score < b.Score:
+			return 1
+		}
+		return 0
+	})
+	return ranked
+}
+
+// matchHost returns how well query matches the searchable fields of h, 0 if
+// it doesn't match at all
+func matchHost(h SSHHost, query string) (int, []int) {
+	q := []rune(strings.ToLower(query))
+	alias := []rune(strings.ToLower(h.Host))
+	if len(q) == 0 {
+		return matchSubstring, nil
+	}
+	if hasRunePrefix(alias, q) {
+		return matchAliasPrefix, runeRange(0, len(q))
+	}
+
+	fields := append([]string{h.HostName, h.User}, h.Tags...)
+	for _, field := range fields {
+		if hasRunePrefix([]rune(strings.ToLower(field)), q) {
+			return matchFieldPrefix, nil
+		}
+	}
+
+	if i := indexRunes(alias, q); i >= 0 {
+		return matchSubstring, runeRange(i, i+len(q))
+	}
+	for _, field := range fields {
+		if strings.Contains(strings.ToLower(field), string(q)) {
+			return matchSubstring, nil
+		}
+	}
+
+	// the letters of the query in order, anywhere in the alias
+	var matched []int
+	for i, r := range alias {
+		if len(matched) < len(q) && r == q[len(matched)] {
+			matched = append(matched, i)
+		}
+	}
+	if len(matched) == len(q) {
+		return matchFuzzy, matched
+	}
+	return 0, nil
+}
+
+func hasRunePrefix(s, prefix []rune) bool {
+	return len(s) >= len(prefix) && slices.Equal(s[:len(prefix)], prefix)
+}
+
+func indexRunes(s, sub []rune) int {
+	for i := 0; i+len(sub) <= len(s); i++ {
+		if slices.Equal(s[i:i+len(sub)], sub) {
+			return i
+		}
+	}
+	return -1
+}
+
+func runeRange(from, to int) []int {
+	indexes := make([]int, 0, to-from)
+	for i := from; i < to; i++ {
+		indexes = append(indexes, i)
+	}
+	return indexes
+}
+
+// rankFilter is the list filter for the "/" search, it ranks the listed
+// hosts with RankHosts instead of the list's plain fuzzy match
+func (m model) rankFilter() list.FilterFunc {
+	items, history, tagQuery, lastSeen := m.list.Items(), m.history, m.tagQuery, m.lastSeen
+	return func(term string, targets []string) []list.Rank {
+		if len(targets) != len(items) {
+			// the items changed since, don't guess
+			return list.DefaultFilter(term, targets)
+		}
+		if tagQuery != "" && term == tagQuery {
+			return tagFilterRanks(hostsOf(items), term)
+		}
+		if idle, ok := parseIdle(term); ok {
+			return idleRanks(hostsOf(items), lastSeen, idle, time.Now())
+		}
+		ranked := RankHosts(hostsOf(items), term, history)
+		ranks := make([]list.Rank, len(ranked))
+		for i, r := range ranked {
+			ranks[i] = list.Rank{Index: r.Index, MatchedIndexes: r.Matched}
+		}
+		return ranks
+	}
+}
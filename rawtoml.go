@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var rawTOMLStyle = lipgloss.NewStyle().
+	Border(lipgloss.RoundedBorder()).
+	BorderForeground(lipgloss.Color("#25A065")).
+	Padding(0, 1)
+
+// rawTOMLModal shows a host the way it is stored in the config file
+type rawTOMLModal struct {
+	host string
+	text string
+}
+
+// hostTOML encodes h as a [[hosts]] entry, ready to paste into a config
+func hostTOML(h SSHHost) (string, error) {
+	var buf bytes.Buffer
+	entry := struct {
+		Hosts []SSHHost `toml:"hosts"`
+	}{[]SSHHost{h}}
+	if err := toml.NewEncoder(&buf).Encode(entry); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func (m *model) openRawTOML() tea.Cmd {
+	h, ok := m.selectedHost()
+	if !ok {
+		return nil
+	}
+	text, err := hostTOML(h)
+	if err != nil {
+		return m.list.NewStatusMessage(errorMessageStyle("Failed to encode " + h.Host + ": " + err.Error()))
+	}
+	m.raw = rawTOMLModal{host: h.Host, text: text}
+	m.view = rawTOMLView
+	return nil
+}
+
+// updateRawTOML copies the entry on y and closes the modal on esc
+func (m *model) updateRawTOML(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "y":
+		m.view = listView
+		if err := copyToClipboard(m.raw.text); err != nil {
+			return m.list.NewStatusMessage(errorMessageStyle("Failed to copy TOML: " + err.Error()))
+		}
+		return m.list.NewStatusMessage(statusMessageStyle("Copied TOML of " + m.raw.host))
+	case "esc", "q":
+		m.view = listView
+	}
+	return nil
+}
+
+func (m model) rawTOMLView() string {
+	return titleStyle.Render("TOML of "+m.raw.host) + "\n\n" +
+		rawTOMLStyle.Render(strings.TrimSuffix(m.raw.text, "\n")) + "\n\n" +
+		checkFixStyle.Render("y: copy • esc: close")
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type reachState uint
+
+const (
+	reachUnknown reachState = iota
+	reachable
+	unreachable
+)
+
+var (
+	reachableStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#04B575"))
+	unreachableStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#ED567A"))
+)
+
+// indicator is appended to the list title of a host
+func (s reachState) indicator() string {
+	switch s {
+	case reachable:
+		return " " + reachableStyle.Render("●")
+	case unreachable:
+		return " " + unreachableStyle.Render("●")
+	}
+	return ""
+}
+
+// sent when a reachability check of a host has finished
+type reachabilityMsg struct {
+	host    string
+	state   reachState
+	latency time.Duration
+	err     error
+}
+
+// checkReachable dials the ssh port of h and returns how long it took
+func checkReachable(ctx context.Context, h SSHHost, timeout time.Duration) (time.Duration, error) {
+	start := time.Now()
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(h.address(), strconv.Itoa(h.port())))
+	if err != nil {
+		return 0, err
+	}
+	conn.Close()
+	return time.Since(start), nil
+}
+
+func checkReachability(h SSHHost) tea.Cmd {
+	return func() tea.Msg {
+		latency, err := checkReachable(context.Background(), h, 3*time.Second)
+		if err != nil {
+			return reachabilityMsg{host: h.Host, state: unreachable, err: err}
+		}
+		return reachabilityMsg{host: h.Host, state: reachable, latency: latency}
+	}
+}
+
+// setReachability stores a check result and refreshes the host's list item
+func (m *model) setReachability(alias string, state reachState) tea.Cmd {
+	m.reach[alias] = state
+	for i, item := range m.list.Items() {
+		if item.(hostItem).Host == alias {
+			return m.list.SetItem(i, m.itemFor(item.(hostItem).SSHHost))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// configRecovery describes what was salvaged from a config that failed to
+// parse
+type configRecovery struct {
+	recovered int
+	lost      int
+	// first line that was dropped
+	line int
+	// copy of the damaged file, kept since the next save overwrites it
+	backup string
+}
+
+func (r configRecovery) String() string {
+	return fmt.Sprintf("Config damaged from line %d: recovered %d hosts, lost %d. The original was kept as %s",
+		r.line, r.recovered, r.lost, r.backup)
+}
+
+// RecoverConfig salvages the valid part of a config that doesn't parse, for
+// example because a crash cut it off. It keeps the longest run of whole
+// sections from the top that still parses, the tail from the first broken
+// section on is dropped. The subtables of a host, like [hosts.deploy], are
+// part of its section, so a host is kept with all of them or not at all.
+func RecoverConfig(data string) (*Config, configRecovery, error) {
+	lines := strings.Split(data, "\n")
+
+	// a section starts at each table header, the first one at the top
+	starts := []int{0}
+	total := 0
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if i > 0 && strings.HasPrefix(trimmed, "[") && !strings.HasPrefix(strings.TrimLeft(trimmed, "[ \t"), "hosts.") {
+			starts = append(starts, i)
+		}
+		if strings.HasPrefix(trimmed, "[[hosts]]") {
+			total++
+		}
+	}
+	starts = append(starts, len(lines))
+
+	var config *Config
+	kept := 0
+	for _, end := range starts[1:] {
+		var c Config
+		if _, err := toml.Decode(strings.Join(lines[:end], "\n"), &c); err != nil {
+			break
+		}
+		config, kept = &c, end
+	}
+	if config == nil {
+		return nil, configRecovery{}, errors.New("nothing could be recovered")
+	}
+	return config, configRecovery{recovered: len(config.Hosts), lost: total - len(config.Hosts), line: kept + 1}, nil
+}
+
+// recoverConfigFile loads what can be salvaged from the damaged config file
+// and copies the file aside
+func recoverConfigFile() (*Config, configRecovery, error) {
+	data, err := os.ReadFile(configFilePath)
+	if err != nil {
+		return nil, configRecovery{}, err
+	}
+	config, recovery, err := RecoverConfig(string(data))
+	if err != nil {
+		return nil, recovery, err
+	}
+	recovery.backup = configFilePath + ".broken"
+	if err := os.WriteFile(recovery.backup, data, 0o600); err != nil {
+		return nil, recovery, fmt.Errorf("failed to back up the damaged config: %w", err)
+	}
+	return config, recovery, nil
+}
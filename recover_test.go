@@ -0,0 +1,86 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const recoverTestConfig = `[settings]
+page_size = 10
+
+[[hosts]]
+host = "web1"
+hostname = "10.0.0.1"
+
+[[hosts]]
+host = "web2"
+hostname = "10.0.0.2"
+
+[[hosts]]
+host = "db1"
+hostname = "10.0.0.3"
+`
+
+func TestRecoverConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		hosts     []string
+		lost      int
+		line      int
+		recovered bool
+	}{
+		{"cut in the last host", strings.TrimSuffix(recoverTestConfig, `"10.0.0.3"`+"\n"), []string{"web1", "web2"}, 1, 12, true},
+		{"cut in a string", recoverTestConfig[:strings.Index(recoverTestConfig, `"web2`)+5], []string{"web1"}, 1, 8, true},
+		{"garbage after the hosts", recoverTestConfig + "\n[[hosts]]\nhost = \n", []string{"web1", "web2", "db1"}, 1, 16, true},
+		{
+			"cut in a subtable of a host",
+			recoverTestConfig + "\n[[hosts]]\nhost = \"app1\"\nhostname = \"10.0.0.4\"\n\n[hosts.deploy]\nremote_dir = \"/srv",
+			[]string{"web1", "web2", "db1"}, 1, 16, true,
+		},
+		{"cut in the first host", "[[hosts]]\nhost = \"web1\nhostname = \"10.0.0.1\"\n", nil, 0, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, r, err := RecoverConfig(tt.data)
+			if !tt.recovered {
+				if err == nil {
+					t.Fatalf("recovered %d hosts, want an error", len(config.Hosts))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := hostAliases(config.Hosts); strings.Join(got, ",") != strings.Join(tt.hosts, ",") {
+				t.Errorf("recovered %v, want %v", got, tt.hosts)
+			}
+			if r.recovered != len(tt.hosts) || r.lost != tt.lost || r.line != tt.line {
+				t.Errorf("recovered %d, lost %d from line %d, want %d, %d from line %d", r.recovered, r.lost, r.line, len(tt.hosts), tt.lost, tt.line)
+			}
+			if config.Settings.PageSize != 10 {
+				t.Errorf("page_size = %d, want the settings kept", config.Settings.PageSize)
+			}
+		})
+	}
+}
+
+func TestStartOnTruncatedConfig(t *testing.T) {
+	truncated := recoverTestConfig[:strings.Index(recoverTestConfig, `"web2`)+5]
+	m := newTestModel(t, truncated)
+	if got := hostAliases(m.hosts); len(got) != 1 || got[0] != "web1" {
+		t.Fatalf("started with %v, want [web1]", got)
+	}
+	if m.configErr != nil {
+		t.Errorf("configErr = %v, want the recovered config to be saveable", m.configErr)
+	}
+	if m.Init() == nil {
+		t.Error("no status message about the recovery")
+	}
+	backup, err := os.ReadFile(filepath.Join(filepath.Dir(configFilePath), ".config.broken"))
+	if err != nil || string(backup) != truncated {
+		t.Errorf("damaged file not kept as .config.broken: %v", err)
+	}
+}
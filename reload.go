@@ -0,0 +1,146 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var changedLineStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#E8A33D"))
+
+// sent on SIGHUP, asks the TUI to read the config file again
+type reloadRequestMsg struct{}
+
+// sent once the config file was read again
+type configReadMsg struct {
+	config *Config
+	err    error
+}
+
+// reloadPanel shows what a reload would change before it is applied
+type reloadPanel struct {
+	config  *Config
+	changes []string
+}
+
+func readConfig() tea.Msg {
+	config, err := loadConfig()
+	return configReadMsg{config: config, err: err}
+}
+
+// editorCommand opens path in $VISUAL or $EDITOR
+func editorCommand(path string) *exec.Cmd {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+	// $EDITOR may carry flags, e.g. "code --wait"
+	args := strings.Fields(editor)
+	return exec.Command(args[0], append(args[1:], path)...)
+}
+
+// editConfig hands the terminal to the editor and reads the config once it
+// is closed
+func editConfig() tea.Cmd {
+	return tea.ExecProcess(editorCommand(configFilePath), func(err error) tea.Msg {
+		if err != nil {
+			return configReadMsg{err: err}
+		}
+		return readConfig()
+	})
+}
+
+// showReload opens the panel with the changes of config, if there are any
+func (m *model) showReload(config *Config) tea.Cmd {
+	changes := DiffConfigs(m.config(), config)
+	if len(changes) == 0 {
+		return m.list.NewStatusMessage("Config unchanged")
+	}
+	m.reload = reloadPanel{config: config, changes: changes}
+	m.view = reloadView
+	return nil
+}
+
+// updateReload applies the new config on enter, esc keeps the current one
+func (m *model) updateReload(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "enter", "y":
+		m.view = listView
+		return m.applyConfig(m.reload.config)
+	case "esc", "q":
+		m.view = listView
+		return m.list.NewStatusMessage(errorMessageStyle("Reload cancelled, saving will overwrite the file"))
+	}
+	return nil
+}
+
+// applyConfig replaces hosts, profiles and settings with the ones of config.
+// The old undo history refers to hosts by index, so it is dropped.
+func (m *model) applyConfig(config *Config) tea.Cmd {
+	m.hosts, m.profiles, m.settings = config.Hosts, config.Profiles, config.Settings
+	m.deploy, m.templates = config.Deploy, config.Templates
+	m.configErr = nil
+	m.edits = undoStack{}
+	// the reloaded config replaces changes that weren't saved yet
+	m.autosave = autosave{seq: m.autosave.seq + 1}
+
+	listed, err := listedHosts(config)
+	items := make([]list.Item, len(listed))
+	for i, h := range listed {
+		items[i] = m.itemFor(h)
+	}
+	m.list.Title = m.settings.title()
+	m.list.SetShowTitle(!m.settings.HideTitle)
+	m.refreshDelegate()
+	if m.height > 0 {
+		m.sizeList()
+	}
+	cmd := tea.Batch(m.list.SetItems(items), m.refreshDuplicates())
+	if warning := useSSHPath(m.settings); warning != "" {
+		return tea.Batch(cmd, m.list.NewStatusMessage(errorMessageStyle(warning)))
+	}
+	if err != nil {
+		return tea.Batch(cmd, m.list.NewStatusMessage(errorMessageStyle("Error reading ~/.ssh/config: "+err.Error())))
+	}
+	return tea.Batch(cmd, m.list.NewStatusMessage(statusMessageStyle("Reloaded config")))
+}
+
+func (m model) reloadView() string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Config changed") + "\n\n")
+
+	_, v := appStyle.GetFrameSize()
+	// title, blank lines, warning and help
+	room := max(m.height-v-7, 1)
+	for i, line := range m.reload.changes {
+		if i == room-1 && len(m.reload.changes) > room {
+			b.WriteString(checkFixStyle.Render("…") + "\n")
+			break
+		}
+		switch line[0] {
+		case '+':
+			line = diffAddStyle.Render(line)
+		case '-':
+			line = diffRemoveStyle.Render(line)
+		default:
+			line = changedLineStyle.Render(line)
+		}
+		b.WriteString(line + "\n")
+	}
+	if len(m.edits.undo) > 0 {
+		b.WriteString("\n" + errorMessageStyle("Changes made in quickssh since the last save are replaced") + "\n")
+	}
+	b.WriteString("\n" + checkFixStyle.Render("enter: apply • esc: keep the current hosts"))
+	return b.String()
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	"github.com/chzyer/readline"
+	"golang.org/x/crypto/ssh"
+)
+
+// replSession runs the commands typed into the repl on one connection. Each
+// command gets its own ssh session, the working directory is carried over
+// by quickssh.
+type replSession struct {
+	host   SSHHost
+	client *ssh.Client
+	dir    string
+}
+
+// run executes command on the host in the current directory with the
+// output going to the terminal. ctrl+c ends the command, not the repl.
+func (r *replSession) run(command string, stdin io.Reader, stdout io.Writer) error {
+	session, err := r.client.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	session.Stdin, session.Stdout, session.Stderr = stdin, stdout, os.Stderr
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer func() {
+		signal.Stop(interrupt)
+		close(interrupt)
+	}()
+	go func() {
+		if _, ok := <-interrupt; ok {
+			session.Signal(ssh.SIGINT)
+			session.Close()
+		}
+	}()
+
+	if r.dir != "" {
+		command = "cd -- " + shellQuote(r.dir) + " && " + command
+	}
+	return session.Run(command)
+}
+
+// cd changes the directory later commands run in, like cd in a shell
+func (r *replSession) cd(dir string) error {
+	var out strings.Builder
+	if err := r.run("cd "+dir+" && pwd", nil, &out); err != nil {
+		return err
+	}
+	r.dir = strings.TrimSpace(out.String())
+	return nil
+}
+
+// put uploads the local file to remote on the host
+func (r *replSession) put(local, remote string) error {
+	f, err := os.Open(local)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return r.run("cat > "+shellQuote(remote), f, io.Discard)
+}
+
+// get downloads remote from the host into the local file
+func (r *replSession) get(remote, local string) error {
+	f, err := os.Create(local)
+	if err != nil {
+		return err
+	}
+	err = r.run("cat -- "+shellQuote(remote), nil, f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(local)
+	}
+	return err
+}
+
+// eval handles one line of input: a built-in, a local command after ! or a
+// remote command
+func (r *replSession) eval(line string) error {
+	fields := strings.Fields(line)
+	switch {
+	case strings.HasPrefix(line, "!"):
+		cmd := shellCommand(context.Background(), strings.TrimPrefix(line, "!"))
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+		return cmd.Run()
+	case fields[0] == "cd":
+		dir := "~"
+		if len(fields) > 1 {
+			dir = strings.TrimSpace(strings.TrimPrefix(line, "cd"))
+		}
+		return r.cd(dir)
+	case fields[0] == "put" || fields[0] == "get":
+		if len(fields) != 3 {
+			return fmt.Errorf("usage: put <local> <remote> or get <remote> <local>")
+		}
+		if fields[0] == "put" {
+			return r.put(fields[1], fields[2])
+		}
+		return r.get(fields[1], fields[2])
+	}
+	return r.run(line, nil, os.Stdout)
+}
+
+func (r *replSession) prompt() string {
+	return r.host.Host + ":" + r.dir + "> "
+}
+
+func runRepl(args []string) int {
+	fs := flag.NewFlagSet("repl", flag.ExitOnError)
+	alias := fs.String("host", "", "alias of the host to run commands on")
+	fs.Parse(args)
+
+	h, err := loadHost(*alias)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if h.ProxyJump != "" {
+		// the in-process client connects directly
+		fmt.Fprintln(os.Stderr, "repl doesn't support hosts with proxy_jump")
+		return 1
+	}
+	client, err := dialNative(h)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to connect:", err)
+		return 1
+	}
+	defer client.Close()
+
+	r := &replSession{host: h, client: client}
+	if err := r.cd("."); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to run pwd:", err)
+		return 1
+	}
+	rl, err := readline.NewEx(&readline.Config{
+		Prompt:      r.prompt(),
+		HistoryFile: filepath.Join(filepath.Dir(configFilePath), "repl_history"),
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer rl.Close()
+
+	fmt.Println("Commands run on " + h.Host + ". !cmd runs cmd locally, put/get copy files, exit or ctrl+d quits.")
+	for {
+		line, err := rl.Readline()
+		if errors.Is(err, readline.ErrInterrupt) {
+			continue
+		} else if err != nil {
+			// io.EOF on ctrl+d
+			return 0
+		}
+		line = strings.TrimSpace(line)
+		switch line {
+		case "":
+			continue
+		case "exit", "quit":
+			return 0
+		}
+
+		err = r.eval(line)
+		var exitErr *ssh.ExitError
+		if errors.As(err, &exitErr) {
+			fmt.Fprintf(os.Stderr, "[exit status %d]\n", exitErr.ExitStatus())
+		} else if err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+		}
+		rl.SetPrompt(r.prompt())
+	}
+}
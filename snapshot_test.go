@@ -0,0 +1,83 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSaveSnapshotSameSecond(t *testing.T) {
+	old := configFilePath
+	t.Cleanup(func() { configFilePath = old })
+	configFilePath = filepath.Join(t.TempDir(), ".config")
+	if err := os.WriteFile(configFilePath, []byte("[settings]\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.Local)
+	var ids []string
+	for _, message := range []string{"before", "before", ""} {
+		id, err := saveSnapshot(message, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	want := []string{"20260102-030405-before", "20260102-030405-before-2", "20260102-030405"}
+	if !slices.Equal(ids, want) {
+		t.Fatalf("ids %v, want %v", ids, want)
+	}
+
+	snapshots, err := listSnapshots()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 3 {
+		t.Errorf("listed %d snapshots, want 3", len(snapshots))
+	}
+}
+
+func TestRestoreSnapshotBacksUp(t *testing.T) {
+	old := configFilePath
+	t.Cleanup(func() { configFilePath = old })
+	configFilePath = filepath.Join(t.TempDir(), ".config")
+
+	before := "[[hosts]]\nhost = \"web1\"\nhostname = \"10.0.0.1\"\n"
+	if err := os.WriteFile(configFilePath, []byte(before), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	id, err := saveSnapshot("web1", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	after := "[[hosts]]\nhost = \"db1\"\nhostname = \"10.0.0.2\"\n"
+	if err := os.WriteFile(configFilePath, []byte(after), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if code := restoreSnapshot(id, true); code != 0 {
+		t.Fatalf("restore exited with %d", code)
+	}
+	data, err := os.ReadFile(configFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != before {
+		t.Errorf("config after restore:\n%s\nwant\n%s", data, before)
+	}
+
+	// the config the restore replaced is kept as a backup
+	names := backupNames(t, backupDir())
+	if len(names) != 1 {
+		t.Fatalf("backups %v, want one", names)
+	}
+	backup, err := os.ReadFile(filepath.Join(backupDir(), names[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(backup) != after {
+		t.Errorf("backup:\n%s\nwant\n%s", backup, after)
+	}
+}
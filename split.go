@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// file of a split holding the settings, profiles and the untagged hosts
+const splitCommonFile = "common.toml"
+
+func runSplit(args []string) int {
+	fs := flag.NewFlagSet("split", flag.ExitOnError)
+	dir := fs.String("dir", "", "directory to write one TOML file per tag to")
+	ask := fs.Bool("ask", false, "ask which file a host with several tags goes to instead of copying it into each")
+	force := fs.Bool("force", false, "overwrite existing files")
+	yes := fs.Bool("yes", false, "don't ask before writing the files")
+	fs.Parse(args)
+
+	if *dir == "" {
+		fmt.Fprintln(os.Stderr, "usage: quickssh split --dir <dir> [--ask] [--force] [--yes]")
+		return 2
+	}
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load config:", err)
+		return 1
+	}
+
+	choose := duplicateTags
+	if *ask {
+		choose = askTag(bufio.NewReader(os.Stdin))
+	}
+	files := SplitConfig(config, choose)
+
+	if err := os.MkdirAll(*dir, 0o755); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	names := slices.Sorted(maps.Keys(files))
+	for _, name := range names {
+		if filepath.Base(name) != name || !filepath.IsLocal(name) {
+			fmt.Fprintf(os.Stderr, "refusing to write %q, it isn't a file name\n", name)
+			return 1
+		}
+	}
+	if !*force {
+		for _, name := range names {
+			if _, err := os.Stat(filepath.Join(*dir, name)); err == nil {
+				fmt.Fprintf(os.Stderr, "%s already exists, use --force to overwrite\n", filepath.Join(*dir, name))
+				return 1
+			}
+		}
+	}
+	aliases := hostAliases(config.Hosts)
+	if !*yes && !confirm(fmt.Sprintf("Split %d hosts (%s) into %d files in %s?", len(aliases), bulkSample(aliases), len(names), *dir)) {
+		return 1
+	}
+	for _, name := range names {
+		path := filepath.Join(*dir, name)
+		if err := writeConfigFile(path, files[name]); err != nil {
+			fmt.Fprintln(os.Stderr, "failed to write split:", err)
+			return 1
+		}
+		fmt.Printf("Wrote %d hosts to %s\n", len(files[name].Hosts), path)
+	}
+	if err := logOperation(config.Settings, "split into "+*dir, aliases); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to write the operations log:", err)
+	}
+	return 0
+}
+
+// hostAliases lists the alias of every host
+func hostAliases(hosts []SSHHost) []string {
+	aliases := make([]string, len(hosts))
+	for i, h := range hosts {
+		aliases[i] = h.Host
+	}
+	return aliases
+}
+
+// duplicateTags puts a host into the file of every tag it has
+func duplicateTags(h SSHHost, files []string) []string {
+	if len(files) > 1 {
+		fmt.Fprintf(os.Stderr, "warning: %s has several tags, it is copied into %s\n", h.Host, strings.Join(files, ", "))
+	}
+	return files
+}
+
+// askTag prompts for the file of each host with several tags
+func askTag(in *bufio.Reader) func(SSHHost, []string) []string {
+	return func(h SSHHost, files []string) []string {
+		if len(files) < 2 {
+			return files
+		}
+		for {
+			fmt.Printf("%s has several tags, which file should it go to?\n", h.Host)
+			for i, name := range files {
+				fmt.Printf("  [%d] %s\n", i+1, name)
+			}
+			fmt.Print("  [a] all of them\n> ")
+			answer, err := in.ReadString('\n')
+			answer = strings.TrimSpace(answer)
+			if answer == "a" || err != nil {
+				return files
+			}
+			if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(files) {
+				return files[n-1 : n]
+			}
+		}
+	}
+}
+
+// splitFileName is the file of the hosts tagged tag. Only letters, digits and
+// dashes are kept, so a tag like "../x" or "a/b" can't name a file outside
+// the directory. Empty if nothing is left of the tag.
+func splitFileName(tag string) string {
+	slug := slugify(tag)
+	if slug == "" {
+		return ""
+	}
+	return slug + ".toml"
+}
+
+// SplitConfig groups the hosts of config into one config per tag, keyed by
+// file name. Settings, profiles, templates, the [deploy] table and untagged
+// hosts go to common.toml. choose picks the files of a host out of the files
+// of its tags.
+func SplitConfig(config *Config, choose func(h SSHHost, files []string) []string) map[string]*Config {
+	files := map[string]*Config{
+		splitCommonFile: {
+			Settings:  config.Settings,
+			Profiles:  config.Profiles,
+			Templates: config.Templates,
+			Deploy:    config.Deploy,
+		},
+	}
+	for _, h := range config.Hosts {
+		var names []string
+		for _, tag := range h.Tags {
+			name := splitFileName(tag)
+			if name != "" && name != splitCommonFile && !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+		if len(names) == 0 {
+			names = []string{splitCommonFile}
+		}
+
+		for _, name := range choose(h, names) {
+			if files[name] == nil {
+				files[name] = &Config{}
+			}
+			files[name].Hosts = append(files[name].Hosts, h)
+		}
+	}
+	return files
+}
+
+func runMerge(args []string) int {
+	fs := flag.NewFlagSet("merge", flag.ExitOnError)
+	strategy := fs.String("strategy", string(MergeFirst), "which of two differing hosts with the same alias to keep: first, last, or error to stop")
+	output := fs.String("output", "", "write the merged config to this file instead of stdout")
+	yes := fs.Bool("yes", false, "with --output: don't ask before writing the file")
+	fs.Parse(args)
+
+	valid := slices.Contains([]MergeStrategy{MergeFirst, MergeLast, MergeError}, MergeStrategy(*strategy))
+	if fs.NArg() == 0 || !valid {
+		fmt.Fprintln(os.Stderr, "usage: quickssh merge [--strategy first|last|error] [--output file [--yes]] <file.toml>...")
+		return 2
+	}
+	return mergeFiles(fs.Args(), MergeStrategy(*strategy), *output, *yes, "merge into ")
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"cmp"
+	"maps"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+)
+
+func TestSplitFileName(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want string
+	}{
+		{"prod", "prod.toml"},
+		{"Web Servers", "web-servers.toml"},
+		{"a/b", "a-b.toml"},
+		{"../../etc/passwd", "etc-passwd.toml"},
+		{"..", ""},
+		{"/", ""},
+		{`..\windows`, "windows.toml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			if got := splitFileName(tt.tag); got != tt.want {
+				t.Errorf("splitFileName(%q) = %q, want %q", tt.tag, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitConfigFileNames(t *testing.T) {
+	config := &Config{Hosts: []SSHHost{
+		{Host: "web1", Tags: []string{"../web"}},
+		{Host: "db1", Tags: []string{".."}},
+		{Host: "app1", Tags: []string{"app/eu", "web"}},
+	}}
+	files := SplitConfig(config, func(h SSHHost, files []string) []string { return files })
+	names := slices.Sorted(maps.Keys(files))
+	if want := []string{"app-eu.toml", splitCommonFile, "web.toml"}; !slices.Equal(names, want) {
+		t.Fatalf("files %v, want %v", names, want)
+	}
+	if got := aliasesOf(files[splitCommonFile].Hosts); !slices.Equal(got, []string{"db1"}) {
+		t.Errorf("common.toml has %v, want the host whose tag names no file", got)
+	}
+	if got := aliasesOf(files["web.toml"].Hosts); !slices.Equal(got, []string{"web1", "app1"}) {
+		t.Errorf("web.toml has %v, want [web1 app1]", got)
+	}
+}
+
+func TestSplitMergeRoundTrip(t *testing.T) {
+	var config Config
+	if _, err := toml.Decode(`
+[settings]
+check_known_hosts = true
+stale_days = 30
+
+[profiles.fast]
+flags = ["-C"]
+
+[templates.web]
+user = "deploy"
+port = 2222
+
+[deploy]
+local_dir = "dist"
+remote_dir = "/srv/app"
+activate = "systemctl restart app"
+
+[[hosts]]
+host = "web1"
+hostname = "10.0.0.1"
+tags = ["web", "prod"]
+
+[[hosts]]
+host = "db1"
+hostname = "10.0.0.2"
+tags = ["db"]
+
+[hosts.deploy]
+remote_dir = "/srv/db"
+
+[[hosts]]
+host = "bastion"
+hostname = "10.0.0.3"
+`, &config); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	files := SplitConfig(&config, func(h SSHHost, files []string) []string { return files })
+	var configs []*Config
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		path := filepath.Join(dir, name)
+		if err := writeConfigFile(path, files[name]); err != nil {
+			t.Fatal(err)
+		}
+		var read Config
+		if _, err := toml.DecodeFile(path, &read); err != nil {
+			t.Fatal(err)
+		}
+		configs = append(configs, &read)
+	}
+
+	// copies of a host with several tags are identical, not conflicts
+	merged, conflicts, err := MergeConfigs(configs, MergeFirst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conflicts) > 0 {
+		t.Errorf("conflicts %+v, want none", conflicts)
+	}
+	// the hosts come back in the order of the files
+	byAlias := func(a, b SSHHost) int { return cmp.Compare(a.Host, b.Host) }
+	slices.SortFunc(config.Hosts, byAlias)
+	slices.SortFunc(merged.Hosts, byAlias)
+	if !reflect.DeepEqual(*merged, config) {
+		t.Errorf("split and merge gave\n%+v\nwant\n%+v", *merged, config)
+	}
+}
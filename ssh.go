@@ -0,0 +1,271 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// sent once an interactive ssh session started from the TUI has ended
+type connectFinishedMsg struct {
+	host SSHHost
+	err  error
+}
+
+// address returns the name ssh should connect to, falling back to the alias
+// when no HostName is set
+func (h SSHHost) address() string {
+	if h.HostName != "" {
+		return h.HostName
+	}
+	return h.Host
+}
+
+// port returns the configured port or the ssh default
+func (h SSHHost) port() int {
+	if h.Port != 0 {
+		return h.Port
+	}
+	return 22
+}
+
+// destination returns the user@host argument for ssh
+func (h SSHHost) destination() string {
+	if h.fromSSHConfig {
+		// ssh resolves everything else from its own config
+		return h.Host
+	}
+	if h.User != "" {
+		return h.User + "@" + h.address()
+	}
+	return h.address()
+}
+
+// sshArgs builds the ssh arguments for a host, ending with the destination so
+// a remote command can be appended
+func sshArgs(h SSHHost) []string {
+	return append(sshOptions(h), h.destination())
+}
+
+// ssh accepts -v up to three times
+const maxVerbose = 3
+
+// sshOptions returns the flags and -o options for a host without the
+// destination
+func sshOptions(h SSHHost) []string {
+	var args []string
+	if h.Verbose > 0 {
+		args = append(args, "-"+strings.Repeat("v", min(h.Verbose, maxVerbose)))
+	}
+	if h.fromSSHConfig {
+		// only the one-shot verbosity, the rest comes from ssh's config
+		return args
+	}
+
+	if h.Port != 0 {
+		args = append(args, "-p", strconv.Itoa(h.Port))
+	}
+	if h.IdentityFile != "" {
+		args = append(args, "-i", expandPath(h.IdentityFile))
+	}
+	if h.CertificateFile != "" {
+		args = append(args, "-o", "CertificateFile="+expandPath(h.CertificateFile))
+	}
+	if h.ForwardAgent {
+		args = append(args, "-A")
+	}
+	if h.ProxyJump != "" {
+		args = append(args, "-J", h.ProxyJump)
+	}
+	if h.ServerAliveInterval != 0 {
+		args = append(args, "-o", "ServerAliveInterval="+strconv.Itoa(h.ServerAliveInterval))
+	}
+	if h.ServerAliveCountMax != 0 {
+		args = append(args, "-o", "ServerAliveCountMax="+strconv.Itoa(h.ServerAliveCountMax))
+	}
+	for _, forward := range h.LocalForward {
+		args = append(args, "-o", "LocalForward="+forward)
+	}
+	for _, forward := range h.RemoteForward {
+		args = append(args, "-o", "RemoteForward="+forward)
+	}
+	if h.ControlMaster != "" {
+		args = append(args, "-o", "ControlMaster="+h.ControlMaster)
+	}
+	if h.ControlPath != "" {
+		args = append(args, "-o", "ControlPath="+h.ControlPath)
+	}
+	if h.ControlPersist != "" {
+		args = append(args, "-o", "ControlPersist="+h.ControlPersist)
+	}
+	for _, key := range slices.Sorted(maps.Keys(h.Options)) {
+		args = append(args, "-o", key+"="+h.Options[key])
+	}
+	// after the options, ssh keeps the first value it gets so options set
+	// by hand win
+	for _, option := range h.authOptions() {
+		args = append(args, "-o", option)
+	}
+	if h.Multiplexing {
+		args = append(args, multiplexOptions()...)
+	}
+	return args
+}
+
+// authOptions turn pubkey_only and password_only into ssh options, nothing
+// if neither is set. keyboard-interactive is how most servers ask for
+// passwords, so it goes with password authentication.
+func (h SSHHost) authOptions() []string {
+	switch {
+	case h.PubkeyOnly:
+		return []string{"PubkeyAuthentication=yes", "PasswordAuthentication=no", "KbdInteractiveAuthentication=no"}
+	case h.PasswordOnly:
+		return []string{"PubkeyAuthentication=no", "PasswordAuthentication=yes", "KbdInteractiveAuthentication=yes"}
+	}
+	return nil
+}
+
+// BuildSSHCommand returns the command for an interactive session on h
+func BuildSSHCommand(h SSHHost) *exec.Cmd {
+	return exec.Command(sshBinary, sshArgs(h)...)
+}
+
+// connect suspends the TUI and hands the terminal to ssh until it exits. The
+// profile's options come after the host's, and since ssh keeps the first
+// value it sees for an option, the host wins on conflicts.
+func connect(h SSHHost, p Profile, settings Settings) tea.Cmd {
+	args := append(sshOptions(h), p.args()...)
+	cmd := sshCommand(settings, append(args, h.destination())...)
+	return tea.Exec(newConnectExec(h, cmd, settings), func(err error) tea.Msg {
+		return connectFinishedMsg{host: h, err: err}
+	})
+}
+
+// connectExec runs an interactive ssh session together with what belongs to
+// a connection: the pre_connect hook, the host's tunnel and the post_connect
+// hook
+type connectExec struct {
+	*exec.Cmd
+	host     SSHHost
+	settings Settings
+}
+
+func newConnectExec(h SSHHost, cmd *exec.Cmd, settings Settings) *connectExec {
+	return &connectExec{Cmd: cmd, host: h, settings: settings}
+}
+
+func (c *connectExec) SetStdin(r io.Reader)  { c.Stdin = r }
+func (c *connectExec) SetStdout(w io.Writer) { c.Stdout = w }
+func (c *connectExec) SetStderr(w io.Writer) { c.Stderr = w }
+
+func (c *connectExec) Run() error {
+	if err := runHook(c.settings.PreConnect, c.host, -1, c.settings, c.Stdout, c.Stderr); err != nil {
+		if !c.settings.ContinueOnHookFailure {
+			// not wrapped, callers would take the hook's exit code for ssh's
+			return fmt.Errorf("pre_connect hook failed: %v", err)
+		}
+		fmt.Fprintln(c.Stderr, "pre_connect hook failed:", err)
+	}
+	if c.host.CertificateFile != "" {
+		// often written on demand by an agent or a login hook, so only warn
+		if _, err := os.Stat(expandPath(c.host.CertificateFile)); err != nil {
+			fmt.Fprintln(c.Stderr, "warning: certificate file", c.host.CertificateFile, "not found")
+		}
+	}
+	if c.host.VaultSSHRole != "" {
+		cleanup, err := c.addVaultCertificate()
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+	}
+	if c.host.PasswordCommand != "" {
+		cleanup, err := setupAskpass(c)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+	}
+	if err := addTunnel(c.host, c.Cmd); err != nil {
+		return err
+	}
+
+	// keep the end of ssh's stderr to show why a connection failed
+	stderr := c.Cmd.Stderr
+	tail := &tailBuffer{max: stderrTailSize}
+	if stderr != nil {
+		c.Cmd.Stderr = io.MultiWriter(stderr, tail)
+	} else {
+		c.Cmd.Stderr = tail
+	}
+	// a ControlPersist master may keep the pipe open after ssh exits
+	c.Cmd.WaitDelay = time.Second
+	err := c.Cmd.Start()
+	if err == nil {
+		removeState := recordTunnels(c.host.Host, c.Cmd)
+		err = c.Cmd.Wait()
+		removeState()
+	}
+	c.Cmd.Stderr = stderr
+	if errors.Is(err, exec.ErrWaitDelay) {
+		err = nil
+	}
+
+	if hookErr := runHook(c.settings.PostConnect, c.host, exitCode(err), c.settings, c.Stdout, c.Stderr); hookErr != nil {
+		fmt.Fprintln(c.Stderr, "post_connect hook failed:", hookErr)
+	}
+	if err != nil {
+		return &sessionError{err: err, stderr: string(tail.buf)}
+	}
+	return nil
+}
+
+// option looks up an ssh option of h, ignoring case like ssh does
+func (h SSHHost) option(name string) (string, bool) {
+	for key, value := range h.Options {
+		if strings.EqualFold(key, name) {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// remoteCommand builds a non-interactive ssh invocation of command on h.
+// BatchMode makes ssh fail instead of prompting, which would hang the caller.
+func remoteCommand(h SSHHost, command string) *exec.Cmd {
+	args := append(sshOptions(h), "-o", "BatchMode=yes", h.destination(), command)
+	return exec.Command(sshBinary, args...)
+}
+
+// runRemote runs command on h and returns its stdout, errors carry the tail
+// of stderr so ssh's reason for failing isn't lost
+func runRemote(h SSHHost, command string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := remoteCommand(h, command)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return stdout.String(), fmt.Errorf("%w: %s", err, lastLine(msg))
+		}
+		return stdout.String(), err
+	}
+	return stdout.String(), nil
+}
+
+func lastLine(s string) string {
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		return s[i+1:]
+	}
+	return s
+}
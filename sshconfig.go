@@ -0,0 +1,279 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ParseSSH reads hosts from OpenSSH client config syntax. Every concrete
+// alias of a Host block becomes its own host, wildcard patterns, negations
+// and Match blocks are skipped. Keywords without a matching SSHHost field are
+// kept in Options.
+func ParseSSH(r io.Reader) ([]SSHHost, error) {
+	var hosts []SSHHost
+	// indices into hosts of the aliases in the current Host block
+	var block []int
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		keyword, value := splitSSHConfigLine(line)
+		switch strings.ToLower(keyword) {
+		case "host":
+			block = block[:0]
+			for _, alias := range strings.Fields(value) {
+				if strings.ContainsAny(alias, "*?!") {
+					continue
+				}
+				block = append(block, len(hosts))
+				hosts = append(hosts, SSHHost{Host: alias})
+			}
+			continue
+		case "match":
+			block = block[:0]
+			continue
+		}
+
+		for _, i := range block {
+			setSSHConfigField(&hosts[i], keyword, value)
+		}
+	}
+	for i := range hosts {
+		hosts[i].foldQuicksshOptions()
+	}
+	return hosts, scanner.Err()
+}
+
+// foldQuicksshOptions turns the options FormatOpenSSHConfig writes for
+// pubkey_only, password_only and multiplexing back into those settings
+func (h *SSHHost) foldQuicksshOptions() {
+	if h.takeOptions(SSHHost{PubkeyOnly: true}.authOptions()) {
+		h.PubkeyOnly = true
+	} else if h.takeOptions(SSHHost{PasswordOnly: true}.authOptions()) {
+		h.PasswordOnly = true
+	}
+
+	control := map[string]*string{
+		"ControlMaster":  &h.ControlMaster,
+		"ControlPath":    &h.ControlPath,
+		"ControlPersist": &h.ControlPersist,
+	}
+	multiplex := multiplexConfig()
+	// the socket path is quickssh's own, other Control* values of the
+	// host were set explicitly and stay
+	var path string
+	for _, o := range multiplex {
+		if o[0] == "ControlPath" {
+			path = o[1]
+		}
+	}
+	if path == "" || h.ControlPath != path {
+		return
+	}
+	h.Multiplexing = true
+	for _, o := range multiplex {
+		if *control[o[0]] == o[1] {
+			*control[o[0]] = ""
+		}
+	}
+}
+
+// takeOptions removes options, given as "Keyword=value", from the Options of
+// h if all of them are set to these values
+func (h *SSHHost) takeOptions(options []string) bool {
+	for _, option := range options {
+		keyword, want, _ := strings.Cut(option, "=")
+		if value, ok := h.option(keyword); !ok || !strings.EqualFold(value, want) {
+			return false
+		}
+	}
+	for _, option := range options {
+		keyword, _, _ := strings.Cut(option, "=")
+		maps.DeleteFunc(h.Options, func(key, _ string) bool { return strings.EqualFold(key, keyword) })
+	}
+	if len(h.Options) == 0 {
+		h.Options = nil
+	}
+	return true
+}
+
+// splitSSHConfigLine splits "Keyword value" or "Keyword=value" and strips
+// quotes around the value
+func splitSSHConfigLine(line string) (string, string) {
+	i := strings.IndexAny(line, " \t=")
+	if i < 0 {
+		return line, ""
+	}
+	keyword := line[:i]
+	value := strings.TrimSpace(line[i:])
+	value = strings.TrimSpace(strings.TrimPrefix(value, "="))
+	return keyword, strings.Trim(value, `"`)
+}
+
+// setSSHConfigField applies one keyword to h. Like ssh, the first value of a
+// keyword wins.
+func setSSHConfigField(h *SSHHost, keyword, value string) {
+	switch strings.ToLower(keyword) {
+	case "hostname":
+		if h.HostName == "" {
+			h.HostName = value
+		}
+	case "user":
+		if h.User == "" {
+			h.User = value
+		}
+	case "port":
+		if h.Port == 0 {
+			h.Port, _ = strconv.Atoi(value)
+		}
+	case "identityfile":
+		if h.IdentityFile == "" {
+			h.IdentityFile = value
+		}
+	case "certificatefile":
+		if h.CertificateFile == "" {
+			h.CertificateFile = value
+		}
+	case "forwardagent":
+		h.ForwardAgent = strings.EqualFold(value, "yes")
+	case "proxyjump":
+		if h.ProxyJump == "" {
+			h.ProxyJump = value
+		}
+	case "serveraliveinterval":
+		if h.ServerAliveInterval == 0 {
+			h.ServerAliveInterval, _ = strconv.Atoi(value)
+		}
+	case "serveralivecountmax":
+		if h.ServerAliveCountMax == 0 {
+			h.ServerAliveCountMax, _ = strconv.Atoi(value)
+		}
+	case "localforward":
+		h.LocalForward = append(h.LocalForward, value)
+	case "remoteforward":
+		h.RemoteForward = append(h.RemoteForward, value)
+	case "controlmaster":
+		if h.ControlMaster == "" {
+			h.ControlMaster = value
+		}
+	case "controlpath":
+		if h.ControlPath == "" {
+			h.ControlPath = value
+		}
+	case "controlpersist":
+		if h.ControlPersist == "" {
+			h.ControlPersist = value
+		}
+	default:
+		if _, ok := h.option(keyword); ok {
+			return
+		}
+		if h.Options == nil {
+			h.Options = make(map[string]string)
+		}
+		h.Options[keyword] = value
+	}
+}
+
+// loadSSHConfigHosts parses ~/.ssh/config, a missing file yields no hosts
+func loadSSHConfigHosts() ([]SSHHost, error) {
+	f, err := os.Open(expandPath("~/.ssh/config"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	hosts, err := ParseSSH(f)
+	for i := range hosts {
+		hosts[i].fromSSHConfig = true
+	}
+	return hosts, err
+}
+
+// FormatOpenSSHConfig renders hosts as ~/.ssh/config Host blocks, the
+// inverse of ParseSSH
+func FormatOpenSSHConfig(hosts []SSHHost) string {
+	var b strings.Builder
+	for i, h := range hosts {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		if h.Desc != "" {
+			b.WriteString("# " + h.Desc + "\n")
+		}
+		b.WriteString("Host " + h.Host + "\n")
+
+		line := func(keyword, value string) {
+			if value != "" {
+				b.WriteString("    " + keyword + " " + value + "\n")
+			}
+		}
+		line("HostName", h.HostName)
+		line("User", h.User)
+		if h.Port != 0 {
+			line("Port", strconv.Itoa(h.Port))
+		}
+		line("IdentityFile", quoteSSHConfigValue(h.IdentityFile))
+		line("CertificateFile", quoteSSHConfigValue(h.CertificateFile))
+		if h.ForwardAgent {
+			line("ForwardAgent", "yes")
+		}
+		line("ProxyJump", h.ProxyJump)
+		if h.ServerAliveInterval != 0 {
+			line("ServerAliveInterval", strconv.Itoa(h.ServerAliveInterval))
+		}
+		if h.ServerAliveCountMax != 0 {
+			line("ServerAliveCountMax", strconv.Itoa(h.ServerAliveCountMax))
+		}
+		for _, forward := range h.LocalForward {
+			line("LocalForward", forward)
+		}
+		for _, forward := range h.RemoteForward {
+			line("RemoteForward", forward)
+		}
+		line("ControlMaster", h.ControlMaster)
+		line("ControlPath", quoteSSHConfigValue(h.ControlPath))
+		line("ControlPersist", h.ControlPersist)
+		for _, key := range slices.Sorted(maps.Keys(h.Options)) {
+			line(key, h.Options[key])
+		}
+		for _, option := range h.authOptions() {
+			keyword, value, _ := strings.Cut(option, "=")
+			line(keyword, value)
+		}
+		if h.Multiplexing {
+			// the host's own Control* settings are written above and win
+			set := map[string]bool{
+				"ControlMaster":  h.ControlMaster != "",
+				"ControlPath":    h.ControlPath != "",
+				"ControlPersist": h.ControlPersist != "",
+			}
+			for _, option := range multiplexConfig() {
+				if !set[option[0]] {
+					line(option[0], option[1])
+				}
+			}
+		}
+	}
+	return b.String()
+}
+
+// quoteSSHConfigValue quotes paths containing spaces
+func quoteSSHConfigValue(value string) string {
+	if strings.ContainsAny(value, " \t") {
+		return `"` + value + `"`
+	}
+	return value
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOpenSSHConfigRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		host SSHHost
+	}{
+		{"minimal", SSHHost{Host: "web1", HostName: "10.0.0.1"}},
+		{"all fields", SSHHost{
+			Host:                "web1",
+			HostName:            "web1.example.com",
+			User:                "deploy",
+			Port:                2222,
+			IdentityFile:        "~/.ssh/my key",
+			CertificateFile:     "~/.ssh/id_ed25519-cert.pub",
+			ForwardAgent:        true,
+			ProxyJump:           "bastion",
+			ServerAliveInterval: 30,
+			ServerAliveCountMax: 3,
+			LocalForward:        []string{"8080 localhost:80", "5432 db:5432"},
+			RemoteForward:       []string{"9000 localhost:9000"},
+			ControlMaster:       "auto",
+			ControlPath:         "~/.ssh/cm-%r@%h:%p",
+			ControlPersist:      "5m",
+		}},
+		{"options", SSHHost{Host: "web1", Options: map[string]string{"StrictHostKeyChecking": "accept-new", "Compression": "yes"}}},
+		{"pubkey only", SSHHost{Host: "web1", PubkeyOnly: true}},
+		{"password only", SSHHost{Host: "web1", PasswordOnly: true, Options: map[string]string{"Compression": "yes"}}},
+		{"multiplexing", SSHHost{Host: "web1", Multiplexing: true}},
+		{"multiplexing with its own persist", SSHHost{Host: "web1", Multiplexing: true, ControlPersist: "1h"}},
+		{"multiplexing and pubkey only", SSHHost{Host: "web1", Multiplexing: true, PubkeyOnly: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := FormatOpenSSHConfig([]SSHHost{tt.host})
+			hosts, err := ParseSSH(strings.NewReader(out))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(hosts) != 1 || !reflect.DeepEqual(hosts[0], tt.host) {
+				t.Errorf("parsed back\n%+v\nwant\n%+v\nfrom\n%s", hosts, tt.host, out)
+			}
+		})
+	}
+}
+
+func TestParseSSH(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   []SSHHost
+	}{
+		{
+			"several aliases",
+			"Host a b\n  HostName 10.0.0.1\n",
+			[]SSHHost{{Host: "a", HostName: "10.0.0.1"}, {Host: "b", HostName: "10.0.0.1"}},
+		},
+		{
+			"wildcards and match blocks",
+			"Host *\n  User root\nHost web*\n  User www\nMatch host x\n  User y\nHost db\n  User postgres\n",
+			[]SSHHost{{Host: "db", User: "postgres"}},
+		},
+		{
+			"first value wins",
+			"Host db\n  Port 2222\n  Port 22\n  LogLevel ERROR\n  loglevel DEBUG\n",
+			[]SSHHost{{Host: "db", Port: 2222, Options: map[string]string{"LogLevel": "ERROR"}}},
+		},
+		{
+			"equals sign and quotes",
+			"Host db\n  IdentityFile=\"~/.ssh/my key\"\n",
+			[]SSHHost{{Host: "db", IdentityFile: "~/.ssh/my key"}},
+		},
+		{
+			"partial auth options stay options",
+			"Host db\n  PubkeyAuthentication yes\n",
+			[]SSHHost{{Host: "db", Options: map[string]string{"PubkeyAuthentication": "yes"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hosts, err := ParseSSH(strings.NewReader(tt.config))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(hosts, tt.want) {
+				t.Errorf("got %+v, want %+v", hosts, tt.want)
+			}
+		})
+	}
+}
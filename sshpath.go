@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// sshBinary is the ssh every command runs, set from ssh_path by useSSHPath
+var sshBinary = "ssh"
+
+// sshPath is the ssh binary configured in ssh_path, "ssh" from the PATH if
+// not set
+func (s Settings) sshPath() string {
+	if s.SSHPath == "" {
+		return "ssh"
+	}
+	return expandPath(s.SSHPath)
+}
+
+// useSSHPath makes the commands run the ssh of s and returns a warning if it
+// can't be run. The binary is used anyway, the warning only explains why
+// connecting fails.
+func useSSHPath(s Settings) string {
+	sshBinary = s.sshPath()
+	if s.SSHPath == "" {
+		return ""
+	}
+	// LookPath checks that a path with a separator is an executable file
+	if _, err := exec.LookPath(sshBinary); err != nil {
+		return fmt.Sprintf("ssh_path %s can't be run: %v", s.SSHPath, err)
+	}
+	return ""
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewModelUsesSSHPath(t *testing.T) {
+	ssh := filepath.Join(t.TempDir(), "ssh")
+	if err := os.WriteFile(ssh, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	settings := "[settings]\nssh_path = \"" + filepath.ToSlash(ssh) + "\"\n\n[[hosts]]\nhost = \"web1\"\nhostname = \"10.0.0.1\"\n"
+	tests := []struct {
+		name   string
+		config string
+	}{
+		{"intact", settings},
+		// recovered from the intact sections at the top
+		{"damaged", settings + "\n[[hosts]]\nhost = \"web2\nhostname = \n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Cleanup(func() { sshBinary = "ssh" })
+			sshBinary = "ssh"
+			m := newTestModel(t, tt.config)
+			if len(m.hosts) != 1 {
+				t.Fatalf("loaded %d hosts, want 1", len(m.hosts))
+			}
+			if sshBinary != filepath.ToSlash(ssh) {
+				t.Errorf("sshBinary = %q, want %q", sshBinary, ssh)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// AddTag adds tag to the hosts with the given aliases that don't have it yet
+// and returns how many were changed. Nothing is changed if an alias is
+// unknown.
+func AddTag(hosts []SSHHost, aliases []string, tag string) (int, error) {
+	changed, err := addTag(hosts, aliases, tag)
+	return len(changed), err
+}
+
+// RemoveTag removes tag from the hosts matching the tag expression selector,
+// e.g. "staging" or "staging AND NOT db", and returns how many were changed
+func RemoveTag(hosts []SSHHost, selector string, tag string) (int, error) {
+	changed, err := removeTag(hosts, selector, tag)
+	return len(changed), err
+}
+
+func checkTagName(tag string) error {
+	if tag == "" {
+		return errors.New("the tag is empty")
+	}
+	if strings.ContainsAny(tag, ", \t") {
+		return fmt.Errorf("tag %q contains a comma or space", tag)
+	}
+	return nil
+}
+
+// addTag is AddTag returning the aliases of the changed hosts
+func addTag(hosts []SSHHost, aliases []string, tag string) ([]string, error) {
+	if err := checkTagName(tag); err != nil {
+		return nil, err
+	}
+	var indexes []int
+	for _, alias := range aliases {
+		i := slices.IndexFunc(hosts, func(h SSHHost) bool { return h.Host == alias })
+		if i < 0 {
+			return nil, fmt.Errorf("no host with alias %q", alias)
+		}
+		indexes = append(indexes, i)
+	}
+
+	var changed []string
+	for _, i := range indexes {
+		if slices.Contains(hosts[i].Tags, tag) {
+			continue
+		}
+		// the slice may be shared with a copy of the host
+		hosts[i].Tags = append(slices.Clip(hosts[i].Tags), tag)
+		changed = append(changed, hosts[i].Host)
+	}
+	return changed, nil
+}
+
+// removeTag is RemoveTag returning the aliases of the changed hosts
+func removeTag(hosts []SSHHost, selector string, tag string) ([]string, error) {
+	if err := checkTagName(tag); err != nil {
+		return nil, err
+	}
+	expr, err := parseTagExpr(selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tag selector %q: %w", selector, err)
+	}
+
+	var changed []string
+	for i, h := range hosts {
+		if !expr.eval(h.Tags) || !slices.Contains(h.Tags, tag) {
+			continue
+		}
+		hosts[i].Tags = slices.DeleteFunc(slices.Clone(h.Tags), func(t string) bool { return t == tag })
+		changed = append(changed, h.Host)
+	}
+	return changed, nil
+}
+
+func runTag(args []string) int {
+	fs := flag.NewFlagSet("tag", flag.ExitOnError)
+	add := fs.String("add", "", "tag to add to the hosts given with --hosts")
+	remove := fs.String("remove", "", "tag to remove from the hosts matching --tag-selector")
+	hostList := fs.String("hosts", "", "comma separated aliases, for --add")
+	selector := fs.String("tag-selector", "", "tag expression, for --remove (default: the tag itself)")
+	fs.Parse(args)
+
+	if (*add == "") == (*remove == "") || *add != "" && *hostList == "" {
+		fmt.Fprintln(os.Stderr, "usage: quickssh tag --add <tag> --hosts a,b | --remove <tag> [--tag-selector expr]")
+		return 2
+	}
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load config:", err)
+		return 1
+	}
+
+	var changed []string
+	var op string
+	if *add != "" {
+		var aliases []string
+		for _, alias := range strings.Split(*hostList, ",") {
+			if alias = strings.TrimSpace(alias); alias != "" {
+				aliases = append(aliases, alias)
+			}
+		}
+		changed, err = addTag(config.Hosts, aliases, *add)
+		op = "add-tag " + *add
+	} else {
+		changed, err = removeTag(config.Hosts, cmp.Or(*selector, *remove), *remove)
+		op = "remove-tag " + *remove
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if len(changed) == 0 {
+		fmt.Println("No host needed a change")
+		return 0
+	}
+
+	if err := saveConfig(config); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to save config:", err)
+		return 1
+	}
+	if err := logOperation(config.Settings, op, changed); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to log the operation:", err)
+	}
+	fmt.Printf("Changed %d hosts: %s\n", len(changed), strings.Join(changed, ", "))
+	return 0
+}
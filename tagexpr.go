@@ -0,0 +1,174 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// tagExpr is a boolean expression over the tags of a host, e.g.
+// "prod AND (web OR api) AND NOT legacy"
+type tagExpr interface {
+	eval(tags []string) bool
+}
+
+type tagName string
+
+func (t tagName) eval(tags []string) bool {
+	for _, tag := range tags {
+		if strings.EqualFold(tag, string(t)) {
+			return true
+		}
+	}
+	return false
+}
+
+type tagNot struct{ expr tagExpr }
+
+func (n tagNot) eval(tags []string) bool { return !n.expr.eval(tags) }
+
+type tagAnd struct{ left, right tagExpr }
+
+func (a tagAnd) eval(tags []string) bool { return a.left.eval(tags) && a.right.eval(tags) }
+
+type tagOr struct{ left, right tagExpr }
+
+func (o tagOr) eval(tags []string) bool { return o.left.eval(tags) || o.right.eval(tags) }
+
+// tokenizeTags splits an expression into words and parentheses
+func tokenizeTags(s string) []string {
+	var tokens []string
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			tokens = append(tokens, word.String())
+			word.Reset()
+		}
+	}
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')':
+			flush()
+			tokens = append(tokens, string(r))
+		case r == ' ' || r == '\t':
+			flush()
+		default:
+			word.WriteRune(r)
+		}
+	}
+	flush()
+	return tokens
+}
+
+// parseTagExpr parses AND, OR and NOT, case insensitive, with parentheses.
+// NOT binds tightest, then AND, then OR.
+func parseTagExpr(s string) (tagExpr, error) {
+	p := &tagParser{tokens: tokenizeTags(s)}
+	if len(p.tokens) == 0 {
+		return nil, errors.New("empty expression")
+	}
+	expr, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos])
+	}
+	return expr, nil
+}
+
+type tagParser struct {
+	tokens []string
+	pos    int
+}
+
+// peek returns the next token, keywords uppercased
+func (p *tagParser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	tok := p.tokens[p.pos]
+	if upper := strings.ToUpper(tok); upper == "AND" || upper == "OR" || upper == "NOT" {
+		return upper
+	}
+	return tok
+}
+
+func (p *tagParser) or() (tagExpr, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "OR" {
+		p.pos++
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = tagOr{left, right}
+	}
+	return left, nil
+}
+
+func (p *tagParser) and() (tagExpr, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "AND" {
+		p.pos++
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = tagAnd{left, right}
+	}
+	return left, nil
+}
+
+func (p *tagParser) unary() (tagExpr, error) {
+	switch tok := p.peek(); tok {
+	case "":
+		return nil, errors.New("unexpected end of expression")
+	case "NOT":
+		p.pos++
+		expr, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return tagNot{expr}, nil
+	case "(":
+		p.pos++
+		expr, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, errors.New("missing )")
+		}
+		p.pos++
+		return expr, nil
+	case ")", "AND", "OR":
+		return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos])
+	default:
+		p.pos++
+		return tagName(tok), nil
+	}
+}
+
+// tagMatcher evaluates query as a tag expression, or if it isn't one, as a
+// substring of any tag
+func tagMatcher(query string) (match func(tags []string) bool, isExpr bool) {
+	if expr, err := parseTagExpr(query); err == nil {
+		return expr.eval, true
+	}
+	query = strings.ToLower(strings.TrimSpace(query))
+	return func(tags []string) bool {
+		for _, tag := range tags {
+			if strings.Contains(strings.ToLower(tag), query) {
+				return true
+			}
+		}
+		return false
+	}, false
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTagExpr(t *testing.T) {
+	tests := []struct {
+		expr string
+		want tagExpr
+		err  string
+	}{
+		{"prod", tagName("prod"), ""},
+		{"prod AND web", tagAnd{tagName("prod"), tagName("web")}, ""},
+		{"staging or qa", tagOr{tagName("staging"), tagName("qa")}, ""},
+		{"NOT legacy", tagNot{tagName("legacy")}, ""},
+		// AND binds tighter than OR
+		{"a OR b AND c", tagOr{tagName("a"), tagAnd{tagName("b"), tagName("c")}}, ""},
+		{"(a OR b) AND c", tagAnd{tagOr{tagName("a"), tagName("b")}, tagName("c")}, ""},
+		{"NOT NOT a", tagNot{tagNot{tagName("a")}}, ""},
+		{"NOT (a AND b)", tagNot{tagAnd{tagName("a"), tagName("b")}}, ""},
+		{"a AND b AND c", tagAnd{tagAnd{tagName("a"), tagName("b")}, tagName("c")}, ""},
+		{"(a)OR(b)", tagOr{tagName("a"), tagName("b")}, ""},
+		{"", nil, "empty expression"},
+		{"   ", nil, "empty expression"},
+		{"prod AND", nil, "unexpected end of expression"},
+		{"AND prod", nil, `unexpected "AND"`},
+		{"(prod", nil, "missing )"},
+		{"prod)", nil, `unexpected ")"`},
+		{"prod web", nil, `unexpected "web"`},
+		{"()", nil, `unexpected ")"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := parseTagExpr(tt.expr)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parsed %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTagMatcher(t *testing.T) {
+	hosts := []SSHHost{
+		{Host: "web1", Tags: []string{"prod", "web"}},
+		{Host: "web2", Tags: []string{"staging", "web"}},
+		{Host: "api1", Tags: []string{"Prod", "api", "legacy"}},
+		{Host: "qa1", Tags: []string{"qa"}},
+		{Host: "bare"},
+	}
+	tests := []struct {
+		query  string
+		want   []string
+		isExpr bool
+	}{
+		{"prod AND web", []string{"web1"}, true},
+		{"staging OR qa", []string{"web2", "qa1"}, true},
+		// tags are matched ignoring case
+		{"prod AND NOT legacy", []string{"web1"}, true},
+		{"(web OR api) AND NOT staging", []string{"web1", "api1"}, true},
+		{"NOT prod", []string{"web2", "qa1", "bare"}, true},
+		// a single word is a tag, matched whole
+		{"stag", nil, true},
+		{"STAGING", []string{"web2"}, true},
+		// no expression, a substring of any tag
+		{"prod web", nil, false},
+		{"(pro", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			match, isExpr := tagMatcher(tt.query)
+			if isExpr != tt.isExpr {
+				t.Errorf("isExpr = %t, want %t", isExpr, tt.isExpr)
+			}
+			var got []string
+			for _, h := range hosts {
+				if match(h.Tags) {
+					got = append(got, h.Host)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("matched %v, want %v", got, tt.want)
+			}
+			var ranked []string
+			for _, r := range tagFilterRanks(hosts, tt.query) {
+				ranked = append(ranked, hosts[r.Index].Host)
+			}
+			if !reflect.DeepEqual(ranked, tt.want) {
+				t.Errorf("filter kept %v, want %v", ranked, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// openTagFilter shows the tag expression input in place of the detail panel
+func (m *model) openTagFilter() tea.Cmd {
+	m.tagInput = textinput.New()
+	m.tagInput.Prompt = "tags: "
+	m.tagInput.Placeholder = "prod AND (web OR api)"
+	m.tagInput.SetValue(m.tagQuery)
+	m.view = tagFilterView
+	return m.tagInput.Focus()
+}
+
+// updateTagFilter applies the expression on enter, or cancels on esc
+func (m *model) updateTagFilter(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		m.view = listView
+		return nil
+	case "enter":
+		m.view = listView
+		m.tagQuery = m.tagInput.Value()
+		if m.tagQuery == "" {
+			m.list.ResetFilter()
+			return nil
+		}
+		m.list.Filter = m.rankFilter()
+		m.list.SetFilterText(m.tagQuery)
+		return nil
+	}
+	var cmd tea.Cmd
+	m.tagInput, cmd = m.tagInput.Update(msg)
+	return cmd
+}
+
+// tagFilterRanks keeps the items whose host matches the tag query
+func tagFilterRanks(hosts []SSHHost, query string) []list.Rank {
+	match, _ := tagMatcher(query)
+	var ranks []list.Rank
+	for i, h := range hosts {
+		if match(h.Tags) {
+			ranks = append(ranks, list.Rank{Index: i})
+		}
+	}
+	return ranks
+}
+
+func (m model) tagFilterView() string {
+	query := m.tagInput.Value()
+	var preview string
+	if query != "" {
+		hosts := m.listedHosts()
+		count := len(tagFilterRanks(hosts, query))
+		if _, isExpr := tagMatcher(query); isExpr {
+			preview = fmt.Sprintf("%d of %d hosts match", count, len(hosts))
+		} else {
+			preview = fmt.Sprintf("Not an expression, %d of %d hosts have a tag containing it", count, len(hosts))
+		}
+	}
+	return titleStyle.Render("Filter by tags") + "\n\n" +
+		m.tagInput.View() + "\n\n" +
+		preview + "\n\n" +
+		checkFixStyle.Render("AND, OR, NOT and parentheses\nenter: apply • esc: cancel")
+}
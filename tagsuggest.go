@@ -0,0 +1,75 @@
+package main
+
+import (
+	"slices"
+	"strings"
+)
+
+// most tag suggestions shown below the tags field
+const maxTagSuggestions = 5
+
+// allTags returns every tag used by hosts once, sorted
+func allTags(hosts []SSHHost) []string {
+	var tags []string
+	for _, h := range hosts {
+		for _, tag := range h.Tags {
+			if !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	slices.Sort(tags)
+	return tags
+}
+
+// suggestTags returns the existing tags matching the tag being typed, the
+// part of input after the last comma. Tags already in input are left out. A
+// prefix match beats a substring, which beats the letters in order.
+func suggestTags(tags []string, input string) []string {
+	entered := strings.Split(input, ",")
+	typing := strings.ToLower(strings.TrimSpace(entered[len(entered)-1]))
+	if typing == "" {
+		return nil
+	}
+	done := func(tag string) bool {
+		return slices.ContainsFunc(entered[:len(entered)-1], func(e string) bool {
+			return strings.EqualFold(strings.TrimSpace(e), tag)
+		})
+	}
+
+	var prefix, substring, fuzzy []string
+	for _, tag := range tags {
+		lower := strings.ToLower(tag)
+		switch {
+		case done(tag) || lower == typing:
+		case strings.HasPrefix(lower, typing):
+			prefix = append(prefix, tag)
+		case strings.Contains(lower, typing):
+			substring = append(substring, tag)
+		case isSubsequence(typing, lower):
+			fuzzy = append(fuzzy, tag)
+		}
+	}
+	suggestions := slices.Concat(prefix, substring, fuzzy)
+	return suggestions[:min(len(suggestions), maxTagSuggestions)]
+}
+
+// isSubsequence reports whether the letters of sub appear in s in order
+func isSubsequence(sub, s string) bool {
+	q := []rune(sub)
+	for _, r := range s {
+		if len(q) > 0 && r == q[0] {
+			q = q[1:]
+		}
+	}
+	return len(q) == 0
+}
+
+// completeTag replaces the tag being typed in input with tag
+func completeTag(input, tag string) string {
+	i := strings.LastIndex(input, ",")
+	if i < 0 {
+		return tag
+	}
+	return input[:i+1] + " " + tag
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// colours of the host prefixes when tailing several hosts
+var tailHostColors = []lipgloss.Color{"#25A065", "#5A9BD5", "#E8A33D", "#C678DD", "#56B6C2", "#E06C75"}
+
+// tailSession follows one file on one host
+type tailSession struct {
+	host   SSHHost
+	prefix string
+	cmd    *exec.Cmd
+}
+
+// tailCommand runs tail -f on h, ssh's errors go straight to stderr
+func tailCommand(h SSHHost, path string, lines int) *exec.Cmd {
+	cmd := remoteCommand(h, fmt.Sprintf("tail -n %d -f -- %s", lines, shellQuote(path)))
+	cmd.Args = slices.Insert(cmd.Args, 1, "-T")
+	cmd.Stderr = os.Stderr
+	return cmd
+}
+
+// printTailLines copies lines from r to stdout with the local time and the
+// session's prefix, skipping lines that don't match grep
+func printTailLines(r io.Reader, prefix string, grep *regexp.Regexp, mu *sync.Mutex) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if grep != nil && !grep.MatchString(line) {
+			continue
+		}
+		mu.Lock()
+		fmt.Printf("%s %s%s\n", time.Now().Format(time.TimeOnly), prefix, line)
+		mu.Unlock()
+	}
+}
+
+func runTail(args []string) int {
+	fs := flag.NewFlagSet("tail", flag.ExitOnError)
+	alias := fs.String("host", "", "alias of the host")
+	multi := fs.String("multi-host", "", "tail several hosts: tag:<tag> or a comma separated alias list")
+	path := fs.String("file", "", "file to follow on the host")
+	lines := fs.Int("lines", 100, "lines of the file to show first")
+	pattern := fs.String("grep", "", "only show lines matching this regular expression")
+	fs.Parse(args)
+
+	if *path == "" {
+		fmt.Fprintln(os.Stderr, "no file given, use --file <path>")
+		return 2
+	}
+	var grep *regexp.Regexp
+	if *pattern != "" {
+		var err error
+		if grep, err = regexp.Compile(*pattern); err != nil {
+			fmt.Fprintln(os.Stderr, "invalid --grep:", err)
+			return 2
+		}
+	}
+
+	var hosts []SSHHost
+	if *multi != "" {
+		config, err := loadConfig()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "failed to load config:", err)
+			return 1
+		}
+		aliases, tag := *multi, ""
+		if t, ok := strings.CutPrefix(*multi, "tag:"); ok {
+			aliases, tag = "", t
+		}
+		if hosts, err = selectHosts(config.Hosts, aliases, tag); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	} else {
+		h, err := loadHost(*alias)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		hosts = []SSHHost{h}
+	}
+
+	width := 0
+	for _, h := range hosts {
+		width = max(width, len(h.Host))
+	}
+	var sessions []*tailSession
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i, h := range hosts {
+		s := &tailSession{host: h, cmd: tailCommand(h, *path, *lines)}
+		if len(hosts) > 1 {
+			style := lipgloss.NewStyle().Foreground(tailHostColors[i%len(tailHostColors)])
+			s.prefix = style.Render(fmt.Sprintf("%-"+strconv.Itoa(width)+"s", h.Host)) + " | "
+		}
+		stdout, err := s.cmd.StdoutPipe()
+		if err == nil {
+			err = s.cmd.Start()
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to tail %s: %v\n", h.Host, err)
+			continue
+		}
+		sessions = append(sessions, s)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			printTailLines(stdout, s.prefix, grep, &mu)
+		}()
+	}
+	if len(sessions) == 0 {
+		return 1
+	}
+
+	// stop every ssh on ctrl+c so none is left behind
+	interrupted := make(chan os.Signal, 1)
+	signal.Notify(interrupted, os.Interrupt)
+	defer signal.Stop(interrupted)
+	stopped := false
+	go func() {
+		if _, ok := <-interrupted; ok {
+			mu.Lock()
+			stopped = true
+			mu.Unlock()
+			for _, s := range sessions {
+				s.cmd.Process.Signal(os.Interrupt)
+			}
+		}
+	}()
+
+	wg.Wait()
+	failed := false
+	for _, s := range sessions {
+		if err := s.cmd.Wait(); err != nil {
+			mu.Lock()
+			if !stopped {
+				fmt.Fprintf(os.Stderr, "tail on %s ended: %v\n", s.host.Host, err)
+				failed = true
+			}
+			mu.Unlock()
+		}
+	}
+	if failed {
+		return 1
+	}
+	return 0
+}
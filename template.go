@@ -0,0 +1,150 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// CloneHost copies original and sets the fields named in overrides, keyed by
+// their config names like "hostname" or "port". Lists are given comma
+// separated. The cloud identity isn't copied, the clone is another machine.
+func CloneHost(original SSHHost, overrides map[string]string) (SSHHost, error) {
+	clone := original
+	clone.Tags = slices.Clone(original.Tags)
+	clone.LocalForward = slices.Clone(original.LocalForward)
+	clone.RemoteForward = slices.Clone(original.RemoteForward)
+	clone.Options = maps.Clone(original.Options)
+	if original.Deploy != nil {
+		deploy := *original.Deploy
+		clone.Deploy = &deploy
+	}
+	clone.CloudID, clone.Decommissioned = "", false
+
+	v := reflect.ValueOf(&clone).Elem()
+	for _, name := range slices.Sorted(maps.Keys(overrides)) {
+		field, ok := hostField(v, name)
+		if !ok {
+			return SSHHost{}, fmt.Errorf("unknown or unsupported field %q", name)
+		}
+		if err := setHostField(field, overrides[name]); err != nil {
+			return SSHHost{}, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return clone, nil
+}
+
+// hostField finds the field of the host value v by its toml name
+func hostField(v reflect.Value, name string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := range t.NumField() {
+		tag, _, _ := strings.Cut(t.Field(i).Tag.Get("toml"), ",")
+		if tag == name && t.Field(i).IsExported() {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// setHostField parses value into a string, number, bool or string list field
+func setHostField(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+		field.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%q is not true or false", value)
+		}
+		field.SetBool(b)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("can't be set from the command line")
+		}
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("can't be set from the command line")
+	}
+	return nil
+}
+
+// keyValueFlag collects repeatable key=value flags
+type keyValueFlag map[string]string
+
+func (f keyValueFlag) String() string { return fmt.Sprint(map[string]string(f)) }
+func (f keyValueFlag) Set(v string) error {
+	key, value, ok := strings.Cut(v, "=")
+	if !ok {
+		return fmt.Errorf("%q is not key=value", v)
+	}
+	f[strings.TrimSpace(key)] = value
+	return nil
+}
+
+func runTemplateHost(args []string) int {
+	fs := flag.NewFlagSet("template-host", flag.ExitOnError)
+	from := fs.String("from", "", "alias of the host to copy")
+	alias := fs.String("new-alias", "", "alias of the new host")
+	hostname := fs.String("hostname", "", "hostname of the new host")
+	overrides := keyValueFlag{}
+	fs.Var(overrides, "set", "other field to change as name=value, e.g. user=deploy, can be repeated")
+	fs.Parse(args)
+
+	if *from == "" || *alias == "" {
+		fmt.Fprintln(os.Stderr, "usage: quickssh template-host --from <alias> --new-alias <alias> [--hostname <address>] [--set field=value]...")
+		return 2
+	}
+	if strings.ContainsAny(*alias, " \t") {
+		fmt.Fprintln(os.Stderr, "the alias must not contain spaces")
+		return 2
+	}
+	overrides["host"] = *alias
+	if *hostname != "" {
+		overrides["hostname"] = *hostname
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load config:", err)
+		return 1
+	}
+	i := slices.IndexFunc(config.Hosts, func(h SSHHost) bool { return h.Host == *from })
+	if i < 0 {
+		fmt.Fprintf(os.Stderr, "no host with alias %q\n", *from)
+		return 1
+	}
+	if slices.ContainsFunc(config.Hosts, func(h SSHHost) bool { return h.Host == *alias }) {
+		fmt.Fprintf(os.Stderr, "a host with alias %q already exists\n", *alias)
+		return 1
+	}
+
+	clone, err := CloneHost(config.Hosts[i], overrides)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	config.Hosts = append(config.Hosts, clone)
+	if err := saveConfig(config); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to save config:", err)
+		return 1
+	}
+	fmt.Printf("Added %s (%s) as a copy of %s\n", clone.Host, clone.address(), *from)
+	return 0
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+)
+
+const topRefreshInterval = 5 * time.Second
+
+// separates the top output from /proc/meminfo in the remote command output
+const topSeparator = "--- quickssh meminfo ---"
+
+var (
+	topIdleRegexp = regexp.MustCompile(`([\d.]+)\s*%?\s*id\b`)
+
+	topPanelStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("#25A065")).
+			Padding(0, 1)
+)
+
+type TopProcess struct {
+	PID     string
+	User    string
+	CPU     float64
+	Mem     float64
+	Command string
+}
+
+type TopStats struct {
+	CPUPercent float64
+	Processes  []TopProcess
+}
+
+type MemStats struct {
+	TotalKB     int
+	AvailableKB int
+}
+
+func (s MemStats) UsedKB() int { return s.TotalKB - s.AvailableKB }
+
+// ParseTopOutput extracts the overall cpu usage and the top five processes
+// from the output of top -b -n 1
+func ParseTopOutput(output string) (*TopStats, error) {
+	stats := &TopStats{}
+	foundCPU := false
+	var cpuCol, memCol, cmdCol = -1, -1, -1
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		fields := strings.Fields(line)
+
+		switch {
+		case strings.Contains(line, "Cpu(s)"):
+			match := topIdleRegexp.FindStringSubmatch(line)
+			if match == nil {
+				return nil, fmt.Errorf("unrecognized cpu line %q", line)
+			}
+			idle, _ := strconv.ParseFloat(match[1], 64)
+			stats.CPUPercent = 100 - idle
+			foundCPU = true
+
+		case len(fields) > 0 && fields[0] == "PID":
+			for i, f := range fields {
+				switch f {
+				case "%CPU":
+					cpuCol = i
+				case "%MEM":
+					memCol = i
+				case "COMMAND":
+					cmdCol = i
+				}
+			}
+
+		case cmdCol >= 0 && len(fields) > cmdCol && len(stats.Processes) < 5:
+			p := TopProcess{PID: fields[0], User: fields[1], Command: strings.Join(fields[cmdCol:], " ")}
+			if cpuCol >= 0 {
+				p.CPU, _ = strconv.ParseFloat(fields[cpuCol], 64)
+			}
+			if memCol >= 0 {
+				p.Mem, _ = strconv.ParseFloat(fields[memCol], 64)
+			}
+			stats.Processes = append(stats.Processes, p)
+		}
+	}
+
+	if !foundCPU {
+		return nil, errors.New("no cpu summary in top output")
+	}
+	return stats, nil
+}
+
+// ParseMemInfo reads total and available memory from /proc/meminfo
+func ParseMemInfo(output string) (*MemStats, error) {
+	stats := &MemStats{}
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		value, err := strconv.Atoi(fields[1])
+		if err != nil {
+			continue
+		}
+		switch fields[0] {
+		case "MemTotal:":
+			stats.TotalKB = value
+		case "MemAvailable:":
+			stats.AvailableKB = value
+		}
+	}
+
+	if stats.TotalKB == 0 {
+		return nil, errors.New("no MemTotal in meminfo")
+	}
+	return stats, nil
+}
+
+// state of the resource dashboard, seq tells refreshes of an older
+// dashboard apart so they can be dropped
+type topDashboard struct {
+	host  SSHHost
+	seq   int
+	stats *TopStats
+	mem   *MemStats
+	err   error
+}
+
+type topStatsMsg struct {
+	seq   int
+	stats *TopStats
+	mem   *MemStats
+	err   error
+}
+
+type topTickMsg struct{ seq int }
+
+func fetchTop(h SSHHost, seq int) tea.Cmd {
+	return func() tea.Msg {
+		out, err := runRemote(h, "top -b -n 1 && echo '"+topSeparator+"' && cat /proc/meminfo")
+		if err != nil {
+			return topStatsMsg{seq: seq, err: err}
+		}
+
+		topOut, memOut, _ := strings.Cut(out, topSeparator)
+		stats, err := ParseTopOutput(topOut)
+		if err != nil {
+			return topStatsMsg{seq: seq, err: err}
+		}
+		mem, err := ParseMemInfo(memOut)
+		return topStatsMsg{seq: seq, stats: stats, mem: mem, err: err}
+	}
+}
+
+func topTick(seq int) tea.Cmd {
+	return tea.Tick(topRefreshInterval, func(time.Time) tea.Msg {
+		return topTickMsg{seq: seq}
+	})
+}
+
+func (m model) topView() string {
+	d := m.top
+	header := titleStyle.Render("Resources of " + d.host.Host)
+	footer := checkFixStyle.Render(fmt.Sprintf("refreshes every %s • esc: back", topRefreshInterval))
+
+	if d.stats == nil {
+		status := "Loading…"
+		if d.err != nil {
+			status = errorMessageStyle(d.err.Error())
+		}
+		return lipgloss.JoinVertical(lipgloss.Left, header, "", status, "", footer)
+	}
+
+	cpu := topPanelStyle.Render(fmt.Sprintf("CPU\n\n%5.1f%%\n%s", d.stats.CPUPercent, usageBar(d.stats.CPUPercent, 20)))
+
+	memText := "Memory\n\nunavailable"
+	if d.mem != nil {
+		used := float64(d.mem.UsedKB()) / float64(d.mem.TotalKB) * 100
+		memText = fmt.Sprintf("Memory\n\n%.1f / %.1f GiB\n%s",
+			float64(d.mem.UsedKB())/1024/1024, float64(d.mem.TotalKB)/1024/1024, usageBar(used, 20))
+	}
+	mem := topPanelStyle.Render(memText)
+
+	processes := table.New().
+		Border(lipgloss.RoundedBorder()).
+		BorderStyle(lipgloss.NewStyle().Foreground(lipgloss.Color("#25A065"))).
+		Headers("PID", "User", "%CPU", "%MEM", "Command").
+		StyleFunc(func(row, col int) lipgloss.Style {
+			if row == table.HeaderRow {
+				return dashboardHeaderStyle
+			}
+			return lipgloss.NewStyle().Padding(0, 1)
+		})
+	for _, p := range d.stats.Processes {
+		processes.Row(p.PID, p.User, fmt.Sprintf("%.1f", p.CPU), fmt.Sprintf("%.1f", p.Mem), p.Command)
+	}
+
+	sections := []string{header, "", lipgloss.JoinHorizontal(lipgloss.Top, cpu, " ", mem), processes.Render()}
+	if d.err != nil {
+		sections = append(sections, errorMessageStyle("Last refresh failed: "+d.err.Error()))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, append(sections, footer)...)
+}
+
+// usageBar draws a percentage as a bar coloured by how full it is
+func usageBar(percent float64, width int) string {
+	filled := min(max(int(percent/100*float64(width)+0.5), 0), width)
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+
+	color := lipgloss.Color("#04B575")
+	switch {
+	case percent > 90:
+		color = lipgloss.Color("#ED567A")
+	case percent > 75:
+		color = lipgloss.Color("#F2C94C")
+	}
+	return lipgloss.NewStyle().Foreground(color).Render(bar)
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestParseTopOutput(t *testing.T) {
+	tests := []struct {
+		name      string
+		out       string
+		cpu       float64
+		processes []TopProcess
+		wantErr   bool
+	}{
+		{
+			name: "procps-ng",
+			out: `top - 10:15:01 up 12 days,  3:04,  1 user,  load average: 0.08, 0.03, 0.01
+Tasks: 112 total,   1 running, 111 sleeping,   0 stopped,   0 zombie
+%Cpu(s):  3.1 us,  1.6 sy,  0.0 ni, 95.3 id,  0.0 wa,  0.0 hi,  0.0 si,  0.0 st
+MiB Mem :   7951.2 total,   1203.4 free,   2210.8 used,   4537.0 buff/cache
+MiB Swap:   2048.0 total,   2048.0 free,      0.0 used.   5412.1 avail Mem
+
+    PID USER      PR  NI    VIRT    RES    SHR S  %CPU  %MEM     TIME+ COMMAND
+   1234 postgres  20   0  215420  28764  25140 S   6.2   0.4   1:02.33 postgres: checkpointer
+    871 www-data  20   0   55948   6420   4612 S   1.3   0.1   0:04.10 nginx
+      1 root      20   0  167744  13124   8412 S   0.0   0.2   0:12.41 systemd
+      2 root      20   0       0      0      0 S   0.0   0.0   0:00.05 kthreadd
+      3 root       0 -20       0      0      0 I   0.0   0.0   0:00.00 rcu_gp
+      4 root       0 -20       0      0      0 I   0.0   0.0   0:00.00 rcu_par_gp
+`,
+			cpu: 4.7,
+			processes: []TopProcess{
+				{"1234", "postgres", 6.2, 0.4, "postgres: checkpointer"},
+				{"871", "www-data", 1.3, 0.1, "nginx"},
+				{"1", "root", 0, 0.2, "systemd"},
+				{"2", "root", 0, 0, "kthreadd"},
+				{"3", "root", 0, 0, "rcu_gp"},
+			},
+		},
+		{
+			name: "old procps",
+			out: `top - 10:15:01 up 40 days,  2:11,  2 users,  load average: 0.00, 0.01, 0.05
+Tasks: 150 total,   1 running, 149 sleeping,   0 stopped,   0 zombie
+Cpu(s):  2.3%us,  0.7%sy,  0.0%ni, 96.8%id,  0.2%wa,  0.0%hi,  0.0%si,  0.0%st
+Mem:   3924512k total,  3712000k used,   212512k free,   190344k buffers
+Swap:  2097148k total,    12288k used,  2084860k free,  2804544k cached
+
+  PID USER      PR  NI  VIRT  RES  SHR S %CPU %MEM    TIME+  COMMAND
+ 2201 mysql     20   0 1120m 310m 6408 S 11.9  8.1 120:33.18 mysqld
+`,
+			cpu: 3.2,
+			processes: []TopProcess{
+				{"2201", "mysql", 11.9, 8.1, "mysqld"},
+			},
+		},
+		{name: "busybox", out: "Mem: 1012K used, 2000K free\nCPU:   0% usr   0% sys   0% nic 100% idle\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats, err := ParseTopOutput(tt.out)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTopOutput: err = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if math.Abs(stats.CPUPercent-tt.cpu) > 0.001 {
+				t.Errorf("cpu %.2f%%, want %.2f%%", stats.CPUPercent, tt.cpu)
+			}
+			if !slices.Equal(stats.Processes, tt.processes) {
+				t.Errorf("processes\n%+v\nwant\n%+v", stats.Processes, tt.processes)
+			}
+		})
+	}
+}
+
+func TestParseMemInfo(t *testing.T) {
+	tests := []struct {
+		name    string
+		out     string
+		want    MemStats
+		wantErr bool
+	}{
+		{
+			name: "linux",
+			out: `MemTotal:        8141996 kB
+MemFree:         1232284 kB
+MemAvailable:    5542016 kB
+Buffers:          210448 kB
+Cached:          4278876 kB
+SwapCached:            0 kB
+`,
+			want: MemStats{TotalKB: 8141996, AvailableKB: 5542016},
+		},
+		{
+			// kernels before 3.14 have no MemAvailable
+			name: "old kernel",
+			out: `MemTotal:        3924512 kB
+MemFree:          212512 kB
+Buffers:          190344 kB
+`,
+			want: MemStats{TotalKB: 3924512},
+		},
+		{name: "no meminfo", out: "cat: /proc/meminfo: No such file or directory\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats, err := ParseMemInfo(tt.out)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseMemInfo: err = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && *stats != tt.want {
+				t.Errorf("ParseMemInfo = %+v, want %+v", *stats, tt.want)
+			}
+		})
+	}
+}
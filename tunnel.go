@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os/exec"
+	"slices"
+	"strconv"
+)
+
+// how many ports above the preferred one are tried before giving up
+const tunnelPortAttempts = 20
+
+// addTunnel inserts the -L forward of h into the ssh arguments, picking the
+// local port right before ssh starts so it is still free when ssh binds it
+func addTunnel(h SSHHost, cmd *exec.Cmd) error {
+	if h.TunnelTarget == "" {
+		return nil
+	}
+	port, err := freeLocalPort(h.tunnelPort())
+	if err != nil {
+		return err
+	}
+	cmd.Args = slices.Insert(cmd.Args, sshArgsStart(cmd), "-L", strconv.Itoa(port)+":"+h.TunnelTarget)
+	if cmd.Stderr != nil {
+		fmt.Fprintf(cmd.Stderr, "Forwarding localhost:%d to %s\r\n", port, h.TunnelTarget)
+	}
+	return nil
+}
+
+// tunnelPort is the preferred local port, the target's port if not set
+func (h SSHHost) tunnelPort() int {
+	if h.TunnelPort != 0 {
+		return h.TunnelPort
+	}
+	_, port, _ := net.SplitHostPort(h.TunnelTarget)
+	n, _ := strconv.Atoi(port)
+	return n
+}
+
+// freeLocalPort returns the first port from preferred upwards that nothing
+// listens on yet
+func freeLocalPort(preferred int) (int, error) {
+	if preferred < 1 || preferred > 65535 {
+		return 0, fmt.Errorf("tunnel port %d is outside 1-65535", preferred)
+	}
+	for port := preferred; port < preferred+tunnelPortAttempts && port <= 65535; port++ {
+		l, err := net.Listen("tcp", net.JoinHostPort("localhost", strconv.Itoa(port)))
+		if err == nil {
+			l.Close()
+			return port, nil
+		}
+	}
+	return 0, fmt.Errorf("no free local port in %d-%d", preferred, min(preferred+tunnelPortAttempts-1, 65535))
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maximum number of changes that can be undone
+const maxUndoDepth = 100
+
+// edit records a single change to the host list with enough state to reverse
+// it. before is nil for an added host and after is nil for a deleted one,
+// anything else (edits, renames, tag changes) replaces the host in place.
+type edit struct {
+	index  int
+	before *SSHHost
+	after  *SSHHost
+}
+
+// describe names the change for status messages
+func (e edit) describe() string {
+	switch {
+	case e.before == nil:
+		return "add of " + e.after.Host
+	case e.after == nil:
+		return "delete of " + e.before.Host
+	default:
+		return "edit of " + e.after.Host
+	}
+}
+
+// change is one undo step, the edits of a bulk change in the order they
+// were applied
+type change []edit
+
+func (c change) describe() string {
+	if len(c) == 1 {
+		return c[0].describe()
+	}
+	return fmt.Sprintf("change of %d hosts", len(c))
+}
+
+// session scoped undo and redo stacks, nothing is persisted
+type undoStack struct {
+	undo []change
+	redo []change
+}
+
+func (s *undoStack) push(c change) {
+	s.undo = append(s.undo, c)
+	if len(s.undo) > maxUndoDepth {
+		s.undo = s.undo[1:]
+	}
+	// a new change invalidates everything that was undone before it
+	s.redo = nil
+}
+
+// replace moves the host at index from one state to the other
+func (m *model) replace(index int, from, to *SSHHost) tea.Cmd {
+	var cmd tea.Cmd
+	switch {
+	case from == nil:
+		cmd = m.insertHost(index, *to)
+	case to == nil:
+		m.removeHost(index)
+	default:
+		cmd = m.setHost(index, *to)
+	}
+	// a changed address can start or end a duplicate elsewhere
+	return tea.Batch(cmd, m.refreshDuplicates(), m.scheduleAutosave())
+}
+
+// commit applies edits and records them as one step for undo
+func (m *model) commit(edits ...edit) tea.Cmd {
+	m.edits.push(edits)
+	var cmds []tea.Cmd
+	for _, e := range edits {
+		cmds = append(cmds, m.replace(e.index, e.before, e.after))
+	}
+	return tea.Batch(cmds...)
+}
+
+func (m *model) undo() tea.Cmd {
+	if len(m.edits.undo) == 0 {
+		return m.list.NewStatusMessage("Nothing to undo")
+	}
+	c := m.edits.undo[len(m.edits.undo)-1]
+	m.edits.undo = m.edits.undo[:len(m.edits.undo)-1]
+	m.edits.redo = append(m.edits.redo, c)
+
+	// backwards, so the indexes are those each edit was made at
+	var cmds []tea.Cmd
+	for i := len(c) - 1; i >= 0; i-- {
+		cmds = append(cmds, m.replace(c[i].index, c[i].after, c[i].before))
+	}
+	return tea.Batch(append(cmds, m.list.NewStatusMessage(statusMessageStyle("Undid "+c.describe())))...)
+}
+
+func (m *model) redo() tea.Cmd {
+	if len(m.edits.redo) == 0 {
+		return m.list.NewStatusMessage("Nothing to redo")
+	}
+	c := m.edits.redo[len(m.edits.redo)-1]
+	m.edits.redo = m.edits.redo[:len(m.edits.redo)-1]
+	m.edits.undo = append(m.edits.undo, c)
+
+	var cmds []tea.Cmd
+	for _, e := range c {
+		cmds = append(cmds, m.replace(e.index, e.before, e.after))
+	}
+	return tea.Batch(append(cmds, m.list.NewStatusMessage(statusMessageStyle("Redid "+c.describe())))...)
+}
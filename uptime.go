@@ -0,0 +1,186 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+)
+
+// hosts asked at the same time by uptime
+const uptimeWorkers = 16
+
+// UptimeResult is the parsed uptime output of one host
+type UptimeResult struct {
+	Host   string     `json:"host"`
+	Uptime string     `json:"uptime,omitempty"`
+	Load   [3]float64 `json:"load"`
+	Time   time.Time  `json:"time"`
+	Error  string     `json:"error,omitempty"`
+}
+
+// parseUptime reads output like
+// "10:01:02 up 3 days,  2:04,  1 user,  load average: 0.15, 0.10, 0.05".
+// BSDs and macOS write "load averages:" without commas.
+func parseUptime(out string) (string, [3]float64, error) {
+	var load [3]float64
+	out = strings.TrimSpace(out)
+	_, rest, ok := strings.Cut(out, " up ")
+	if !ok {
+		return "", load, fmt.Errorf("unexpected uptime output %q", out)
+	}
+	i := strings.Index(rest, "load average")
+	if i < 0 {
+		return "", load, fmt.Errorf("no load average in %q", out)
+	}
+
+	// the uptime ends before the user count, if there is one
+	up := strings.TrimRight(strings.TrimSpace(rest[:i]), ",")
+	if j := strings.LastIndex(up, ","); j >= 0 && strings.Contains(up[j:], "user") {
+		up = strings.TrimSpace(up[:j])
+	}
+
+	up = strings.Join(strings.Fields(up), " ")
+
+	_, averages, _ := strings.Cut(rest[i:], ":")
+	fields := strings.FieldsFunc(averages, func(r rune) bool { return r == ',' || r == ' ' })
+	if len(fields) != 3 {
+		return "", load, fmt.Errorf("unexpected load average %q", averages)
+	}
+	for k, f := range fields {
+		v, err := strconv.ParseFloat(f, 64)
+		if err != nil {
+			return "", load, fmt.Errorf("unexpected load average %q", averages)
+		}
+		load[k] = v
+	}
+	return up, load, nil
+}
+
+// FetchUptime runs uptime on h
+func FetchUptime(h SSHHost) (UptimeResult, error) {
+	result := UptimeResult{Host: h.Host, Time: time.Now()}
+	out, err := runRemote(h, "uptime")
+	if err != nil {
+		return result, err
+	}
+	result.Uptime, result.Load, err = parseUptime(out)
+	return result, err
+}
+
+// fetchUptimes asks all hosts with a pool of workers. Failed hosts have Error
+// set.
+func fetchUptimes(hosts []SSHHost) []UptimeResult {
+	results := make([]UptimeResult, len(hosts))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(uptimeWorkers, len(hosts)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				r, err := FetchUptime(hosts[i])
+				if err != nil {
+					r.Error = err.Error()
+				}
+				results[i] = r
+			}
+		}()
+	}
+	for i := range hosts {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+func renderUptimeTable(results []UptimeResult) string {
+	t := table.New().
+		Border(lipgloss.RoundedBorder()).
+		Headers("Host", "Uptime", "Load 1m", "5m", "15m", "Time").
+		StyleFunc(func(row, col int) lipgloss.Style {
+			switch {
+			case row == table.HeaderRow:
+				return dashboardHeaderStyle
+			case results[row].Error != "":
+				return unreachableStyle.Padding(0, 1)
+			}
+			return lipgloss.NewStyle().Padding(0, 1)
+		})
+	for _, r := range results {
+		if r.Error != "" {
+			t.Row(r.Host, r.Error, "", "", "", r.Time.Format(time.TimeOnly))
+			continue
+		}
+		load := func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
+		t.Row(r.Host, r.Uptime, load(r.Load[0]), load(r.Load[1]), load(r.Load[2]), r.Time.Format(time.TimeOnly))
+	}
+	return t.Render()
+}
+
+func runUptime(args []string) int {
+	fs := flag.NewFlagSet("uptime", flag.ExitOnError)
+	tag := fs.String("tag", "", "only hosts with this tag, all hosts if not set")
+	asJSON := fs.Bool("json", false, "print the results as JSON")
+	fs.Parse(args)
+
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load config:", err)
+		return 1
+	}
+	hosts := config.Hosts
+	if *tag != "" {
+		if hosts, err = selectHosts(hosts, "", *tag); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	if len(hosts) == 0 {
+		fmt.Fprintln(os.Stderr, "no hosts configured")
+		return 1
+	}
+
+	results := fetchUptimes(hosts)
+	// busiest first, unreachable hosts at the end
+	slices.SortStableFunc(results, func(a, b UptimeResult) int {
+		if (a.Error == "") != (b.Error == "") {
+			if a.Error == "" {
+				return -1
+			}
+			return 1
+		}
+		if a.Load[0] != b.Load[0] {
+			if a.Load[0] > b.Load[0] {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Host, b.Host)
+	})
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			fmt.Fprintln(os.Stderr, "failed to encode results:", err)
+			return 1
+		}
+	} else {
+		fmt.Println(renderUptimeTable(results))
+	}
+
+	if slices.ContainsFunc(results, func(r UptimeResult) bool { return r.Error != "" }) {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// validateConfig lists the problems of config that parse fine but would
+// break connections or the list, like duplicate aliases or invalid ports
+func validateConfig(config *Config) []error {
+	var problems []error
+	seen := make(map[string]bool)
+	commands := subcommands()
+	for i, h := range config.Hosts {
+		switch {
+		case h.Host == "":
+			problems = append(problems, fmt.Errorf("host %d has no alias", i+1))
+		case strings.ContainsAny(h.Host, " \t"):
+			problems = append(problems, fmt.Errorf("alias %q contains spaces", h.Host))
+		case seen[h.Host]:
+			problems = append(problems, fmt.Errorf("alias %q is used more than once", h.Host))
+		}
+		seen[h.Host] = true
+		if _, ok := commands[h.Host]; ok {
+			problems = append(problems, fmt.Errorf("alias %q is also a subcommand, quickssh %s runs that instead of connecting", h.Host, h.Host))
+		}
+
+		if h.Port < 0 || h.Port > 65535 {
+			problems = append(problems, fmt.Errorf("%s: port %d is out of range", h.Host, h.Port))
+		}
+		if h.TunnelPort < 0 || h.TunnelPort > 65535 {
+			problems = append(problems, fmt.Errorf("%s: tunnel_port %d is out of range", h.Host, h.TunnelPort))
+		}
+		if h.PubkeyOnly && h.PasswordOnly {
+			problems = append(problems, fmt.Errorf("%s: pubkey_only and password_only are both set", h.Host))
+		}
+		if h.WireGuardPublicKey != "" {
+			if err := checkWireGuardKey(h.WireGuardPublicKey); err != nil {
+				problems = append(problems, fmt.Errorf("%s: wg_public_key %w", h.Host, err))
+			}
+		}
+		if h.WireGuardPresharedKey != "" {
+			if h.WireGuardPublicKey == "" {
+				problems = append(problems, fmt.Errorf("%s: wg_preshared_key is set without wg_public_key", h.Host))
+			} else if err := checkWireGuardKey(h.WireGuardPresharedKey); err != nil {
+				problems = append(problems, fmt.Errorf("%s: wg_preshared_key %w", h.Host, err))
+			}
+		}
+		if h.ProxyJump != "" && h.ProxyJump == h.Host {
+			problems = append(problems, fmt.Errorf("%s: proxy_jump points to the host itself", h.Host))
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(config.Templates)) {
+		if port := config.Templates[name].Port; port < 0 || port > 65535 {
+			problems = append(problems, fmt.Errorf("template %s: port %d is out of range", name, port))
+		}
+	}
+	if s := config.Settings.Subtitle; s != "" && !slices.Contains(subtitleModes, s) {
+		problems = append(problems, fmt.Errorf("subtitle %q is not one of %s", s, strings.Join(subtitleModes, ", ")))
+	}
+	if config.Settings.IdleQuitSeconds < 0 {
+		problems = append(problems, fmt.Errorf("idle_quit_seconds %d is negative", config.Settings.IdleQuitSeconds))
+	}
+	return problems
+}
+
+// runSelfcheck loads the config and builds the model the TUI would start
+// with, without a terminal, for CI. The summary goes to stdout, problems to
+// stderr.
+func runSelfcheck(stdout, stderr io.Writer) int {
+	start := time.Now()
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintln(stderr, "selfcheck: failed to load config:", err)
+		return 1
+	}
+	problems := validateConfig(config)
+	for _, p := range problems {
+		fmt.Fprintln(stderr, "selfcheck:", p)
+	}
+
+	listed, err := listedHosts(config)
+	if err != nil {
+		fmt.Fprintln(stderr, "selfcheck: failed to read ~/.ssh/config:", err)
+		return 1
+	}
+	items := toItems(listed)
+
+	var m tea.Model = newModel()
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m.View()
+
+	fmt.Fprintf(stdout, "selfcheck: %d hosts, %d items, %d profiles, %d problems in %s\n",
+		len(config.Hosts), len(items), len(config.Profiles), len(problems), time.Since(start).Round(time.Millisecond))
+	if len(problems) > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRunSelfcheck(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    string
+		sshConfig string
+		code      int
+		stdout    string
+		stderr    []string
+	}{
+		{"empty", "", "", 0, `^selfcheck: 0 hosts, 0 items, 0 profiles, 0 problems in \S+\n$`, nil},
+		{"valid", bulkTestConfig + "\n[profiles.debug]\nverbose = 1\n", "", 0, `^selfcheck: 3 hosts, 3 items, 1 profiles, 0 problems in `, nil},
+		{"with ssh_config hosts", "[settings]\nshow_ssh_config = true\n" + bulkTestConfig, "Host bastion\n  HostName 10.0.9.1\n", 0, `^selfcheck: 3 hosts, 4 items, 0 profiles, 0 problems in `, nil},
+		{
+			"problems",
+			bulkTestConfig + "\n[[hosts]]\nhost = \"web1\"\nport = 70000\n",
+			"",
+			1,
+			`^selfcheck: 4 hosts, 4 items, 0 profiles, 2 problems in `,
+			[]string{`selfcheck: alias "web1" is used more than once`, "selfcheck: web1: port 70000 is out of range"},
+		},
+		{"not TOML", "hosts = [", "", 1, `^$`, []string{"selfcheck: failed to load config:"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			old := configFilePath
+			t.Cleanup(func() { configFilePath = old })
+			configFilePath = filepath.Join(t.TempDir(), ".config")
+			if err := os.WriteFile(configFilePath, []byte(tt.config), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+			t.Setenv("USERPROFILE", home)
+			if tt.sshConfig != "" {
+				if err := os.MkdirAll(filepath.Join(home, ".ssh"), 0o700); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(filepath.Join(home, ".ssh", "config"), []byte(tt.sshConfig), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var stdout, stderr bytes.Buffer
+			if code := runSelfcheck(&stdout, &stderr); code != tt.code {
+				t.Errorf("exit code %d, want %d, stderr: %s", code, tt.code, stderr.String())
+			}
+			if !regexp.MustCompile(tt.stdout).MatchString(stdout.String()) {
+				t.Errorf("stdout %q doesn't match %s", stdout.String(), tt.stdout)
+			}
+			for _, line := range tt.stderr {
+				if !strings.Contains(stderr.String(), line) {
+					t.Errorf("stderr lacks %q: %s", line, stderr.String())
+				}
+			}
+			if tt.stderr == nil && stderr.Len() > 0 {
+				t.Errorf("unexpected stderr: %s", stderr.String())
+			}
+		})
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		want   []string
+	}{
+		{"valid", Config{Hosts: []SSHHost{{Host: "web1", Port: 22}, {Host: "web2", ProxyJump: "web1"}}}, nil},
+		{"no alias", Config{Hosts: []SSHHost{{HostName: "10.0.0.1"}}}, []string{"host 1 has no alias"}},
+		{"spaces", Config{Hosts: []SSHHost{{Host: "web 1"}}}, []string{`alias "web 1" contains spaces`}},
+		{"negative tunnel port", Config{Hosts: []SSHHost{{Host: "db", TunnelPort: -1}}}, []string{"db: tunnel_port -1 is out of range"}},
+		{"both auth limits", Config{Hosts: []SSHHost{{Host: "db", PubkeyOnly: true, PasswordOnly: true}}}, []string{"db: pubkey_only and password_only are both set"}},
+		{"subcommand alias", Config{Hosts: []SSHHost{{Host: "merge"}, {Host: "df"}}}, []string{
+			`alias "merge" is also a subcommand, quickssh merge runs that instead of connecting`,
+			`alias "df" is also a subcommand, quickssh df runs that instead of connecting`,
+		}},
+		{"jump to itself", Config{Hosts: []SSHHost{{Host: "db", ProxyJump: "db"}}}, []string{"db: proxy_jump points to the host itself"}},
+		{"template port", Config{Templates: map[string]HostTemplate{"base": {Port: 99999}}}, []string{"template base: port 99999 is out of range"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, p := range validateConfig(&tt.config) {
+				got = append(got, p.Error())
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("problems %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var vaultClient = &http.Client{Timeout: 15 * time.Second}
+
+// vaultKV addresses a KV secrets engine path, v2 mounts put data/ and
+// metadata/ between the mount and the secret path
+type vaultKV struct {
+	addr    string
+	token   string
+	mount   string
+	version string
+}
+
+// FetchVaultHosts reads every secret below path from a Vault KV engine and
+// decodes it into a host using the SSHHost JSON field names. The KV version
+// is detected from the mount. Secrets without a host field are named after
+// their key.
+func FetchVaultHosts(addr, token, path string) ([]SSHHost, error) {
+	kv, err := detectVaultKV(strings.TrimRight(addr, "/"), token, strings.Trim(path, "/"))
+	if err != nil {
+		return nil, err
+	}
+	return kv.hosts(strings.TrimPrefix(strings.Trim(path, "/")+"/", kv.mount))
+}
+
+// detectVaultKV asks Vault which mount serves path and with which KV version
+func detectVaultKV(addr, token, path string) (*vaultKV, error) {
+	kv := &vaultKV{addr: addr, token: token}
+
+	var resp struct {
+		Data struct {
+			Path    string            `json:"path"`
+			Options map[string]string `json:"options"`
+		} `json:"data"`
+	}
+	if err := kv.get("sys/internal/ui/mounts/"+path, &resp); err != nil {
+		// older Vault versions or restricted tokens can't read mount
+		// info, fall back to KV v1 on the first path segment
+		kv.mount, _, _ = strings.Cut(path, "/")
+		kv.mount += "/"
+		kv.version = "1"
+		return kv, nil
+	}
+
+	kv.mount = resp.Data.Path
+	kv.version = resp.Data.Options["version"]
+	if kv.version == "" {
+		kv.version = "1"
+	}
+	return kv, nil
+}
+
+// hosts recursively reads all secrets in dir, which is relative to the mount
+// and ends in a slash
+func (kv *vaultKV) hosts(dir string) ([]SSHHost, error) {
+	var list struct {
+		Data struct {
+			Keys []string `json:"keys"`
+		} `json:"data"`
+	}
+	listPath := kv.mount + dir
+	if kv.version == "2" {
+		listPath = kv.mount + "metadata/" + dir
+	}
+	if err := kv.get(listPath+"?list=true", &list); err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", kv.mount+dir, err)
+	}
+
+	var hosts []SSHHost
+	for _, key := range list.Data.Keys {
+		if strings.HasSuffix(key, "/") {
+			sub, err := kv.hosts(dir + key)
+			if err != nil {
+				return nil, err
+			}
+			hosts = append(hosts, sub...)
+			continue
+		}
+
+		h, err := kv.host(dir + key)
+		if err != nil {
+			return nil, err
+		}
+		if h.Host == "" {
+			h.Host = key
+		}
+		hosts = append(hosts, h)
+	}
+	return hosts, nil
+}
+
+func (kv *vaultKV) host(path string) (SSHHost, error) {
+	var secret struct {
+		Data json.RawMessage `json:"data"`
+	}
+	secretPath := kv.mount + path
+	if kv.version == "2" {
+		secretPath = kv.mount + "data/" + path
+	}
+	if err := kv.get(secretPath, &secret); err != nil {
+		return SSHHost{}, fmt.Errorf("failed to read %s: %w", kv.mount+path, err)
+	}
+
+	data := secret.Data
+	if kv.version == "2" {
+		// v2 wraps the secret in data.data next to its metadata
+		var versioned struct {
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(data, &versioned); err != nil {
+			return SSHHost{}, err
+		}
+		data = versioned.Data
+	}
+
+	var h SSHHost
+	if err := json.Unmarshal(data, &h); err != nil {
+		return SSHHost{}, fmt.Errorf("secret %s doesn't match the host fields: %w", kv.mount+path, err)
+	}
+	return h, nil
+}
+
+func (kv *vaultKV) get(path string, v any) error {
+	req, err := http.NewRequest(http.MethodGet, kv.addr+"/v1/"+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", kv.token)
+
+	resp, err := vaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("vault returned %s", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// how long certificates signed for a connection are valid
+const vaultCertTTL = "30m"
+
+// SignKeyWithVault has the SSH secrets engine of Vault sign pubKey for role
+// and returns the certificate. role is "<mount>/<role>", or just the role for
+// the default "ssh" mount.
+func SignKeyWithVault(pubKey, role, addr, token string) ([]byte, error) {
+	mount, name, ok := strings.Cut(strings.Trim(role, "/"), "/")
+	if !ok {
+		mount, name = "ssh", mount
+	}
+	body, err := json.Marshal(map[string]string{"public_key": pubKey, "ttl": vaultCertTTL})
+	if err != nil {
+		return nil, err
+	}
+	url := strings.TrimRight(addr, "/") + "/v1/" + mount + "/sign/" + name
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := vaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var signed struct {
+		Data struct {
+			SignedKey string `json:"signed_key"`
+		} `json:"data"`
+		Errors []string `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&signed); err != nil && resp.StatusCode == http.StatusOK {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		if len(signed.Errors) > 0 {
+			return nil, fmt.Errorf("vault returned %s: %s", resp.Status, strings.Join(signed.Errors, ", "))
+		}
+		return nil, fmt.Errorf("vault returned %s", resp.Status)
+	}
+	if signed.Data.SignedKey == "" {
+		return nil, errors.New("vault returned no signed key")
+	}
+	return []byte(signed.Data.SignedKey), nil
+}
+
+// vaultToken is $VAULT_TOKEN or the token the vault cli stored on login
+func vaultToken() string {
+	if token := os.Getenv("VAULT_TOKEN"); token != "" {
+		return token
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	token, _ := os.ReadFile(filepath.Join(home, ".vault-token"))
+	return strings.TrimSpace(string(token))
+}
+
+// publicKeyFile is the public half of the host's identity, or of the first
+// default key ssh would try
+func (h SSHHost) publicKeyFile() (string, error) {
+	if h.IdentityFile != "" {
+		return expandPath(h.IdentityFile) + ".pub", nil
+	}
+	for _, name := range []string{"id_ed25519", "id_ecdsa", "id_rsa"} {
+		path := expandPath("~/.ssh/" + name + ".pub")
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", errors.New("no identity_file set and no default key in ~/.ssh")
+}
+
+// addVaultCertificate signs the host's key with Vault, writes the
+// certificate to a temp file and passes it to ssh. The returned function
+// removes the file.
+func (c *connectExec) addVaultCertificate() (cleanup func(), err error) {
+	addr := c.settings.vaultAddr()
+	if addr == "" {
+		return nil, errors.New("vault_ssh_role is set but neither vault_addr nor $VAULT_ADDR")
+	}
+	keyFile, err := c.host.publicKeyFile()
+	if err != nil {
+		return nil, err
+	}
+	pubKey, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := SignKeyWithVault(string(pubKey), c.host.VaultSSHRole, addr, vaultToken())
+	if err != nil {
+		return nil, fmt.Errorf("signing %s with vault failed: %w", keyFile, err)
+	}
+
+	f, err := os.CreateTemp("", "quickssh-cert-*.pub")
+	if err != nil {
+		return nil, err
+	}
+	cleanup = func() { os.Remove(f.Name()) }
+	_, err = f.Write(cert)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		cleanup()
+		return nil, err
+	}
+
+	path := f.Name()
+	if c.settings.usesWSL() {
+		path = wslPath(path)
+	}
+	c.Cmd.Args = slices.Insert(c.Cmd.Args, sshArgsStart(c.Cmd), "-o", "CertificateFile="+path)
+	return cleanup, nil
+}
+
+// vaultAddr is vault_addr, $VAULT_ADDR if not set
+func (s Settings) vaultAddr() string {
+	if s.VaultAddr != "" {
+		return s.VaultAddr
+	}
+	return os.Getenv("VAULT_ADDR")
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	normalModeStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFDF5")).Background(lipgloss.Color("#25A065")).Padding(0, 1)
+	insertModeStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#1A1A1A")).Background(lipgloss.Color("#E8C547")).Padding(0, 1)
+)
+
+// insertMode reports whether keys are going into a text input, the search
+// or a form, rather than triggering actions
+func (m model) insertMode() bool {
+	switch m.view {
+	case formView, tagFilterView:
+		return true
+	case moveGroupView:
+		return m.group.creating
+	case execView:
+		return m.exec.report == nil && !m.exec.running
+	}
+	return m.list.FilterState() == list.Filtering
+}
+
+// footerHeight is the room below the list taken by the mode indicator
+func (m model) footerHeight() int {
+	if m.settings.VimMode {
+		return 1
+	}
+	return 0
+}
+
+// modeIndicator is the footer shown with vim_mode
+func (m model) modeIndicator() string {
+	indicator := normalModeStyle.Render("NORMAL")
+	if m.insertMode() {
+		indicator = insertModeStyle.Render("INSERT")
+	}
+	return lipgloss.NewStyle().PaddingLeft(appStyle.GetPaddingLeft()).Render(indicator)
+}
+
+// startInsert opens the search like / does, for i in normal mode
+func (m *model) startInsert() tea.Cmd {
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}})
+	return cmd
+}
+
+// sizeList fits the list to the window, leaving room for the footer. The
+// list works out its page size from its height whenever its items or filter
+// change, so page_size is kept by making it no taller than that many hosts.
+func (m *model) sizeList() {
+	_, v := appStyle.GetFrameSize()
+	height := max(m.height-v-m.footerHeight(), 1)
+	m.list.SetSize(listWidth, height)
+
+	size := m.settings.pageSize()
+	extra := m.list.Paginator.PerPage - size
+	if extra <= 0 {
+		return
+	}
+	d := newHostDelegate(m.settings, m.lastSeen)
+	perHost := d.Height() + d.Spacing()
+	height -= extra * perHost
+	// go down to the smallest height that fits size hosts and add a line,
+	// so the page line below the list coming or going doesn't change it
+	for height > 1 {
+		m.list.SetSize(listWidth, height-1)
+		if m.list.Paginator.PerPage < size {
+			break
+		}
+		height--
+	}
+	if perHost > 1 {
+		height++
+	}
+	m.list.SetSize(listWidth, height)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// how long opening a master connection may take
+const warmupTimeout = 30 * time.Second
+
+// sent once the master connection of a pinned host is up or failed
+type warmupCompleteMsg struct {
+	host string
+	err  error
+}
+
+// multiplexOptions share one connection between all sessions to a host. Explicit
+// Control* settings of the host come first in sshOptions and win.
+func multiplexOptions() []string {
+	return []string{
+		"-o", "ControlMaster=auto",
+		"-o", "ControlPath=~/.ssh/quickssh-%C",
+		"-o", "ControlPersist=10m",
+	}
+}
+
+// multiplexConfig is multiplexOptions as ssh_config keywords and values
+func multiplexConfig() [][2]string {
+	var config [][2]string
+	options := multiplexOptions()
+	for i := 1; i < len(options); i += 2 {
+		keyword, value, _ := strings.Cut(options[i], "=")
+		config = append(config, [2]string{keyword, value})
+	}
+	return config
+}
+
+// warmupHosts opens the master connections of the pinned, multiplexed hosts,
+// each in its own command so they run concurrently
+func warmupHosts(hosts []SSHHost) tea.Cmd {
+	var cmds []tea.Cmd
+	for _, h := range hosts {
+		if h.Pinned && h.Multiplexing && !h.fromSSHConfig {
+			cmds = append(cmds, func() tea.Msg {
+				return warmupCompleteMsg{host: h.Host, err: warmup(h)}
+			})
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// warmup starts a master connection for h in the background unless one is
+// already running
+func warmup(h SSHHost) error {
+	check := exec.Command(sshBinary, append(sshOptions(h), "-O", "check", h.destination())...)
+	if check.Run() == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), warmupTimeout)
+	defer cancel()
+	args := append([]string{"-f", "-N", "-o", "ControlMaster=yes", "-o", "BatchMode=yes"}, sshOptions(h)...)
+	cmd := exec.CommandContext(ctx, sshBinary, append(args, h.destination())...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	// the backgrounded ssh keeps stderr open, don't wait for it to close
+	cmd.WaitDelay = time.Second
+
+	err := cmd.Run()
+	if err == nil || errors.Is(err, exec.ErrWaitDelay) {
+		return nil
+	}
+	if msg := strings.TrimSpace(stderr.String()); msg != "" {
+		return fmt.Errorf("%w: %s", err, lastLine(msg))
+	}
+	return err
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"golang.org/x/crypto/ssh"
+)
+
+var watchChangedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#1A1A1A")).Background(lipgloss.Color("#E8C547"))
+
+// WatchRemoteCommand runs cmd on h every interval over a single connection
+// and writes the output of each run to out in one Write. A non-zero exit
+// status is noted below the output and the watch goes on, it only stops
+// when the connection fails or out returns an error.
+func WatchRemoteCommand(h SSHHost, cmd string, interval time.Duration, out io.Writer) error {
+	client, err := dialNative(h)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		session, err := client.NewSession()
+		if err != nil {
+			return err
+		}
+		output, err := session.CombinedOutput(cmd)
+		session.Close()
+		var exitErr *ssh.ExitError
+		if errors.As(err, &exitErr) {
+			output = fmt.Appendf(output, "\n[exit status %d]\n", exitErr.ExitStatus())
+		} else if err != nil {
+			return err
+		}
+		if _, err := out.Write(output); err != nil {
+			return err
+		}
+		<-ticker.C
+	}
+}
+
+// changedLines marks the lines of output that weren't in previous. A line
+// that shows up more often than before counts as changed too.
+func changedLines(previous, output []string) []bool {
+	seen := make(map[string]int)
+	for _, line := range previous {
+		seen[line]++
+	}
+	changed := make([]bool, len(output))
+	for i, line := range output {
+		if seen[line] > 0 {
+			seen[line]--
+		} else {
+			changed[i] = previous != nil
+		}
+	}
+	return changed
+}
+
+// sent with the output of each run of the watched command
+type watchOutputMsg string
+
+// sent when the watch stopped
+type watchStoppedMsg struct{ err error }
+
+// watchWriter passes each run's output to the program
+type watchWriter struct{ p *tea.Program }
+
+func (w watchWriter) Write(b []byte) (int, error) {
+	w.p.Send(watchOutputMsg(b))
+	return len(b), nil
+}
+
+type watchModel struct {
+	host     string
+	command  string
+	interval time.Duration
+	output   viewport.Model
+	previous []string
+	updated  time.Time
+	err      error
+	ready    bool
+}
+
+func (m watchModel) Init() tea.Cmd { return nil }
+
+func (m watchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "q", "esc":
+			return m, tea.Quit
+		}
+	case tea.WindowSizeMsg:
+		// header and footer line
+		m.output.Width, m.output.Height = msg.Width, max(msg.Height-2, 1)
+	case watchOutputMsg:
+		lines := strings.Split(strings.TrimRight(string(msg), "\n"), "\n")
+		changed := changedLines(m.previous, lines)
+		rendered := make([]string, len(lines))
+		for i, line := range lines {
+			if changed[i] {
+				line = watchChangedStyle.Render(line)
+			}
+			rendered[i] = line
+		}
+		m.output.SetContent(strings.Join(rendered, "\n"))
+		m.previous, m.updated, m.ready = lines, time.Now(), true
+		return m, nil
+	case watchStoppedMsg:
+		m.err = msg.err
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.output, cmd = m.output.Update(msg)
+	return m, cmd
+}
+
+func (m watchModel) View() string {
+	header := titleStyle.Render(fmt.Sprintf("Every %s: %s on %s", m.interval, m.command, m.host))
+	if m.ready {
+		header += " " + checkFixStyle.Render(m.updated.Format(time.TimeOnly))
+	}
+	footer := checkFixStyle.Render("j/k: scroll • q: quit")
+	if m.err != nil {
+		footer = errorMessageStyle("Stopped: "+m.err.Error()) + " " + footer
+	}
+	if !m.ready && m.err == nil {
+		return header + "\n\nConnecting...\n" + footer
+	}
+	return header + "\n" + m.output.View() + "\n" + footer
+}
+
+func runWatch(args []string) int {
+	fs := flag.NewFlagSet("watch", flag.ExitOnError)
+	alias := fs.String("host", "", "alias of the host to run the command on")
+	command := fs.String("cmd", "", "command to run")
+	interval := fs.Int("interval", 2, "seconds between runs")
+	fs.Parse(args)
+
+	if *command == "" || *interval < 1 {
+		fmt.Fprintln(os.Stderr, "usage: quickssh watch --host <alias> --cmd <command> [--interval seconds]")
+		return 2
+	}
+	h, err := loadHost(*alias)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if h.ProxyJump != "" {
+		// the in-process client connects directly
+		fmt.Fprintln(os.Stderr, "watch doesn't support hosts with proxy_jump")
+		return 1
+	}
+
+	every := time.Duration(*interval) * time.Second
+	p := tea.NewProgram(watchModel{host: h.Host, command: *command, interval: every, output: viewport.New(0, 0)}, tea.WithAltScreen())
+	go func() {
+		err := WatchRemoteCommand(h, *command, every, watchWriter{p})
+		p.Send(watchStoppedMsg{err: err})
+	}()
+	if _, err := p.Run(); err != nil {
+		fmt.Fprintln(os.Stderr, "Error running program:", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/user"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+)
+
+var (
+	mismatchStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#F2C94C")).Padding(0, 1)
+
+	// one uid=, gid= or groups= entry of id's output
+	idFieldRegexp = regexp.MustCompile(`(\w+)=(\S+)`)
+	idNameRegexp  = regexp.MustCompile(`\(([^)]*)\)`)
+)
+
+// RemoteIdentity is who a connection to a host ends up logged in as
+type RemoteIdentity struct {
+	User     string
+	UID      string
+	GID      string
+	Groups   []string
+	Hostname string
+}
+
+// FetchRemoteIdentity runs id and hostname on h
+func FetchRemoteIdentity(h SSHHost) (RemoteIdentity, error) {
+	out, err := runRemote(h, "id; hostname")
+	if err != nil {
+		return RemoteIdentity{}, err
+	}
+	return parseIdentity(out)
+}
+
+// parseIdentity reads the output of id followed by hostname
+func parseIdentity(out string) (RemoteIdentity, error) {
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) < 2 {
+		return RemoteIdentity{}, errors.New("unexpected output of id and hostname: " + out)
+	}
+
+	var id RemoteIdentity
+	id.Hostname = strings.TrimSpace(lines[len(lines)-1])
+	for _, match := range idFieldRegexp.FindAllStringSubmatch(lines[0], -1) {
+		switch match[1] {
+		case "uid":
+			id.UID = match[2]
+			if name := idNameRegexp.FindStringSubmatch(match[2]); name != nil {
+				id.User = name[1]
+			}
+		case "gid":
+			id.GID = match[2]
+		case "groups":
+			id.Groups = strings.Split(match[2], ",")
+		}
+	}
+	if id.UID == "" {
+		return RemoteIdentity{}, errors.New("unexpected output of id: " + lines[0])
+	}
+	return id, nil
+}
+
+// expectedUser is the user ssh logs in as unless its config says otherwise
+func expectedUser(h SSHHost) string {
+	if h.User != "" {
+		return h.User
+	}
+	if u, err := user.Current(); err == nil {
+		// on Windows the name includes the domain
+		_, name, _ := strings.Cut(u.Username, `\`)
+		if name != "" {
+			return name
+		}
+		return u.Username
+	}
+	return ""
+}
+
+func runWhoami(args []string) int {
+	fs := flag.NewFlagSet("whoami", flag.ExitOnError)
+	alias := fs.String("host", "", "alias of the host to check")
+	fs.Parse(args)
+
+	h, err := loadHost(*alias)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	id, err := FetchRemoteIdentity(h)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to fetch identity:", err)
+		return 1
+	}
+
+	configured := h.User
+	if configured == "" {
+		configured = "(not set, " + expectedUser(h) + ")"
+	}
+	mismatch := id.User != expectedUser(h)
+
+	rows := [][]string{
+		{"Host", h.Host},
+		{"Configured user", configured},
+		{"Remote user", id.User},
+		{"uid", id.UID},
+		{"gid", id.GID},
+		{"groups", strings.Join(id.Groups, ", ")},
+		{"hostname", id.Hostname},
+	}
+	t := table.New().
+		Border(lipgloss.RoundedBorder()).
+		Rows(rows...).
+		StyleFunc(func(row, col int) lipgloss.Style {
+			if mismatch && (row == 1 || row == 2) {
+				return mismatchStyle
+			}
+			if col == 0 {
+				return dashboardHeaderStyle
+			}
+			return lipgloss.NewStyle().Padding(0, 1)
+		})
+	fmt.Println(t)
+
+	if mismatch {
+		fmt.Println(mismatchStyle.UnsetPadding().Render("The remote user differs from the configured one, check ~/.ssh/config for overrides"))
+	}
+	return 0
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+)
+
+// checkWireGuardKey tells whether key looks like a WireGuard key, 32 bytes
+// in base64
+func checkWireGuardKey(key string) error {
+	data, err := base64.StdEncoding.DecodeString(key)
+	if err != nil || len(data) != 32 {
+		return fmt.Errorf("%q is not a base64 encoded 32 byte key", key)
+	}
+	return nil
+}
+
+// wireGuardAllowedIPs returns the addresses of h as single-address networks,
+// looking the hostname up if it isn't an IP
+func wireGuardAllowedIPs(h SSHHost) (string, error) {
+	var addrs []netip.Addr
+	if addr, err := netip.ParseAddr(h.address()); err == nil {
+		addrs = append(addrs, addr)
+	} else {
+		ips, err := net.LookupIP(h.address())
+		if err != nil {
+			return "", err
+		}
+		for _, ip := range ips {
+			if addr, ok := netip.AddrFromSlice(ip); ok {
+				addrs = append(addrs, addr.Unmap())
+			}
+		}
+	}
+	var prefixes []string
+	for _, addr := range addrs {
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()).String())
+	}
+	return strings.Join(prefixes, ", "), nil
+}
+
+// FormatWireGuardPeers renders a [Peer] section for every host with
+// wg_public_key, to append to a wg0.conf. AllowedIPs are the addresses of
+// the hostname.
+func FormatWireGuardPeers(hosts []SSHHost) (string, error) {
+	var b strings.Builder
+	for _, h := range hosts {
+		if h.WireGuardPublicKey == "" {
+			continue
+		}
+		allowed, err := wireGuardAllowedIPs(h)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", h.Host, err)
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "# %s\n[Peer]\nPublicKey = %s\n", h.Host, h.WireGuardPublicKey)
+		if h.WireGuardPresharedKey != "" {
+			fmt.Fprintf(&b, "PresharedKey = %s\n", h.WireGuardPresharedKey)
+		}
+		fmt.Fprintf(&b, "AllowedIPs = %s\n", allowed)
+	}
+	return b.String(), nil
+}
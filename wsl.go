@@ -0,0 +1,65 @@
+package main
+
+import (
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// wslAvailable reports whether wsl.exe is on the PATH, looked up once
+var wslAvailable = sync.OnceValue(func() bool {
+	_, err := exec.LookPath("wsl")
+	return err == nil
+})
+
+// usesWSL reports whether connections go through the ssh of WSL
+func (s Settings) usesWSL() bool {
+	return s.UseWSL && runtime.GOOS == "windows" && wslAvailable()
+}
+
+// wslWarning explains why use_wsl has no effect, if it is set and WSL is
+// missing
+func (s Settings) wslWarning() string {
+	if s.UseWSL && runtime.GOOS == "windows" && !wslAvailable() {
+		return "use_wsl is set but wsl.exe wasn't found, using the ssh of Windows"
+	}
+	return ""
+}
+
+// sshCommand runs ssh with args, through WSL if use_wsl is set. Identity
+// paths are translated since WSL's ssh can't read C:\ paths.
+func sshCommand(settings Settings, args ...string) *exec.Cmd {
+	if !settings.usesWSL() {
+		return exec.Command(sshBinary, args...)
+	}
+	wslArgs := append([]string{"ssh"}, args...)
+	for i := 2; i < len(wslArgs); i++ {
+		if wslArgs[i-1] == "-i" {
+			wslArgs[i] = wslPath(wslArgs[i])
+		} else if cert, ok := strings.CutPrefix(wslArgs[i], "CertificateFile="); ok {
+			wslArgs[i] = "CertificateFile=" + wslPath(cert)
+		}
+	}
+	return exec.Command("wsl", wslArgs...)
+}
+
+// wslPath turns a Windows path like C:\Users\me\.ssh\id_ed25519 into
+// /mnt/c/Users/me/.ssh/id_ed25519, other paths are returned as is
+func wslPath(path string) string {
+	if len(path) < 3 || path[1] != ':' || (path[2] != '\\' && path[2] != '/') {
+		return path
+	}
+	drive := strings.ToLower(path[:1])
+	return "/mnt/" + drive + strings.ReplaceAll(path[2:], `\`, "/")
+}
+
+// sshArgsStart is the index of ssh's first argument in cmd.Args, after the
+// wsl prefix if there is one
+func sshArgsStart(cmd *exec.Cmd) int {
+	if strings.TrimSuffix(filepath.Base(cmd.Args[0]), ".exe") == "wsl" {
+		return 2
+	}
+	return 1
+}